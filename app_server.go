@@ -69,5 +69,9 @@ func (s *AppServer) Start(port string) error {
 		logrus.Infof("服务器已优雅关闭")
 	}
 
+	if s.runtime != nil {
+		s.runtime.Close()
+	}
+
 	return nil
 }
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// BlockProfile 资源拦截策略。
+type BlockProfile string
+
+const (
+	// BlockNone 不拦截，完整加载页面（发布、登录等需要真实页面的操作）
+	BlockNone BlockProfile = "none"
+	// BlockMedia 拦截图片、音视频与字体
+	BlockMedia BlockProfile = "media"
+	// BlockStrict 在 BlockMedia 基础上拦截第三方统计与广告请求
+	BlockStrict BlockProfile = "strict"
+)
+
+// ParseBlockProfile 解析策略名称。
+func ParseBlockProfile(s string) (BlockProfile, error) {
+	switch p := BlockProfile(strings.ToLower(strings.TrimSpace(s))); p {
+	case BlockNone, BlockMedia, BlockStrict:
+		return p, nil
+	case "":
+		return BlockNone, nil
+	default:
+		return "", fmt.Errorf("unknown block profile: %s", s)
+	}
+}
+
+// 第三方统计/广告域名，只在 strict 策略下拦截
+var trackerHosts = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"doubleclick.net",
+	"hm.baidu.com",
+	"cnzz.com",
+	"umeng.com",
+	"growingio.com",
+	"sensorsdata.cn",
+}
+
+// 小红书自身的域名，即使是统计请求也不拦截，避免触发风控
+var firstPartyHosts = []string{
+	"xiaohongshu.com",
+	"xhscdn.com",
+	"xhslink.com",
+}
+
+// shouldBlock 判断请求是否应被拦截。
+func shouldBlock(profile BlockProfile, resourceType proto.NetworkResourceType, rawURL string) bool {
+	if profile == BlockNone || profile == "" {
+		return false
+	}
+	switch resourceType {
+	case proto.NetworkResourceTypeImage, proto.NetworkResourceTypeMedia, proto.NetworkResourceTypeFont:
+		return true
+	}
+	if profile != BlockStrict {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if hostMatches(host, firstPartyHosts) {
+		return false
+	}
+	return hostMatches(host, trackerHosts)
+}
+
+func hostMatches(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockResources 按策略拦截页面请求，返回停止拦截的函数。BlockNone 时不做任何处理。
+func BlockResources(page *rod.Page, profile BlockProfile) (stop func(), err error) {
+	if profile == BlockNone || profile == "" {
+		return func() {}, nil
+	}
+
+	router := page.HijackRequests()
+	err = router.Add("*", "", func(h *rod.Hijack) {
+		if shouldBlock(profile, h.Request.Type(), h.Request.URL().String()) {
+			h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	go router.Run()
+
+	return func() { _ = router.Stop() }, nil
+}
@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldBlock(t *testing.T) {
+	img := "https://sns-webpic-qc.xhscdn.com/abc.jpg"
+	tracker := "https://www.google-analytics.com/collect"
+	api := "https://edith.xiaohongshu.com/api/sns/web/v1/homefeed"
+
+	require.False(t, shouldBlock(BlockNone, proto.NetworkResourceTypeImage, img))
+
+	require.True(t, shouldBlock(BlockMedia, proto.NetworkResourceTypeImage, img))
+	require.True(t, shouldBlock(BlockMedia, proto.NetworkResourceTypeFont, img))
+	require.False(t, shouldBlock(BlockMedia, proto.NetworkResourceTypeScript, tracker))
+	require.False(t, shouldBlock(BlockMedia, proto.NetworkResourceTypeXHR, api))
+
+	require.True(t, shouldBlock(BlockStrict, proto.NetworkResourceTypeScript, tracker))
+	require.True(t, shouldBlock(BlockStrict, proto.NetworkResourceTypeMedia, img))
+	require.False(t, shouldBlock(BlockStrict, proto.NetworkResourceTypeXHR, api))
+}
+
+func TestParseBlockProfile(t *testing.T) {
+	p, err := ParseBlockProfile(" Strict ")
+	require.NoError(t, err)
+	require.Equal(t, BlockStrict, p)
+
+	p, err = ParseBlockProfile("")
+	require.NoError(t, err)
+	require.Equal(t, BlockNone, p)
+
+	_, err = ParseBlockProfile("all")
+	require.Error(t, err)
+}
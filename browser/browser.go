@@ -1,25 +1,35 @@
 package browser
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/go-rod/stealth"
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
 )
 
 type browserConfig struct {
 	binPath     string
 	cookiesPath string
+	cookier     cookies.Cookier
 	proxyURL    string
+	userDataDir string
+	fingerprint *fingerprint.Profile
+	remoteURL   string
+	// remoteIsolated 在远程浏览器中为本次连接创建独立的浏览器上下文
+	remoteIsolated bool
 }
 
 type Option func(*browserConfig)
@@ -36,6 +46,20 @@ func WithCookiesPath(path string) Option {
 	}
 }
 
+// WithCookier 指定 cookies 来源，优先于 WithCookiesPath。
+func WithCookier(c cookies.Cookier) Option {
+	return func(cfg *browserConfig) {
+		cfg.cookier = c
+	}
+}
+
+// WithUserDataDir 使用持久化的 user-data-dir，关闭浏览器时保留该目录。
+func WithUserDataDir(dir string) Option {
+	return func(c *browserConfig) {
+		c.userDataDir = dir
+	}
+}
+
 func WithProxyURL(proxyURL string) Option {
 	return func(c *browserConfig) {
 		c.proxyURL = proxyURL
@@ -43,8 +67,14 @@ func WithProxyURL(proxyURL string) Option {
 }
 
 type Browser struct {
-	browser  *rod.Browser
-	launcher *launcher.Launcher
+	browser     *rod.Browser
+	launcher    *launcher.Launcher
+	persistent  bool
+	fingerprint *fingerprint.Profile
+
+	// remote 连接的外部浏览器，cancel 用于断开连接
+	remote bool
+	cancel context.CancelFunc
 }
 
 var launchMu sync.Mutex
@@ -99,6 +129,20 @@ func NewBrowser(headless bool, options ...Option) (*Browser, error) {
 	if ua := strings.TrimSpace(os.Getenv("XHS_MCP_USER_AGENT")); ua != "" {
 		userAgent = ua
 	}
+	fp := cfg.fingerprint
+	if fp != nil && fp.IsZero() {
+		fp = nil
+	}
+	if cfg.remoteURL != "" {
+		cfg.fingerprint = fp
+		return connectRemote(cfg)
+	}
+	if fp != nil {
+		// 启动前还不知道 Chrome 版本，先按本机系统调整；连接后再按实际版本调整
+		launchFP := fp.ForBrowser("", fingerprint.HostOS())
+		fp = &launchFP
+		userAgent = fp.UserAgent
+	}
 
 	l := launcher.New().
 		Headless(headless).
@@ -108,6 +152,17 @@ func NewBrowser(headless bool, options ...Option) (*Browser, error) {
 	if cfg.proxyURL != "" {
 		l = l.Set("proxy-server", cfg.proxyURL)
 	}
+	if cfg.userDataDir != "" {
+		l = l.UserDataDir(cfg.userDataDir)
+	}
+	if fp != nil {
+		if locale := fp.Locale(); locale != "" {
+			l = l.Set("lang", locale)
+		}
+		if fp.ViewportWidth > 0 && fp.ViewportHeight > 0 {
+			l = l.Set("window-size", fmt.Sprintf("%d,%d", fp.ViewportWidth, fp.ViewportHeight))
+		}
+	}
 	binPath := strings.TrimSpace(cfg.binPath)
 	if binPath == "" {
 		binPath = detectChromeBinPath()
@@ -135,19 +190,29 @@ func NewBrowser(headless bool, options ...Option) (*Browser, error) {
 
 	b := rod.New().ControlURL(url)
 	if err := b.Connect(); err != nil {
+		if cfg.userDataDir != "" {
+			l.Delete(flags.UserDataDir)
+		}
+		l.Kill()
 		l.Cleanup()
 		return nil, fmt.Errorf("connect browser failed: %w", err)
 	}
+	fp = matchBrowser(b, fp)
 
-	cookiesPath := cfg.cookiesPath
-	if cookiesPath == "" {
-		cookiesPath = cookies.GetCookiesFilePath()
+	cookieLoader := cfg.cookier
+	if cookieLoader == nil {
+		cookiesPath := cfg.cookiesPath
+		if cookiesPath == "" {
+			cookiesPath = cookies.GetCookiesFilePath()
+		}
+		if cookiesPath != "" {
+			cookieLoader = cookies.NewLoadCookie(cookiesPath)
+		}
 	}
-	if cookiesPath != "" {
-		cookieLoader := cookies.NewLoadCookie(cookiesPath)
+	if cookieLoader != nil {
 		if data, err := cookieLoader.LoadCookies(); err == nil {
 			if len(data) == 0 {
-				return &Browser{browser: b, launcher: l}, nil
+				return &Browser{browser: b, launcher: l, persistent: cfg.userDataDir != "", fingerprint: fp}, nil
 			}
 			var cks []*proto.NetworkCookie
 			if err := json.Unmarshal(data, &cks); err == nil {
@@ -163,21 +228,61 @@ func NewBrowser(headless bool, options ...Option) (*Browser, error) {
 		}
 	}
 
-	return &Browser{browser: b, launcher: l}, nil
+	return &Browser{browser: b, launcher: l, persistent: cfg.userDataDir != "", fingerprint: fp}, nil
 }
 
 func (b *Browser) Close() {
 	if b == nil {
 		return
 	}
+	if b.remote {
+		// 远程浏览器由外部管理，只销毁本次创建的独立上下文并断开连接
+		if b.browser != nil && b.browser.BrowserContextID != "" {
+			_ = b.browser.Close()
+		}
+		if b.cancel != nil {
+			b.cancel()
+		}
+		return
+	}
 	if b.browser != nil {
 		_ = b.browser.Close()
 	}
 	if b.launcher != nil {
+		if b.persistent {
+			// Cleanup 会删除 user-data-dir，持久化 profile 需先解除关联，只等待进程退出
+			b.launcher.Delete(flags.UserDataDir)
+		}
 		b.launcher.Cleanup()
 	}
 }
 
 func (b *Browser) NewPage() *rod.Page {
-	return stealth.MustPage(b.browser)
+	page := stealth.MustPage(b.browser)
+	if err := applyFingerprint(page, b.fingerprint); err != nil {
+		logrus.Warnf("failed to apply fingerprint: %v", err)
+	}
+	return page
+}
+
+// Page 创建新页面并应用指纹，浏览器已断开时返回错误而不是 panic。
+func (b *Browser) Page() (*rod.Page, error) {
+	page, err := stealth.Page(b.browser)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFingerprint(page, b.fingerprint); err != nil {
+		_ = page.Close()
+		return nil, err
+	}
+	return page, nil
+}
+
+// pingTimeout 健康检查的超时时间，Chrome 或远程 CDP 无响应时按检查失败处理
+const pingTimeout = 5 * time.Second
+
+// Ping 检查浏览器连接是否仍然可用，超时同样返回错误。
+func (b *Browser) Ping() error {
+	_, err := proto.BrowserGetVersion{}.Call(b.browser.Timeout(pingTimeout))
+	return err
 }
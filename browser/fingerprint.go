@@ -0,0 +1,131 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
+)
+
+// WithFingerprint 使用账号固定的浏览器指纹（UA、平台、视口、语言、时区、WebGL）。
+func WithFingerprint(fp *fingerprint.Profile) Option {
+	return func(c *browserConfig) {
+		c.fingerprint = fp
+	}
+}
+
+// matchBrowser 按实际运行的 Chrome 版本与系统调整指纹，避免 UA 声明的版本、系统与真实浏览器不一致
+func matchBrowser(b *rod.Browser, fp *fingerprint.Profile) *fingerprint.Profile {
+	if fp == nil {
+		return nil
+	}
+	v, err := proto.BrowserGetVersion{}.Call(b.Timeout(pingTimeout))
+	if err != nil {
+		logrus.Warnf("failed to get browser version, fingerprint kept as is: %v", err)
+		return fp
+	}
+	matched := fp.ForBrowser(v.Product, fingerprint.OSFromUserAgent(v.UserAgent))
+	return &matched
+}
+
+// fingerprintScript 在 stealth 脚本之后注入，覆盖 navigator 与 WebGL 相关字段。
+const fingerprintScript = `(() => {
+  const fp = %s;
+  const define = (obj, key, value) => {
+    try { Object.defineProperty(obj, key, { get: () => value, configurable: true }); } catch (e) {}
+  };
+  define(Navigator.prototype, 'platform', fp.platform);
+  define(Navigator.prototype, 'language', fp.languages[0]);
+  define(Navigator.prototype, 'languages', Object.freeze(fp.languages.slice()));
+  const patch = (proto) => {
+    if (!proto) return;
+    const getParameter = proto.getParameter;
+    proto.getParameter = function (p) {
+      if (p === 37445) return fp.vendor;
+      if (p === 37446) return fp.renderer;
+      return getParameter.call(this, p);
+    };
+  };
+  patch(window.WebGLRenderingContext && WebGLRenderingContext.prototype);
+  patch(window.WebGL2RenderingContext && WebGL2RenderingContext.prototype);
+})();`
+
+// applyFingerprint 通过 CDP emulation 将指纹应用到页面。
+func applyFingerprint(page *rod.Page, fp *fingerprint.Profile) error {
+	if fp == nil || fp.IsZero() {
+		return nil
+	}
+
+	if err := (proto.EmulationSetUserAgentOverride{
+		UserAgent:         fp.UserAgent,
+		AcceptLanguage:    fp.AcceptLanguage(),
+		Platform:          fp.Platform,
+		UserAgentMetadata: userAgentMetadata(fp.ClientHints()),
+	}).Call(page); err != nil {
+		return fmt.Errorf("set user agent override failed: %w", err)
+	}
+	if fp.ViewportWidth > 0 && fp.ViewportHeight > 0 {
+		if err := (proto.EmulationSetDeviceMetricsOverride{
+			Width:             fp.ViewportWidth,
+			Height:            fp.ViewportHeight,
+			DeviceScaleFactor: fp.DeviceScaleFactor,
+		}).Call(page); err != nil {
+			return fmt.Errorf("set device metrics override failed: %w", err)
+		}
+	}
+	if fp.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: fp.Timezone}).Call(page); err != nil {
+			return fmt.Errorf("set timezone override failed: %w", err)
+		}
+	}
+	if locale := fp.Locale(); locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: locale}).Call(page); err != nil {
+			return fmt.Errorf("set locale override failed: %w", err)
+		}
+	}
+
+	languages := fp.Languages
+	if len(languages) == 0 {
+		languages = []string{"zh-CN"}
+	}
+	data, err := json.Marshal(map[string]any{
+		"platform":  fp.Platform,
+		"languages": languages,
+		"vendor":    fp.WebGLVendor,
+		"renderer":  fp.WebGLRenderer,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := page.EvalOnNewDocument(fmt.Sprintf(fingerprintScript, data)); err != nil {
+		return fmt.Errorf("inject fingerprint script failed: %w", err)
+	}
+	return nil
+}
+
+// userAgentMetadata 转换为 CDP 的 UA 元数据，使 navigator.userAgentData 与 Sec-CH-UA 头和伪装的 UA 一致
+func userAgentMetadata(h *fingerprint.ClientHints) *proto.EmulationUserAgentMetadata {
+	if h == nil {
+		return nil
+	}
+	brands := func(list []fingerprint.Brand) []*proto.EmulationUserAgentBrandVersion {
+		out := make([]*proto.EmulationUserAgentBrandVersion, len(list))
+		for i, b := range list {
+			out[i] = &proto.EmulationUserAgentBrandVersion{Brand: b.Brand, Version: b.Version}
+		}
+		return out
+	}
+	return &proto.EmulationUserAgentMetadata{
+		Brands:          brands(h.Brands),
+		FullVersionList: brands(h.FullVersionList),
+		FullVersion:     h.FullVersion,
+		Platform:        h.Platform,
+		PlatformVersion: h.PlatformVersion,
+		Architecture:    h.Architecture,
+		Bitness:         h.Bitness,
+		Mobile:          h.Mobile,
+	}
+}
@@ -0,0 +1,370 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// 这些 header 包含登录凭证或请求签名，写入 HAR 前脱敏
+var redactedHeaders = map[string]bool{
+	"cookie":              true,
+	"set-cookie":          true,
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-s":                 true,
+	"x-t":                 true,
+	"x-s-common":          true,
+	"x-sign":              true,
+}
+
+// HARRecorder 记录页面的网络请求与响应，导出为 HAR 1.2。
+// 超过 maxBytes 后不再记录新的请求，已记录的数据保持完整。
+// 请求体与响应体可能包含笔记内容、账号信息等，只在 bodies 为 true 时记录。
+type HARRecorder struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bodies   bool
+	size     int64
+	dropped  int
+	entries  []*harEntry
+	pending  map[proto.NetworkRequestID]*harEntry
+	cancel   context.CancelFunc
+}
+
+// NewHARRecorder 开始记录网络流量，调用 Stop 结束。maxBytes<=0 表示不限制，bodies 为 true 时记录请求体与响应体。
+func NewHARRecorder(page *rod.Page, maxBytes int64, bodies bool) *HARRecorder {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	r := &HARRecorder{
+		maxBytes: maxBytes,
+		bodies:   bodies,
+		pending:  map[proto.NetworkRequestID]*harEntry{},
+		cancel:   cancel,
+	}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			r.onRequest(e)
+		},
+		func(e *proto.NetworkResponseReceived) {
+			r.onResponse(e)
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			r.onFinished(page, e)
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			r.onFailed(e)
+		},
+	)
+	go wait()
+
+	return r
+}
+
+// Stop 停止记录。
+func (r *HARRecorder) Stop() {
+	if r != nil && r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *HARRecorder) onRequest(e *proto.NetworkRequestWillBeSent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 重定向时同一个 RequestID 会再次出现，先结束上一跳
+	if prev, ok := r.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+		prev.setResponse(e.RedirectResponse)
+		prev.finish(e.Timestamp)
+		delete(r.pending, e.RequestID)
+	}
+
+	req := e.Request
+	cost := int64(len(req.URL) + headersSize(req.Headers))
+	if r.bodies {
+		cost += int64(len(req.PostData))
+	}
+	if r.maxBytes > 0 && r.size+cost > r.maxBytes {
+		r.dropped++
+		return
+	}
+	r.size += cost
+
+	entry := &harEntry{
+		StartedDateTime: e.WallTime.Time().Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     convertHeaders(req.Headers),
+			QueryString: queryString(req.URL),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(req.PostData),
+		},
+		Response: harResponse{
+			HTTPVersion: "HTTP/1.1",
+			Headers:     []harNameValue{},
+			Cookies:     []harNameValue{},
+			Content:     harContent{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache:        map[string]any{},
+		Timings:      harTimings{Send: 0, Wait: -1, Receive: -1},
+		ResourceType: string(e.Type),
+		start:        e.Timestamp,
+	}
+	if req.PostData != "" && r.bodies {
+		mime := ""
+		for k, v := range req.Headers {
+			if strings.EqualFold(k, "content-type") {
+				mime = v.Str()
+			}
+		}
+		entry.Request.PostData = &harPostData{MimeType: mime, Text: req.PostData}
+	}
+	r.entries = append(r.entries, entry)
+	r.pending[e.RequestID] = entry
+}
+
+func (r *HARRecorder) onResponse(e *proto.NetworkResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.pending[e.RequestID]; ok {
+		entry.setResponse(e.Response)
+		entry.responseAt = e.Timestamp
+	}
+}
+
+func (r *HARRecorder) onFinished(page *rod.Page, e *proto.NetworkLoadingFinished) {
+	r.mu.Lock()
+	entry, ok := r.pending[e.RequestID]
+	if ok {
+		delete(r.pending, e.RequestID)
+		entry.finish(e.Timestamp)
+		entry.Response.BodySize = int(e.EncodedDataLength)
+	}
+	wantBody := ok && r.bodies && captureBody(entry.ResourceType) &&
+		(r.maxBytes <= 0 || r.size+int64(e.EncodedDataLength) <= r.maxBytes)
+	r.mu.Unlock()
+
+	if !wantBody {
+		return
+	}
+	// 只抓取文档与 XHR/Fetch 的响应体，图片视频等资源体积大且无分析价值
+	body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(page)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cost := int64(len(body.Body))
+	if r.maxBytes > 0 && r.size+cost > r.maxBytes {
+		entry.Comment = "body omitted: trace size limit reached"
+		return
+	}
+	r.size += cost
+	entry.Response.Content.Text = body.Body
+	if body.Base64Encoded {
+		entry.Response.Content.Encoding = "base64"
+	}
+	entry.Response.Content.Size = len(body.Body)
+}
+
+func (r *HARRecorder) onFailed(e *proto.NetworkLoadingFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.pending[e.RequestID]; ok {
+		delete(r.pending, e.RequestID)
+		entry.finish(e.Timestamp)
+		entry.Comment = "failed: " + e.ErrorText
+		if e.Canceled {
+			entry.Comment = "canceled"
+		}
+	}
+}
+
+// Truncated 是否因大小限制丢弃过请求。
+func (r *HARRecorder) Truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped > 0
+}
+
+// HAR 导出 HAR JSON。
+func (r *HARRecorder) HAR() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]*harEntry, len(r.entries))
+	copy(entries, r.entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].start < entries[j].start })
+
+	log := harLog{
+		Version: "1.2",
+		Creator: harNameVersion{Name: "xiaohongshu-mcp", Version: "1.0"},
+		Pages:   []any{},
+		Entries: entries,
+	}
+	if r.dropped > 0 {
+		log.Comment = "truncated: trace size limit reached"
+	}
+	return json.MarshalIndent(map[string]any{"log": log}, "", "  ")
+}
+
+func captureBody(resourceType string) bool {
+	switch proto.NetworkResourceType(resourceType) {
+	case proto.NetworkResourceTypeDocument, proto.NetworkResourceTypeXHR, proto.NetworkResourceTypeFetch:
+		return true
+	}
+	return false
+}
+
+func convertHeaders(h proto.NetworkHeaders) []harNameValue {
+	out := make([]harNameValue, 0, len(h))
+	for k, v := range h {
+		value := v.Str()
+		if redactedHeaders[strings.ToLower(k)] {
+			value = "<redacted>"
+		}
+		out = append(out, harNameValue{Name: k, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func headersSize(h proto.NetworkHeaders) int {
+	n := 0
+	for k, v := range h {
+		n += len(k) + len(v.Str())
+	}
+	return n
+}
+
+func queryString(raw string) []harNameValue {
+	out := []harNameValue{}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return out
+	}
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type harLog struct {
+	Version string         `json:"version"`
+	Creator harNameVersion `json:"creator"`
+	Pages   []any          `json:"pages"`
+	Entries []*harEntry    `json:"entries"`
+	Comment string         `json:"comment,omitempty"`
+}
+
+type harNameVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harEntry struct {
+	StartedDateTime string         `json:"startedDateTime"`
+	Time            float64        `json:"time"`
+	Request         harRequest     `json:"request"`
+	Response        harResponse    `json:"response"`
+	Cache           map[string]any `json:"cache"`
+	Timings         harTimings     `json:"timings"`
+	ResourceType    string         `json:"_resourceType,omitempty"`
+	Comment         string         `json:"comment,omitempty"`
+
+	start      proto.MonotonicTime
+	responseAt proto.MonotonicTime
+}
+
+func (e *harEntry) setResponse(resp *proto.NetworkResponse) {
+	e.Response.Status = resp.Status
+	e.Response.StatusText = resp.StatusText
+	e.Response.Headers = convertHeaders(resp.Headers)
+	e.Response.Content.MimeType = resp.MIMEType
+	for k, v := range resp.Headers {
+		if strings.EqualFold(k, "location") {
+			e.Response.RedirectURL = v.Str()
+		}
+	}
+}
+
+func (e *harEntry) finish(at proto.MonotonicTime) {
+	e.Time = ms(at - e.start)
+	if e.responseAt > 0 {
+		e.Timings.Wait = ms(e.responseAt - e.start)
+		e.Timings.Receive = ms(at - e.responseAt)
+	} else {
+		e.Timings.Wait = e.Time
+		e.Timings.Receive = 0
+	}
+}
+
+func ms(d proto.MonotonicTime) float64 {
+	if d < 0 {
+		return 0
+	}
+	return float64(d.Duration()) / float64(time.Millisecond)
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
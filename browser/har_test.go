@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+	"github.com/ysmood/gson"
+)
+
+func TestHARRecorder_EntriesAndLimit(t *testing.T) {
+	r := &HARRecorder{maxBytes: 400, bodies: true, pending: map[proto.NetworkRequestID]*harEntry{}}
+
+	r.onRequest(&proto.NetworkRequestWillBeSent{
+		RequestID: "1",
+		Request: &proto.NetworkRequest{
+			Method:  "GET",
+			URL:     "https://edith.xiaohongshu.com/api/sns/web/v1/search/notes?keyword=a",
+			Headers: proto.NetworkHeaders{"Cookie": gson.New("web_session=secret")},
+		},
+		Timestamp: 1,
+		Type:      proto.NetworkResourceTypeXHR,
+	})
+	r.onResponse(&proto.NetworkResponseReceived{
+		RequestID: "1",
+		Timestamp: 1.2,
+		Response: &proto.NetworkResponse{
+			Status:   461,
+			MIMEType: "application/json",
+			Headers:  proto.NetworkHeaders{"Set-Cookie": gson.New("a=b")},
+		},
+	})
+	r.onFailed(&proto.NetworkLoadingFailed{RequestID: "1", Timestamp: 1.5, ErrorText: "net::ERR_ABORTED"})
+
+	// 超过大小上限的请求被丢弃
+	big := make([]byte, 500)
+	r.onRequest(&proto.NetworkRequestWillBeSent{
+		RequestID: "2",
+		Request:   &proto.NetworkRequest{Method: "POST", URL: "https://example.com", PostData: string(big)},
+		Timestamp: 2,
+	})
+	require.True(t, r.Truncated())
+
+	data, err := r.HAR()
+	require.NoError(t, err)
+
+	var out struct {
+		Log struct {
+			Version string `json:"version"`
+			Comment string `json:"comment"`
+			Entries []struct {
+				Time    float64 `json:"time"`
+				Request struct {
+					Headers     []harNameValue `json:"headers"`
+					QueryString []harNameValue `json:"queryString"`
+				} `json:"request"`
+				Response struct {
+					Status  int            `json:"status"`
+					Headers []harNameValue `json:"headers"`
+				} `json:"response"`
+				Comment string `json:"comment"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, "1.2", out.Log.Version)
+	require.NotEmpty(t, out.Log.Comment)
+	require.Len(t, out.Log.Entries, 1)
+
+	e := out.Log.Entries[0]
+	require.Equal(t, 461, e.Response.Status)
+	require.InDelta(t, 500, e.Time, 0.001)
+	require.Equal(t, "<redacted>", e.Request.Headers[0].Value)
+	require.Equal(t, "<redacted>", e.Response.Headers[0].Value)
+	require.Equal(t, []harNameValue{{Name: "keyword", Value: "a"}}, e.Request.QueryString)
+	require.Equal(t, "failed: net::ERR_ABORTED", e.Comment)
+}
+
+func TestHARRecorder_RedactsSigningHeadersAndOmitsBodies(t *testing.T) {
+	r := &HARRecorder{pending: map[proto.NetworkRequestID]*harEntry{}}
+	r.onRequest(&proto.NetworkRequestWillBeSent{
+		RequestID: "1",
+		Request: &proto.NetworkRequest{
+			Method: "POST",
+			URL:    "https://edith.xiaohongshu.com/web_api/sns/v2/note",
+			Headers: proto.NetworkHeaders{
+				"X-S":           gson.New("XYW_sign"),
+				"x-t":           gson.New("1700000000000"),
+				"Authorization": gson.New("Bearer token"),
+				"Content-Type":  gson.New("application/json"),
+			},
+			PostData: `{"title":"draft"}`,
+		},
+		Timestamp: 1,
+		Type:      proto.NetworkResourceTypeXHR,
+	})
+
+	require.Len(t, r.entries, 1)
+	e := r.entries[0]
+	require.Nil(t, e.Request.PostData)
+	for _, h := range e.Request.Headers {
+		if h.Name == "Content-Type" {
+			require.Equal(t, "application/json", h.Value)
+			continue
+		}
+		require.Equal(t, "<redacted>", h.Value, h.Name)
+	}
+}
@@ -0,0 +1,143 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxRecorded 每类错误最多保留的条数，只保留最近的记录。
+const maxRecorded = 50
+
+// Recorder 记录页面的控制台错误与失败的网络请求，用于失败现场分析。
+type Recorder struct {
+	mu      sync.Mutex
+	console []string
+	network []string
+	cancel  context.CancelFunc
+}
+
+// NewRecorder 开始监听页面事件，调用 Stop 结束。
+func NewRecorder(page *rod.Page) *Recorder {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	r := &Recorder{cancel: cancel}
+
+	requests := map[proto.NetworkRequestID]string{}
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.RuntimeConsoleAPICalled) {
+			if e.Type != proto.RuntimeConsoleAPICalledTypeError && e.Type != proto.RuntimeConsoleAPICalledTypeWarning {
+				return
+			}
+			parts := make([]string, 0, len(e.Args))
+			for _, arg := range e.Args {
+				if arg.Description != "" {
+					parts = append(parts, arg.Description)
+				} else {
+					parts = append(parts, arg.Value.String())
+				}
+			}
+			r.add(&r.console, fmt.Sprintf("[%s] %s", e.Type, strings.Join(parts, " ")))
+		},
+		func(e *proto.RuntimeExceptionThrown) {
+			msg := e.ExceptionDetails.Text
+			if e.ExceptionDetails.Exception != nil && e.ExceptionDetails.Exception.Description != "" {
+				msg = e.ExceptionDetails.Exception.Description
+			}
+			r.add(&r.console, "[exception] "+msg)
+		},
+		func(e *proto.NetworkRequestWillBeSent) {
+			r.mu.Lock()
+			requests[e.RequestID] = e.Request.Method + " " + e.Request.URL
+			r.mu.Unlock()
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if e.Response.Status >= 400 {
+				r.add(&r.network, fmt.Sprintf("%d %s", e.Response.Status, e.Response.URL))
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			if e.Canceled {
+				return
+			}
+			r.mu.Lock()
+			req := requests[e.RequestID]
+			r.mu.Unlock()
+			r.add(&r.network, fmt.Sprintf("failed %s: %s", req, e.ErrorText))
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			r.mu.Lock()
+			delete(requests, e.RequestID)
+			r.mu.Unlock()
+		},
+	)
+	go wait()
+
+	return r
+}
+
+func (r *Recorder) add(list *[]string, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*list = append(*list, time.Now().Format("15:04:05.000")+" "+line)
+	if len(*list) > maxRecorded {
+		*list = (*list)[len(*list)-maxRecorded:]
+	}
+}
+
+// Stop 停止监听。
+func (r *Recorder) Stop() {
+	if r != nil && r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// ConsoleErrors 返回记录的控制台错误与异常。
+func (r *Recorder) ConsoleErrors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.console...)
+}
+
+// NetworkErrors 返回记录的失败请求（状态码 >= 400 或加载失败）。
+func (r *Recorder) NetworkErrors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.network...)
+}
+
+// Snapshot 页面现场。
+type Snapshot struct {
+	URL        string
+	HTML       string
+	Screenshot []byte
+}
+
+// CaptureSnapshot 抓取页面 URL、HTML 与整页截图，单项失败不影响其它项。
+func CaptureSnapshot(page *rod.Page, timeout time.Duration) (*Snapshot, []error) {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	snap := &Snapshot{}
+	var errs []error
+	if info, err := p.Info(); err == nil {
+		snap.URL = info.URL
+	} else {
+		errs = append(errs, fmt.Errorf("get url: %w", err))
+	}
+	if html, err := p.HTML(); err == nil {
+		snap.HTML = html
+	} else {
+		errs = append(errs, fmt.Errorf("get html: %w", err))
+	}
+	if img, err := p.Screenshot(true, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng}); err == nil {
+		snap.Screenshot = img
+	} else {
+		errs = append(errs, fmt.Errorf("screenshot: %w", err))
+	}
+	return snap, errs
+}
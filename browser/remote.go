@@ -0,0 +1,123 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+)
+
+// WithRemoteURL 连接已有的 Chrome DevTools 端点，而不是在本机启动浏览器。
+// 支持 ws://.../devtools/browser/<id>、http://host:9222 以及 host:port 形式。
+func WithRemoteURL(endpoint string) Option {
+	return func(c *browserConfig) {
+		c.remoteURL = strings.TrimSpace(endpoint)
+	}
+}
+
+// WithRemoteIsolation 在远程浏览器中使用独立的浏览器上下文（cookies、存储互不可见），
+// 用于多个账号共用同一个远程浏览器的场景；断开连接时上下文随之销毁。
+func WithRemoteIsolation() Option {
+	return func(c *browserConfig) {
+		c.remoteIsolated = true
+	}
+}
+
+// ResolveRemoteURL 将远程端点解析为可直接连接的 websocket 地址。
+func ResolveRemoteURL(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", fmt.Errorf("remote cdp endpoint is empty")
+	}
+	if strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://") {
+		return endpoint, nil
+	}
+	return launcher.ResolveURL(endpoint)
+}
+
+// connectRemote 连接远程浏览器。远程浏览器由外部管理：
+// Close 时只断开连接（独立上下文会被销毁），不关闭浏览器；
+// 远程浏览器已登录的正是本账号时不会被本地 cookies 覆盖。
+func connectRemote(cfg *browserConfig) (*Browser, error) {
+	if cfg.proxyURL != "" {
+		logrus.Warnf("remote browser ignores proxy %s, configure the proxy on the remote host", cfg.proxyURL)
+	}
+
+	u, err := ResolveRemoteURL(cfg.remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("resolve remote browser failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := rod.New().Context(ctx).ControlURL(u)
+	if err := b.Connect(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("connect remote browser failed: %w", err)
+	}
+
+	if cfg.remoteIsolated {
+		ib, err := b.Incognito()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("create remote browser context failed: %w", err)
+		}
+		b = ib
+	}
+
+	if cfg.cookier != nil {
+		injectRemoteCookies(b, cfg.cookier)
+	}
+
+	return &Browser{browser: b, remote: true, cancel: cancel, fingerprint: matchBrowser(b, cfg.fingerprint)}, nil
+}
+
+// injectRemoteCookies 远程浏览器已登录的是本账号（登录态与本地保存的一致，或本地尚无登录态）时保留其 cookies，
+// 否则注入本地保存的 cookies，避免共用的远程浏览器以其他账号的身份执行操作。
+func injectRemoteCookies(b *rod.Browser, cookier cookies.Cookier) {
+	var stored []*proto.NetworkCookie
+	data, err := cookier.LoadCookies()
+	if err != nil {
+		logrus.Warnf("failed to load cookies: %v", err)
+		return
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			logrus.Warnf("failed to unmarshal cookies: %v", err)
+			return
+		}
+	}
+
+	existing, err := b.GetCookies()
+	if err == nil && keepRemoteSession(existing, stored, time.Now()) {
+		logrus.Debugf("remote browser already has this account's login session, skip cookie injection")
+		return
+	}
+	if len(stored) == 0 {
+		return
+	}
+	if err := b.SetCookies(proto.CookiesToParams(stored)); err != nil {
+		logrus.Warnf("failed to set cookies: %v", err)
+	}
+}
+
+// keepRemoteSession 远程登录态有效，且本地没有登录态或两者的会话值相同
+func keepRemoteSession(existing, stored []*proto.NetworkCookie, now time.Time) bool {
+	remote := cookies.SessionValue(existing, now)
+	if remote == "" {
+		return false
+	}
+	local := cookies.SessionValue(stored, now)
+	return local == "" || local == remote
+}
+
+// IsRemote 是否为连接的远程浏览器。
+func (b *Browser) IsRemote() bool {
+	return b != nil && b.remote
+}
@@ -0,0 +1,71 @@
+package browser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRemoteURL(t *testing.T) {
+	ws := "ws://10.0.0.2:3000/devtools/browser/abc"
+	got, err := ResolveRemoteURL(ws)
+	require.NoError(t, err)
+	require.Equal(t, ws, got)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/json/version", r.URL.Path)
+		_, _ = w.Write([]byte(`{"webSocketDebuggerUrl":"ws://127.0.0.1:9222/devtools/browser/xyz"}`))
+	}))
+	defer srv.Close()
+
+	got, err = ResolveRemoteURL(srv.URL)
+	require.NoError(t, err)
+	require.Contains(t, got, "/devtools/browser/xyz")
+
+	_, err = ResolveRemoteURL("  ")
+	require.Error(t, err)
+}
+
+func TestKeepRemoteSession(t *testing.T) {
+	now := time.Now()
+	session := func(v string) []*proto.NetworkCookie {
+		return []*proto.NetworkCookie{{Name: "web_session", Value: v, Domain: ".xiaohongshu.com"}}
+	}
+
+	// 远程已登录的就是本账号，或本地尚无登录态
+	require.True(t, keepRemoteSession(session("a"), session("a"), now))
+	require.True(t, keepRemoteSession(session("a"), nil, now))
+	// 共用远程浏览器中登录的是其他账号
+	require.False(t, keepRemoteSession(session("a"), session("b"), now))
+	require.False(t, keepRemoteSession(nil, session("b"), now))
+
+	expired := session("a")
+	expired[0].Expires = proto.TimeSinceEpoch(now.Add(-time.Hour).Unix())
+	require.False(t, keepRemoteSession(expired, nil, now))
+}
+
+// hangingWS 接受请求但永远不返回响应，模拟卡住的 Chrome 或远程 CDP
+type hangingWS struct{ done chan struct{} }
+
+func (w *hangingWS) Send([]byte) error { return nil }
+
+func (w *hangingWS) Read() ([]byte, error) {
+	<-w.done
+	return nil, http.ErrServerClosed
+}
+
+func TestPingTimesOut(t *testing.T) {
+	ws := &hangingWS{done: make(chan struct{})}
+	defer close(ws.done)
+	b := &Browser{browser: rod.New().Client(cdp.New().Start(ws))}
+
+	start := time.Now()
+	require.Error(t, b.Ping())
+	require.Less(t, time.Since(start), pingTimeout+2*time.Second)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/profilestore"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+)
+
+const usage = `用法: cleanup <子命令> [参数]
+
+子命令:
+  profiles   清理账号的持久化浏览器 profile（<data_dir>/profiles）
+  media      清理远程图片/视频的下载缓存（<data_dir>/media_cache）
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "profiles":
+		runProfiles(os.Args[2:])
+	case "media":
+		runMedia(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func resolveDataDir(dataDir string) string {
+	if dataDir == "" {
+		dataDir = os.Getenv("XHS_MCP_DATA_DIR")
+	}
+	if dataDir == "" {
+		dataDir = "."
+	}
+	return dataDir
+}
+
+// runProfiles 清理持久化 profile：
+//
+//	cleanup profiles -list
+//	cleanup profiles -account alice,bob
+//	cleanup profiles -orphans -older_than 720h -dry_run
+//	cleanup profiles -all -cache_only
+func runProfiles(args []string) {
+	fs := flag.NewFlagSet("profiles", flag.ExitOnError)
+	var (
+		dataDir   string
+		all       bool
+		accounts  string
+		olderThan time.Duration
+		orphans   bool
+		cacheOnly bool
+		dryRun    bool
+		list      bool
+	)
+	fs.StringVar(&dataDir, "data_dir", "", "数据目录（users.json/ip.txt/cookies等）")
+	fs.BoolVar(&all, "all", false, "清理全部 profile（会删除所有账号的登录态与草稿）")
+	fs.StringVar(&accounts, "account", "", "只清理指定账号，多个用逗号分隔")
+	fs.DurationVar(&olderThan, "older_than", 0, "清理超过该时长未使用的 profile，如 720h")
+	fs.BoolVar(&orphans, "orphans", false, "清理 users.json 中已不存在的账号的 profile")
+	fs.BoolVar(&cacheOnly, "cache_only", false, "只删除缓存目录，保留登录态与本地存储")
+	fs.BoolVar(&dryRun, "dry_run", false, "只列出将被清理的 profile，不实际删除")
+	fs.BoolVar(&list, "list", false, "列出所有 profile")
+	_ = fs.Parse(args)
+
+	dataDir = resolveDataDir(dataDir)
+	store := profilestore.NewStore(dataDir)
+
+	if list {
+		profiles, err := store.List()
+		if err != nil {
+			logrus.Fatalf("列出 profile 失败: %v", err)
+		}
+		for _, p := range profiles {
+			fmt.Printf("%s\t%s\t%d bytes\t%s%s\n", p.Name, p.ModTime.Format(time.RFC3339), p.SizeBytes, p.Dir, inUseNote(p))
+		}
+		return
+	}
+
+	opts := profilestore.CleanupOptions{
+		All:       all,
+		OlderThan: olderThan,
+		Orphans:   orphans,
+		CacheOnly: cacheOnly,
+		DryRun:    dryRun,
+	}
+	for _, a := range strings.Split(accounts, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			opts.Accounts = append(opts.Accounts, a)
+		}
+	}
+	if orphans {
+		up, err := userpool.NewManager(dataDir)
+		if err != nil {
+			logrus.Fatalf("failed to load users.json: %v", err)
+		}
+		for _, u := range up.ListSummaries() {
+			opts.KnownAccounts = append(opts.KnownAccounts, u.Account)
+		}
+	}
+
+	removed, err := store.Cleanup(opts)
+	if err != nil {
+		logrus.Fatalf("清理 profile 失败: %v", err)
+	}
+
+	var (
+		total   int64
+		cleaned int
+	)
+	for _, p := range removed {
+		fmt.Printf("%s\t%d bytes\t%s%s\n", p.Name, p.SizeBytes, p.Dir, inUseNote(p))
+		if p.InUse {
+			continue
+		}
+		cleaned++
+		total += p.SizeBytes
+	}
+	action := "已清理"
+	if dryRun {
+		action = "将清理"
+	}
+	logrus.Infof("%s %d 个 profile，共 %d bytes；%d 个正在使用已跳过", action, cleaned, total, len(removed)-cleaned)
+}
+
+func inUseNote(p profilestore.Profile) string {
+	if p.InUse {
+		return "\t(正在使用，跳过)"
+	}
+	return ""
+}
+
+// runMedia 清理下载缓存，不带参数时按 XHS_MCP_MEDIA_CACHE_TTL / XHS_MCP_MEDIA_CACHE_MAX_BYTES 清理：
+//
+//	cleanup media -list
+//	cleanup media -older_than 24h -dry_run
+//	cleanup media -max_bytes 536870912
+//	cleanup media -all
+func runMedia(args []string) {
+	fs := flag.NewFlagSet("media", flag.ExitOnError)
+	var (
+		dataDir   string
+		olderThan time.Duration
+		maxBytes  int64
+		all       bool
+		dryRun    bool
+		list      bool
+	)
+	fs.StringVar(&dataDir, "data_dir", "", "数据目录（users.json/ip.txt/cookies等）")
+	fs.DurationVar(&olderThan, "older_than", 0, "清理超过该时长未使用的缓存，如 24h")
+	fs.Int64Var(&maxBytes, "max_bytes", 0, "清理后缓存总大小不超过该字节数")
+	fs.BoolVar(&all, "all", false, "清空全部缓存")
+	fs.BoolVar(&dryRun, "dry_run", false, "只列出将被清理的文件，不实际删除")
+	fs.BoolVar(&list, "list", false, "列出缓存内容")
+	_ = fs.Parse(args)
+
+	configs.InitDataDir(resolveDataDir(dataDir))
+	cache := downloader.DefaultCache()
+
+	if list {
+		entries, err := cache.List()
+		if err != nil {
+			logrus.Fatalf("读取下载缓存失败: %v", err)
+		}
+		for _, e := range entries {
+			fmt.Printf("%s.%s\t%s\t%d bytes\t%s\n", e.SHA256, e.Ext, e.LastUsedAt.Format(time.RFC3339), e.Size, strings.Join(e.URLs, ","))
+		}
+		return
+	}
+
+	res, err := cache.Cleanup(downloader.CacheCleanupOptions{
+		OlderThan: olderThan,
+		MaxBytes:  maxBytes,
+		All:       all,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		logrus.Fatalf("清理下载缓存失败: %v", err)
+	}
+
+	for _, e := range res.Removed {
+		fmt.Printf("%s.%s\t%d bytes\t%s\n", e.SHA256, e.Ext, e.Size, strings.Join(e.URLs, ","))
+	}
+	for _, f := range res.Files {
+		fmt.Println(f)
+	}
+	action := "已清理"
+	if dryRun {
+		action = "将清理"
+	}
+	logrus.Infof("%s %d 个缓存内容与 %d 个其他文件，共 %d bytes", action, len(res.Removed), len(res.Files), res.RemovedBytes+res.FileBytes)
+}
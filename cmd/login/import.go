@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
+)
+
+// runImport 导入浏览器导出的 cookies 并绑定到账号，无需打开浏览器。
+//
+//	login import -account alice -file cookies.txt [-format auto|netscape|json|header]
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		dataDir string
+		account string
+		file    string
+		format  string
+	)
+	fs.StringVar(&dataDir, "data_dir", "", "数据目录（users.json/ip.txt/cookies等）")
+	fs.StringVar(&account, "account", "", "导入到的账号（users.json中的account），不存在时自动创建")
+	fs.StringVar(&file, "file", "-", "cookies 文件路径，- 表示从标准输入读取")
+	fs.StringVar(&format, "format", cookies.FormatAuto, "格式：auto/netscape/json/header")
+	_ = fs.Parse(args)
+
+	if dataDir == "" {
+		dataDir = os.Getenv("XHS_MCP_DATA_DIR")
+	}
+	if dataDir == "" {
+		dataDir = "."
+	}
+	account = strings.TrimSpace(account)
+	if account == "" {
+		logrus.Fatal("缺少 -account")
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		logrus.Fatalf("读取 cookies 失败: %v", err)
+	}
+
+	res, err := cookies.ParseImport(data, format)
+	if err != nil {
+		logrus.Fatalf("解析 cookies 失败: %v", err)
+	}
+	for _, w := range res.Warnings {
+		logrus.Warn(w)
+	}
+
+	up, err := userpool.NewManager(dataDir)
+	if err != nil {
+		logrus.Fatalf("failed to load users.json: %v", err)
+	}
+	store := openCookieStore(dataDir)
+	defer store.Close()
+
+	hint := ""
+	if u, err := up.Resolve(account, nil); err == nil {
+		hint = u.CookieFile
+	}
+	ref, err := store.Import(account, hint, res.Cookies)
+	if err != nil {
+		logrus.Fatalf("保存 cookies 失败: %v", err)
+	}
+	if _, err := up.UpsertCookie(account, ref); err != nil {
+		logrus.Fatalf("更新 users.json 失败: %v", err)
+	}
+
+	logrus.Infof("已导入 %d 条 cookies（格式 %s，忽略 %d 条非小红书域名）到账号 %s: %s",
+		res.Count, res.Format, res.Dropped, account, store.Location(account, ref))
+}
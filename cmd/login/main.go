@@ -10,24 +10,37 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/cookiestore"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/ippool"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/profilestore"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(os.Args[2:])
+		return
+	}
+
 	var (
 		binPath string
 		dataDir string
 		account string
 		index   int
+
+		persistentProfile bool
+		remoteCDP         string
 	)
 	flag.StringVar(&binPath, "bin", "", "浏览器二进制文件路径")
 	flag.StringVar(&dataDir, "data_dir", "", "数据目录（users.json/ip.txt/cookies等）")
 	flag.StringVar(&account, "account", "", "账号（users.json中的account）")
 	flag.IntVar(&index, "index", -1, "用户序号（users.json中的索引，从0开始）")
+	flag.BoolVar(&persistentProfile, "persistent_profile", false, "使用账号的持久化浏览器 profile（与服务端 -persistent_profile 一致）")
+	flag.StringVar(&remoteCDP, "remote_cdp", "", "在已有浏览器的 DevTools 端点上登录（如 http://127.0.0.1:9222）")
 	flag.Parse()
 
 	if dataDir == "" {
@@ -48,7 +61,8 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("failed to load ip.txt: %v", err)
 	}
-	store := cookiestore.NewStore(dataDir)
+	store := openCookieStore(dataDir)
+	defer store.Close()
 
 	ips := ipPool.All()
 	if len(ips) > 0 {
@@ -80,8 +94,7 @@ func main() {
 	if err != nil {
 		u = userpool.User{Account: account, Enabled: true}
 	}
-	cookieAbs, cookieRel := store.CookiePathFor(account, u.CookieFile)
-	_ = store.EnsureDir(cookieAbs)
+	cookier, cookieRef := store.CookierFor(account, u.CookieFile)
 
 	proxy := ""
 	if len(ips) > 0 {
@@ -101,13 +114,37 @@ func main() {
 		}
 	}
 
-	// 登录的时候，需要界面，所以不能无头模式
-	b, err := browser.NewBrowser(
-		false,
+	if remoteCDP == "" {
+		remoteCDP = u.RemoteCDP
+	}
+	if remoteCDP == "" {
+		remoteCDP = os.Getenv("XHS_MCP_REMOTE_CDP")
+	}
+
+	opts := []browser.Option{
 		browser.WithBinPath(binPath),
-		browser.WithCookiesPath(cookieAbs),
+		browser.WithCookier(cookier),
 		browser.WithProxyURL(proxy),
-	)
+	}
+	if remoteCDP != "" {
+		opts = append(opts, browser.WithRemoteURL(remoteCDP))
+	} else if persistentProfile || os.Getenv("XHS_MCP_PERSISTENT_PROFILE") == "true" {
+		dir, err := profilestore.NewStore(dataDir).Prepare(account)
+		if err != nil {
+			logrus.Fatalf("failed to prepare profile dir: %v", err)
+		}
+		opts = append(opts, browser.WithUserDataDir(dir))
+	}
+	if remoteCDP == "" {
+		fp, err := up.EnsureFingerprint(account, func() fingerprint.Profile { return fingerprint.Generate(account) })
+		if err != nil {
+			logrus.Warnf("failed to persist fingerprint: %v", err)
+		}
+		opts = append(opts, browser.WithFingerprint(&fp))
+	}
+
+	// 登录的时候，需要界面，所以不能无头模式
+	b, err := browser.NewBrowser(false, opts...)
 	if err != nil {
 		logrus.Fatalf("failed to launch browser: %v", err)
 	}
@@ -134,10 +171,10 @@ func main() {
 	if err = action.Login(context.Background()); err != nil {
 		logrus.Fatalf("登录失败: %v", err)
 	} else {
-		if err := saveCookies(page, cookieAbs); err != nil {
+		if err := saveCookies(page, cookier); err != nil {
 			logrus.Fatalf("failed to save cookies: %v", err)
 		}
-		_, _ = up.UpsertCookie(account, cookieRel)
+		_, _ = up.UpsertCookie(account, cookieRef)
 	}
 
 	// 再次检查登录状态确认成功
@@ -154,7 +191,23 @@ func main() {
 
 }
 
-func saveCookies(page *rod.Page, cookiePath string) error {
+// openCookieStore 按配置打开 cookies 存储后端
+func openCookieStore(dataDir string) *cookiestore.Store {
+	configs.InitDataDir(dataDir)
+	backend, err := cookies.OpenBackend(cookies.BackendConfig{
+		Name:         configs.GetCookieBackend(),
+		BoltPath:     configs.GetCookieBoltPath(),
+		BoltVersions: configs.GetCookieBoltVersions(),
+		KVURL:        configs.GetCookieKVURL(),
+		KVToken:      configs.GetCookieKVToken(),
+	})
+	if err != nil {
+		logrus.Fatalf("failed to open cookies backend: %v", err)
+	}
+	return cookiestore.NewStoreWithBackend(dataDir, backend)
+}
+
+func saveCookies(page *rod.Page, cookieLoader cookies.Cookier) error {
 	cks, err := page.Browser().GetCookies()
 	if err != nil {
 		return err
@@ -165,6 +218,5 @@ func saveCookies(page *rod.Page, cookiePath string) error {
 		return err
 	}
 
-	cookieLoader := cookies.NewLoadCookie(cookiePath)
 	return cookieLoader.SaveCookies(data)
 }
@@ -0,0 +1,64 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsArtifactsEnabled 操作失败时是否保存现场快照，默认开启，XHS_MCP_ARTIFACTS=false 关闭。
+func IsArtifactsEnabled() bool {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_ARTIFACTS"))
+	if v == "" {
+		return true
+	}
+	b, err := strconv.ParseBool(v)
+	return err != nil || b
+}
+
+// GetArtifactsMaxAge 快照保留时间，默认 72h。
+func GetArtifactsMaxAge() time.Duration {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_ARTIFACTS_MAX_AGE"))
+	if v == "" {
+		return 72 * time.Hour
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 72 * time.Hour
+	}
+	return d
+}
+
+// GetArtifactsMaxCount 最多保留的快照数量，默认 200。
+func GetArtifactsMaxCount() int {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_ARTIFACTS_MAX_COUNT"))
+	if v == "" {
+		return 200
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 200
+	}
+	return n
+}
+
+// GetTraceMaxBytes 单次请求网络追踪（HAR）的大小上限，默认 20MB。
+func GetTraceMaxBytes() int64 {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_TRACE_MAX_BYTES"))
+	if v == "" {
+		return 20 << 20
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 20 << 20
+	}
+	return n
+}
+
+// IsTraceBodiesEnabled 网络追踪是否记录请求体与响应体，默认关闭，XHS_MCP_TRACE_BODIES=true 开启。
+// 请求体与响应体可能包含笔记内容与账号信息，快照接口没有鉴权，只在排查问题时临时开启。
+func IsTraceBodiesEnabled() bool {
+	b, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("XHS_MCP_TRACE_BODIES")))
+	return err == nil && b
+}
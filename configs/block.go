@@ -0,0 +1,50 @@
+package configs
+
+import (
+	"os"
+	"strings"
+)
+
+// 只读操作默认拦截图片/视频/字体与第三方统计请求，其它操作（发布、评论、登录等）完整加载
+var defaultBlockProfiles = map[string]string{
+	"list_feeds":   "strict",
+	"search_feeds": "strict",
+	"user_profile": "strict",
+	"my_profile":   "strict",
+	"check_login":  "strict",
+	"list_notes":   "strict",
+	"get_note":     "strict",
+	"list_drafts":  "strict",
+}
+
+// GetBlockProfile 返回操作的资源拦截策略（none/media/strict）。
+// XHS_MCP_BLOCK_PROFILES 形如 "search_feeds=media,feed_detail=strict,*=none"，
+// 优先级：具体操作 > "*" > 内置默认值。
+func GetBlockProfile(action string) string {
+	overrides := parseBlockProfiles(os.Getenv("XHS_MCP_BLOCK_PROFILES"))
+	if v, ok := overrides[action]; ok {
+		return v
+	}
+	if v, ok := overrides["*"]; ok {
+		return v
+	}
+	if v, ok := defaultBlockProfiles[action]; ok {
+		return v
+	}
+	return "none"
+}
+
+func parseBlockProfiles(s string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		k, v = strings.TrimSpace(k), strings.ToLower(strings.TrimSpace(v))
+		if k != "" && v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
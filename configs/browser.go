@@ -1,9 +1,22 @@
 package configs
 
+import (
+	"strings"
+	"time"
+)
+
 var (
 	useHeadless = true
 
 	binPath = ""
+
+	persistentProfile = false
+
+	warmPool        = false
+	warmPoolSize    = 0
+	warmPoolIdleTTL = 10 * time.Minute
+
+	remoteCDP = ""
 )
 
 func InitHeadless(h bool) {
@@ -22,3 +35,56 @@ func SetBinPath(b string) {
 func GetBinPath() string {
 	return binPath
 }
+
+// InitPersistentProfile 是否为每个账号使用持久化的 user-data-dir。
+func InitPersistentProfile(enable bool) {
+	persistentProfile = enable
+}
+
+// IsPersistentProfile 是否启用持久化 profile。
+func IsPersistentProfile() bool {
+	return persistentProfile
+}
+
+// InitWarmPool 是否启用按账号复用的常驻浏览器池。
+func InitWarmPool(enable bool) {
+	warmPool = enable
+}
+
+// IsWarmPool 是否启用常驻浏览器池。
+func IsWarmPool() bool {
+	return warmPool
+}
+
+// InitWarmPoolSize 常驻浏览器最大数量，<=0 时与浏览器并发池大小一致。
+func InitWarmPoolSize(n int) {
+	warmPoolSize = n
+}
+
+func GetWarmPoolSize() int {
+	if warmPoolSize > 0 {
+		return warmPoolSize
+	}
+	return GetBrowserPoolSize()
+}
+
+// InitWarmPoolIdleTTL 常驻浏览器空闲多久后关闭。
+func InitWarmPoolIdleTTL(d time.Duration) {
+	if d > 0 {
+		warmPoolIdleTTL = d
+	}
+}
+
+func GetWarmPoolIdleTTL() time.Duration {
+	return warmPoolIdleTTL
+}
+
+// InitRemoteCDP 全局远程浏览器 DevTools 端点，设置后不再启动本地浏览器。
+// users.json 中账号的 remote_cdp 优先。
+func InitRemoteCDP(endpoint string) {
+	remoteCDP = strings.TrimSpace(endpoint)
+}
+
+func GetRemoteCDP() string {
+	return remoteCDP
+}
@@ -0,0 +1,56 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+var cookieBackend = ""
+
+// InitCookieBackend 设置 cookies 存储后端（file/bolt/http），优先级高于环境变量。
+func InitCookieBackend(name string) {
+	cookieBackend = strings.ToLower(strings.TrimSpace(name))
+}
+
+// GetCookieBackend cookies 存储后端，默认 file。
+func GetCookieBackend() string {
+	if cookieBackend != "" {
+		return cookieBackend
+	}
+	if v := strings.TrimSpace(os.Getenv("XHS_MCP_COOKIE_BACKEND")); v != "" {
+		return strings.ToLower(v)
+	}
+	return "file"
+}
+
+// GetCookieBoltPath bolt 后端数据库文件路径，默认 <data_dir>/cookies.db。
+func GetCookieBoltPath() string {
+	if v := strings.TrimSpace(os.Getenv("XHS_MCP_COOKIE_BOLT_PATH")); v != "" {
+		return ResolveDataPath(v)
+	}
+	return ResolveDataPath("cookies.db")
+}
+
+// GetCookieBoltVersions bolt 后端每个账号保留的历史版本数。
+func GetCookieBoltVersions() int {
+	v := os.Getenv("XHS_MCP_COOKIE_BOLT_VERSIONS")
+	if v == "" {
+		return 10
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 10
+	}
+	return n
+}
+
+// GetCookieKVURL http 后端的基础地址。
+func GetCookieKVURL() string {
+	return strings.TrimSpace(os.Getenv("XHS_MCP_COOKIE_KV_URL"))
+}
+
+// GetCookieKVToken http 后端的鉴权 token。
+func GetCookieKVToken() string {
+	return strings.TrimSpace(os.Getenv("XHS_MCP_COOKIE_KV_TOKEN"))
+}
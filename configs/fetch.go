@@ -0,0 +1,48 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IsFetchAllowPrivate 下载远程图片/视频时是否允许访问内网、回环与链路本地地址，默认禁止。
+// 仅在可信的部署环境中通过 XHS_MCP_FETCH_ALLOW_PRIVATE=true 开启。
+func IsFetchAllowPrivate() bool {
+	b, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("XHS_MCP_FETCH_ALLOW_PRIVATE")))
+	return err == nil && b
+}
+
+// GetFetchAllowHosts 下载白名单，XHS_MCP_FETCH_ALLOW_HOSTS 逗号分隔，
+// 支持域名（example.com 同时匹配子域名）与 CIDR（10.0.0.0/8，可放行指定内网段）。为空表示不限制。
+func GetFetchAllowHosts() []string {
+	return splitList(os.Getenv("XHS_MCP_FETCH_ALLOW_HOSTS"))
+}
+
+// GetFetchDenyHosts 下载黑名单，XHS_MCP_FETCH_DENY_HOSTS 逗号分隔，格式同白名单，优先于白名单。
+func GetFetchDenyHosts() []string {
+	return splitList(os.Getenv("XHS_MCP_FETCH_DENY_HOSTS"))
+}
+
+// GetFetchMaxRedirects 下载时最多跟随的重定向次数，默认 5，XHS_MCP_FETCH_MAX_REDIRECTS=0 禁止重定向。
+func GetFetchMaxRedirects() int {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_FETCH_MAX_REDIRECTS"))
+	if v == "" {
+		return 5
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 5
+	}
+	return n
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -10,8 +10,9 @@ const (
 	ImagesDir = "xiaohongshu_images"
 )
 
+// GetImagesPath 处理后图片的输出目录，位于下载缓存目录下，随缓存一起清理
 func GetImagesPath() string {
-	return filepath.Join(os.TempDir(), ImagesDir)
+	return filepath.Join(GetMediaCachePath(), ImagesDir)
 }
 
 func GetImageMaxBytes() int64 {
@@ -25,3 +26,29 @@ func GetImageMaxBytes() int64 {
 	}
 	return n
 }
+
+// GetImageMaxPixels 处理图片时允许解码的最大像素数，XHS_MCP_IMAGE_MAX_PIXELS，默认 1 亿
+func GetImageMaxPixels() int64 {
+	v := os.Getenv("XHS_MCP_IMAGE_MAX_PIXELS")
+	if v == "" {
+		return 100_000_000
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 100_000_000
+	}
+	return n
+}
+
+// GetImageFetchConcurrency 同一篇笔记的图片并发下载数，XHS_MCP_IMAGE_FETCH_CONCURRENCY，默认 4
+func GetImageFetchConcurrency() int {
+	v := os.Getenv("XHS_MCP_IMAGE_FETCH_CONCURRENCY")
+	if v == "" {
+		return 4
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 4
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaCacheDir 下载缓存在数据目录下的默认子目录名。
+const MediaCacheDir = "media_cache"
+
+// GetMediaCachePath 远程图片/视频的下载缓存目录，默认 <data_dir>/media_cache，
+// XHS_MCP_MEDIA_CACHE_DIR 可以指定其他位置（相对路径基于数据目录）。
+func GetMediaCachePath() string {
+	return MediaCachePathIn(GetDataDir())
+}
+
+// MediaCachePathIn 数据目录 dataDir 下的下载缓存目录，XHS_MCP_MEDIA_CACHE_DIR 为相对路径时基于 dataDir。
+func MediaCachePathIn(dataDir string) string {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_MEDIA_CACHE_DIR"))
+	if v == "" {
+		v = MediaCacheDir
+	}
+	if filepath.IsAbs(v) {
+		return v
+	}
+	return filepath.Join(dataDir, v)
+}
+
+// GetMediaCacheTTL 缓存文件超过该时长未被使用即过期，默认 168h。
+func GetMediaCacheTTL() time.Duration {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_MEDIA_CACHE_TTL"))
+	if v == "" {
+		return 168 * time.Hour
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 168 * time.Hour
+	}
+	return d
+}
+
+// GetMediaCacheMaxBytes 缓存总大小上限，超出后按最近使用时间淘汰，默认 2GB。
+func GetMediaCacheMaxBytes() int64 {
+	v := strings.TrimSpace(os.Getenv("XHS_MCP_MEDIA_CACHE_MAX_BYTES"))
+	if v == "" {
+		return 2 << 30
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 2 << 30
+	}
+	return n
+}
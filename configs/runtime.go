@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
 var (
@@ -66,4 +67,27 @@ func LoadRuntimeFromEnv() {
 			InitBrowserPoolSize(n)
 		}
 	}
+	if v := os.Getenv("XHS_MCP_WARM_POOL"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			InitWarmPool(b)
+		}
+	}
+	if v := os.Getenv("XHS_MCP_WARM_POOL_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			InitWarmPoolSize(n)
+		}
+	}
+	if v := os.Getenv("XHS_MCP_WARM_POOL_IDLE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			InitWarmPoolIdleTTL(d)
+		}
+	}
+	if v := os.Getenv("XHS_MCP_REMOTE_CDP"); v != "" {
+		InitRemoteCDP(v)
+	}
+	if v := os.Getenv("XHS_MCP_PERSISTENT_PROFILE"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			InitPersistentProfile(b)
+		}
+	}
 }
@@ -0,0 +1,43 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	VideosDir = "xiaohongshu_videos"
+)
+
+// GetVideosPath 远程视频的下载目录，位于下载缓存目录下，随缓存一起清理
+func GetVideosPath() string {
+	return filepath.Join(GetMediaCachePath(), VideosDir)
+}
+
+// GetVideoMaxBytes 单个视频最大字节数，XHS_MCP_VIDEO_MAX_BYTES，默认 2GB
+func GetVideoMaxBytes() int64 {
+	v := os.Getenv("XHS_MCP_VIDEO_MAX_BYTES")
+	if v == "" {
+		return 2 * 1024 * 1024 * 1024
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 2 * 1024 * 1024 * 1024
+	}
+	return n
+}
+
+// GetVideoMaxDuration 视频最长时长，XHS_MCP_VIDEO_MAX_DURATION（如 "60m"），默认 4 小时
+func GetVideoMaxDuration() time.Duration {
+	v := os.Getenv("XHS_MCP_VIDEO_MAX_DURATION")
+	if v == "" {
+		return 4 * time.Hour
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 4 * time.Hour
+	}
+	return d
+}
@@ -0,0 +1,66 @@
+package cookies
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// 存储后端名称
+const (
+	BackendFile = "file"
+	BackendBolt = "bolt"
+	BackendHTTP = "http"
+)
+
+// Backend cookies 存储后端，按 key 打开对应账号的 Cookier。
+// 文件后端的 key 为 cookies 文件路径，其它后端的 key 为账号名。
+type Backend interface {
+	Name() string
+	Open(key string) Cookier
+	// Location 返回 key 对应的存储位置描述，用于日志与接口返回
+	Location(key string) string
+}
+
+type fileBackend struct{}
+
+// NewFileBackend 本地文件后端，每个账号一个 JSON 文件。
+func NewFileBackend() Backend {
+	return fileBackend{}
+}
+
+func (fileBackend) Name() string { return BackendFile }
+
+func (fileBackend) Open(key string) Cookier { return NewLoadCookie(key) }
+
+func (fileBackend) Location(key string) string { return key }
+
+// BackendConfig 存储后端配置。
+type BackendConfig struct {
+	Name         string
+	BoltPath     string
+	BoltVersions int
+	KVURL        string
+	KVToken      string
+}
+
+// OpenBackend 按配置创建存储后端，Name 为空时使用文件后端。
+func OpenBackend(cfg BackendConfig) (Backend, error) {
+	switch cfg.Name {
+	case "", BackendFile:
+		return NewFileBackend(), nil
+	case BackendBolt:
+		if cfg.BoltPath == "" {
+			return nil, errors.New("bolt backend requires a db path")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.BoltPath), 0755); err != nil {
+			return nil, err
+		}
+		return NewBoltBackend(cfg.BoltPath, cfg.BoltVersions)
+	case BackendHTTP:
+		return NewHTTPBackend(cfg.KVURL, cfg.KVToken)
+	default:
+		return nil, errors.Errorf("unknown cookies backend: %s", cfg.Name)
+	}
+}
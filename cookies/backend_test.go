@@ -0,0 +1,151 @@
+package cookies
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltBackend_SaveLoadAndHistory(t *testing.T) {
+	b, err := NewBoltBackend(filepath.Join(t.TempDir(), "cookies.db"), 2)
+	require.NoError(t, err)
+	defer b.Close()
+
+	c := b.Open("u1")
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	require.Len(t, data, 0)
+
+	require.NoError(t, c.SaveCookies([]byte("v1")))
+	require.NoError(t, c.SaveCookies([]byte("v2")))
+	require.NoError(t, c.SaveCookies([]byte("v3")))
+
+	data, err = c.LoadCookies()
+	require.NoError(t, err)
+	require.Equal(t, "v3", string(data))
+
+	versions, err := b.Versions("u1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	old, err := b.LoadVersion("u1", versions[0].Seq)
+	require.NoError(t, err)
+	require.Equal(t, "v2", string(old))
+
+	// 其它账号互不影响
+	other, err := b.Open("u2").LoadCookies()
+	require.NoError(t, err)
+	require.Len(t, other, 0)
+
+	require.NoError(t, c.DeleteCookies())
+	data, err = c.LoadCookies()
+	require.NoError(t, err)
+	require.Len(t, data, 0)
+	old, err = b.LoadVersion("u1", versions[1].Seq)
+	require.NoError(t, err)
+	require.Equal(t, "v3", string(old))
+}
+
+func TestBoltBackend_SharedAcrossOpeners(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.db")
+	server, err := NewBoltBackend(path, 3)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// 服务运行期间，cmd/login 等其他进程可以打开同一个数据库写入
+	login, err := NewBoltBackend(path, 3)
+	require.NoError(t, err)
+	require.NoError(t, login.Open("u1").SaveCookies([]byte("from-login")))
+
+	data, err := server.Open("u1").LoadCookies()
+	require.NoError(t, err)
+	require.Equal(t, "from-login", string(data))
+}
+
+func TestBoltBackend_TrimsAllExcessVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.db")
+	b, err := NewBoltBackend(path, 5)
+	require.NoError(t, err)
+	for _, v := range []string{"v1", "v2", "v3", "v4", "v5"} {
+		require.NoError(t, b.Open("u1").SaveCookies([]byte(v)))
+	}
+
+	// 保留数量调小后，下一次写入要删掉全部超出的旧版本
+	small, err := NewBoltBackend(path, 2)
+	require.NoError(t, err)
+	require.NoError(t, small.Open("u1").SaveCookies([]byte("v6")))
+
+	versions, err := small.Versions("u1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	old, err := small.LoadVersion("u1", versions[0].Seq)
+	require.NoError(t, err)
+	require.Equal(t, "v5", string(old))
+}
+
+func TestHTTPBackend_RoundTrip(t *testing.T) {
+	var mu sync.Mutex
+	kv := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/kv/")
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			v, ok := kv[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, v)
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			kv[key] = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			if _, ok := kv[key]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(kv, key)
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewHTTPBackend(srv.URL+"/kv/", "secret")
+	require.NoError(t, err)
+
+	c := b.Open("user a")
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	require.Len(t, data, 0)
+
+	require.NoError(t, c.SaveCookies([]byte(`[{"name":"web_session"}]`)))
+	data, err = c.LoadCookies()
+	require.NoError(t, err)
+	require.Equal(t, `[{"name":"web_session"}]`, string(data))
+	require.Contains(t, kv, "user a")
+
+	require.NoError(t, c.DeleteCookies())
+	require.NoError(t, c.DeleteCookies())
+	require.Empty(t, kv)
+
+	bad, err := NewHTTPBackend(srv.URL+"/kv", "wrong")
+	require.NoError(t, err)
+	_, err = bad.Open("x").LoadCookies()
+	require.Error(t, err)
+}
+
+func TestNewHTTPBackend_InvalidURL(t *testing.T) {
+	_, err := NewHTTPBackend("ftp://example.com", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,219 @@
+package cookies
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltRootBucket = []byte("cookies")
+
+// Version cookies 的一个历史版本，Data 为空表示该版本是删除记录。
+type Version struct {
+	Seq     uint64    `json:"seq"`
+	SavedAt time.Time `json:"saved_at"`
+	Data    []byte    `json:"data,omitempty"`
+}
+
+// boltLockTimeout 等待其他进程释放数据库文件锁的最长时间
+const boltLockTimeout = 10 * time.Second
+
+// BoltBackend 基于 bbolt 的嵌入式后端，所有账号保存在同一个数据库文件中，
+// 每个账号一个 bucket，按递增序号保留最近 maxVersions 个版本。
+// bbolt 打开期间独占文件锁，因此每次操作单独打开、用完即关，
+// 服务运行时 cmd/login 等其他进程也能读写同一个数据库。
+type BoltBackend struct {
+	path        string
+	maxVersions int
+}
+
+// NewBoltBackend 打开（不存在则创建）bbolt 数据库。
+func NewBoltBackend(path string, maxVersions int) (*BoltBackend, error) {
+	if maxVersions < 1 {
+		maxVersions = 1
+	}
+	b := &BoltBackend{path: path, maxVersions: maxVersions}
+	if err := b.update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltRootBucket)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to init cookies db")
+	}
+	return b, nil
+}
+
+// open 打开数据库，只读时使用共享锁，多个读操作可以并行
+func (b *BoltBackend) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(b.path, 0600, &bolt.Options{Timeout: boltLockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open cookies db %s", b.path)
+	}
+	return db, nil
+}
+
+func (b *BoltBackend) view(fn func(tx *bolt.Tx) error) error {
+	db, err := b.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (b *BoltBackend) update(fn func(tx *bolt.Tx) error) error {
+	db, err := b.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+func (b *BoltBackend) Name() string { return BackendBolt }
+
+func (b *BoltBackend) Open(key string) Cookier {
+	return &boltCookie{backend: b, key: key}
+}
+
+func (b *BoltBackend) Location(key string) string {
+	return b.path + "#" + key
+}
+
+// Close 数据库按操作打开和关闭，这里无需释放资源。
+func (b *BoltBackend) Close() error {
+	return nil
+}
+
+// Versions 返回 key 的全部历史版本（按时间升序，不含数据内容）。
+func (b *BoltBackend) Versions(key string) ([]Version, error) {
+	var out []Version
+	err := b.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(boltRootBucket).Bucket([]byte(key))
+		if bk == nil {
+			return nil
+		}
+		return bk.ForEach(func(k, v []byte) error {
+			var ver Version
+			if err := json.Unmarshal(v, &ver); err != nil {
+				return err
+			}
+			ver.Data = nil
+			out = append(out, ver)
+			return nil
+		})
+	})
+	return out, err
+}
+
+// LoadVersion 读取 key 的指定历史版本。
+func (b *BoltBackend) LoadVersion(key string, seq uint64) ([]byte, error) {
+	var data []byte
+	err := b.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(boltRootBucket).Bucket([]byte(key))
+		if bk == nil {
+			return errors.Errorf("no cookies for %s", key)
+		}
+		v := bk.Get(seqKey(seq))
+		if v == nil {
+			return errors.Errorf("version %d not found for %s", seq, key)
+		}
+		var ver Version
+		if err := json.Unmarshal(v, &ver); err != nil {
+			return err
+		}
+		data = ver.Data
+		return nil
+	})
+	return data, err
+}
+
+func (b *BoltBackend) latest(key string) ([]byte, error) {
+	var data []byte
+	err := b.view(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(boltRootBucket).Bucket([]byte(key))
+		if bk == nil {
+			return nil
+		}
+		_, v := bk.Cursor().Last()
+		if v == nil {
+			return nil
+		}
+		var ver Version
+		if err := json.Unmarshal(v, &ver); err != nil {
+			return err
+		}
+		data = ver.Data
+		return nil
+	})
+	return data, err
+}
+
+func (b *BoltBackend) append(key string, data []byte) error {
+	return b.update(func(tx *bolt.Tx) error {
+		bk, err := tx.Bucket(boltRootBucket).CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+		seq, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(Version{Seq: seq, SavedAt: time.Now(), Data: data})
+		if err != nil {
+			return err
+		}
+		if err := bk.Put(seqKey(seq), v); err != nil {
+			return err
+		}
+
+		// 超出保留数量时从最旧的版本开始删除。游标删除后 Next 会跳过一个 key，
+		// 所以先收集需要删除的 key，遍历结束后再删除
+		var keys [][]byte
+		c := bk.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for i := 0; i < len(keys)-b.maxVersions; i++ {
+			if err := bk.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func seqKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
+
+type boltCookie struct {
+	backend *BoltBackend
+	key     string
+}
+
+// LoadCookies 读取最新版本，删除记录视为不存在。
+func (c *boltCookie) LoadCookies() ([]byte, error) {
+	return c.backend.latest(c.key)
+}
+
+// SaveCookies 追加一个新版本。
+func (c *boltCookie) SaveCookies(data []byte) error {
+	return c.backend.append(c.key, data)
+}
+
+// DeleteCookies 追加一条删除记录，历史版本仍可通过 LoadVersion 找回。
+func (c *boltCookie) DeleteCookies() error {
+	data, err := c.backend.latest(c.key)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return c.backend.append(c.key, nil)
+}
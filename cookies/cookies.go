@@ -41,11 +41,18 @@ func (c *localCookie) LoadCookies() ([]byte, error) {
 }
 
 // SaveCookies 保存 cookies 到文件中。
+// 先写临时文件再 rename 保证原子性，已有文件会先备份为 <path>.bak。
 func (c *localCookie) SaveCookies(data []byte) error {
 	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
 		return err
 	}
-	return os.WriteFile(c.path, data, 0644)
+
+	if old, err := os.ReadFile(c.path); err == nil && len(old) > 0 {
+		if err := writeFileAtomic(BackupPath(c.path), old); err != nil {
+			return errors.Wrap(err, "failed to backup cookies")
+		}
+	}
+	return writeFileAtomic(c.path, data)
 }
 
 // DeleteCookies 删除 cookies 文件。
@@ -57,6 +64,36 @@ func (c *localCookie) DeleteCookies() error {
 	return os.Remove(c.path)
 }
 
+// BackupPath 返回 cookies 文件上一版本的备份路径。
+func BackupPath(path string) string {
+	return path + ".bak"
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
 // GetCookiesFilePath 获取 cookies 文件路径。
 // 为了向后兼容，如果旧路径 /tmp/cookies.json 存在，则继续使用；
 // 否则使用当前目录下的 cookies.json
@@ -23,3 +23,22 @@ func TestLocalCookie_SaveCookies_CreatesParentDir(t *testing.T) {
 	_, err := os.Stat(path)
 	require.NoError(t, err)
 }
+
+func TestLocalCookie_SaveCookies_KeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	c := NewLoadCookie(path)
+	require.NoError(t, c.SaveCookies([]byte(`[{"name":"a"}]`)))
+	require.NoError(t, c.SaveCookies([]byte(`[{"name":"b"}]`)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `[{"name":"b"}]`, string(data))
+
+	bak, err := os.ReadFile(BackupPath(path))
+	require.NoError(t, err)
+	require.Equal(t, `[{"name":"a"}]`, string(bak))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
@@ -0,0 +1,121 @@
+package cookies
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPBackend 远程 KV 后端，便于多台机器共享同一批账号的 cookies。
+// 协议约定：
+//   - GET    {baseURL}/{key}  200 返回内容，404 表示不存在
+//   - PUT    {baseURL}/{key}  请求体为 cookies 内容
+//   - DELETE {baseURL}/{key}  404 视为已删除
+//
+// token 非空时以 Authorization: Bearer <token> 传递。
+type HTTPBackend struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPBackend 创建远程 KV 后端。
+func NewHTTPBackend(baseURL, token string) (*HTTPBackend, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("invalid cookies kv url: %q", baseURL)
+	}
+	return &HTTPBackend{
+		baseURL: baseURL,
+		token:   token,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (b *HTTPBackend) Name() string { return BackendHTTP }
+
+func (b *HTTPBackend) Open(key string) Cookier {
+	return &httpCookie{backend: b, key: key}
+}
+
+func (b *HTTPBackend) Location(key string) string {
+	return b.keyURL(key)
+}
+
+func (b *HTTPBackend) keyURL(key string) string {
+	return b.baseURL + "/" + url.PathEscape(key)
+}
+
+func (b *HTTPBackend) do(method, key string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, b.keyURL(key), r)
+	if err != nil {
+		return nil, err
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return b.client.Do(req)
+}
+
+type httpCookie struct {
+	backend *HTTPBackend
+	key     string
+}
+
+// LoadCookies 从远程 KV 读取，不存在时返回空。
+func (c *httpCookie) LoadCookies() ([]byte, error) {
+	resp, err := c.backend.do(http.MethodGet, c.key, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load cookies from kv")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("load cookies from kv: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// SaveCookies 写入远程 KV。
+func (c *httpCookie) SaveCookies(data []byte) error {
+	resp, err := c.backend.do(http.MethodPut, c.key, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to save cookies to kv")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("save cookies to kv: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeleteCookies 删除远程 KV 中的记录。
+func (c *httpCookie) DeleteCookies() error {
+	resp, err := c.backend.do(http.MethodDelete, c.key, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete cookies from kv")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return errors.Errorf("delete cookies from kv: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
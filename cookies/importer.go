@@ -0,0 +1,297 @@
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// 导入格式
+const (
+	FormatAuto     = "auto"
+	FormatNetscape = "netscape"
+	FormatJSON     = "json"
+	FormatHeader   = "header"
+)
+
+// RequiredDomains 导入的 cookies 必须覆盖的域名。
+var RequiredDomains = []string{".xiaohongshu.com"}
+
+// ImportResult 导入解析结果。
+type ImportResult struct {
+	Format   string                 `json:"format"`
+	Cookies  []*proto.NetworkCookie `json:"-"`
+	Count    int                    `json:"count"`
+	Dropped  int                    `json:"dropped"`
+	Warnings []string               `json:"warnings,omitempty"`
+}
+
+// ParseImport 解析浏览器导出的 cookies，转换为 proto.NetworkCookie。
+// 只保留小红书相关域名的 cookie；缺少 RequiredDomains 时返回错误。
+func ParseImport(data []byte, format string) (*ImportResult, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) == 0 {
+		return nil, errors.New("cookies 内容为空")
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == FormatAuto {
+		format = detectFormat(data)
+	}
+
+	var (
+		cks []*proto.NetworkCookie
+		err error
+	)
+	switch format {
+	case FormatNetscape:
+		cks, err = parseNetscape(data)
+	case FormatJSON:
+		cks, err = parseJSONExport(data)
+	case FormatHeader:
+		cks, err = parseCookieHeader(string(data), RequiredDomains[0])
+	default:
+		return nil, errors.Errorf("不支持的 cookies 格式: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ImportResult{Format: format}
+	for _, c := range cks {
+		if !isXHSDomain(c.Domain) {
+			res.Dropped++
+			continue
+		}
+		res.Cookies = append(res.Cookies, c)
+	}
+	res.Count = len(res.Cookies)
+
+	if err := ValidateDomains(res.Cookies, RequiredDomains); err != nil {
+		return nil, err
+	}
+	if !HasLoginSession(res.Cookies, time.Now()) {
+		res.Warnings = append(res.Warnings, "未找到有效的 web_session，导入后账号可能仍处于未登录状态")
+	}
+	return res, nil
+}
+
+// ValidateDomains 校验每个必需域名下至少有一个 cookie。
+func ValidateDomains(cks []*proto.NetworkCookie, required []string) error {
+	var missing []string
+	for _, d := range required {
+		found := false
+		for _, c := range cks {
+			if domainMatches(c.Domain, d) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, d)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("缺少必需域名的 cookies: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func detectFormat(data []byte) string {
+	switch {
+	case data[0] == '[' || data[0] == '{':
+		return FormatJSON
+	case bytes.Contains(data, []byte("\t")):
+		return FormatNetscape
+	default:
+		return FormatHeader
+	}
+}
+
+// parseNetscape 解析 Netscape cookies.txt：
+// domain \t includeSubdomains \t path \t secure \t expiry \t name \t value
+func parseNetscape(data []byte) ([]*proto.NetworkCookie, error) {
+	var out []*proto.NetworkCookie
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(text, "#HttpOnly_") {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 7 {
+			return nil, errors.Errorf("cookies.txt 第 %d 行格式错误", line)
+		}
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, errors.Errorf("cookies.txt 第 %d 行过期时间错误: %q", line, fields[4])
+		}
+		c := &proto.NetworkCookie{
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			Domain:   netscapeDomain(fields[0], fields[1]),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HTTPOnly: httpOnly,
+			Expires:  proto.TimeSinceEpoch(expires),
+		}
+		if expires <= 0 {
+			c.Session = true
+			c.Expires = -1
+		}
+		out = append(out, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "读取 cookies.txt 失败")
+	}
+	return out, nil
+}
+
+// netscapeDomain 按 includeSubdomains 列还原域名：TRUE 对子域名生效，以前导点表示；
+// FALSE 为 host-only，去掉前导点
+func netscapeDomain(domain, includeSubdomains string) string {
+	domain = strings.TrimPrefix(domain, ".")
+	if domain != "" && strings.EqualFold(includeSubdomains, "TRUE") {
+		return "." + domain
+	}
+	return domain
+}
+
+// exportedCookie 兼容 EditThisCookie / Cookie-Editor 导出字段，以及本项目保存的 NetworkCookie。
+type exportedCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	HostOnly       *bool    `json:"hostOnly"`
+	Path           string   `json:"path"`
+	Secure         bool     `json:"secure"`
+	HTTPOnly       bool     `json:"httpOnly"`
+	SameSite       string   `json:"sameSite"`
+	Session        bool     `json:"session"`
+	ExpirationDate *float64 `json:"expirationDate"`
+	Expires        *float64 `json:"expires"`
+}
+
+func parseJSONExport(data []byte) ([]*proto.NetworkCookie, error) {
+	var list []exportedCookie
+	if data[0] == '{' {
+		// 部分导出工具会包一层 {"cookies": [...]}
+		var wrapped struct {
+			Cookies []exportedCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, errors.Wrap(err, "解析 cookies JSON 失败")
+		}
+		list = wrapped.Cookies
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, errors.Wrap(err, "解析 cookies JSON 失败")
+	}
+
+	out := make([]*proto.NetworkCookie, 0, len(list))
+	for _, e := range list {
+		if e.Name == "" {
+			continue
+		}
+		c := &proto.NetworkCookie{
+			Name:     e.Name,
+			Value:    e.Value,
+			Domain:   e.Domain,
+			Path:     e.Path,
+			Secure:   e.Secure,
+			HTTPOnly: e.HTTPOnly,
+			SameSite: convertSameSite(e.SameSite),
+			Session:  e.Session,
+			Expires:  -1,
+		}
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		// hostOnly=false 表示对子域名生效，CDP 中以前导点表示；
+		// 本项目导出的 NetworkCookie 没有 hostOnly 字段，域名保持原样
+		if e.HostOnly != nil && !*e.HostOnly && c.Domain != "" && !strings.HasPrefix(c.Domain, ".") {
+			c.Domain = "." + c.Domain
+		}
+		switch {
+		case e.ExpirationDate != nil:
+			c.Expires = proto.TimeSinceEpoch(*e.ExpirationDate)
+		case e.Expires != nil:
+			c.Expires = proto.TimeSinceEpoch(*e.Expires)
+		}
+		if c.Expires <= 0 {
+			c.Session = true
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func convertSameSite(v string) proto.NetworkCookieSameSite {
+	switch strings.ToLower(v) {
+	case "strict":
+		return proto.NetworkCookieSameSiteStrict
+	case "lax":
+		return proto.NetworkCookieSameSiteLax
+	case "none", "no_restriction":
+		return proto.NetworkCookieSameSiteNone
+	default:
+		return ""
+	}
+}
+
+// parseCookieHeader 解析 "Cookie: a=b; c=d"，header 中没有域名信息，统一挂在 domain 下。
+func parseCookieHeader(header, domain string) ([]*proto.NetworkCookie, error) {
+	header = strings.TrimSpace(header)
+	if i := strings.Index(header, ":"); i >= 0 && strings.EqualFold(strings.TrimSpace(header[:i]), "cookie") {
+		header = header[i+1:]
+	}
+
+	var out []*proto.NetworkCookie
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, errors.Errorf("Cookie 头格式错误: %q", part)
+		}
+		out = append(out, &proto.NetworkCookie{
+			Name:    strings.TrimSpace(name),
+			Value:   strings.TrimSpace(value),
+			Domain:  domain,
+			Path:    "/",
+			Secure:  true,
+			Session: true,
+			Expires: -1,
+		})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("Cookie 头中没有任何 cookie")
+	}
+	return out, nil
+}
+
+func isXHSDomain(domain string) bool {
+	return domainMatches(domain, ".xiaohongshu.com")
+}
+
+// domainMatches 判断 cookie 域名是否属于 required（含子域名）。
+func domainMatches(domain, required string) bool {
+	d := strings.ToLower(strings.TrimPrefix(domain, "."))
+	r := strings.ToLower(strings.TrimPrefix(required, "."))
+	return d == r || strings.HasSuffix(d, "."+r)
+}
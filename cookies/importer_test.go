@@ -0,0 +1,74 @@
+package cookies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImport_Netscape(t *testing.T) {
+	data := "# Netscape HTTP Cookie File\n" +
+		"#HttpOnly_.xiaohongshu.com\tTRUE\t/\tTRUE\t1999999999\tweb_session\tabc\n" +
+		".xiaohongshu.com\tTRUE\t/\tFALSE\t0\ta1\tx\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tother\ty\n"
+
+	res, err := ParseImport([]byte(data), FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatNetscape, res.Format)
+	require.Equal(t, 2, res.Count)
+	require.Equal(t, 1, res.Dropped)
+	require.Empty(t, res.Warnings)
+	require.True(t, res.Cookies[0].HTTPOnly)
+	require.True(t, res.Cookies[1].Session)
+}
+
+func TestParseImport_EditThisCookieJSON(t *testing.T) {
+	data := `[
+	  {"domain":"xiaohongshu.com","hostOnly":false,"name":"web_session","value":"abc","path":"/","sameSite":"no_restriction","secure":true,"expirationDate":1999999999.5},
+	  {"domain":"www.xiaohongshu.com","hostOnly":true,"name":"xsecappid","value":"xhs-pc-web","session":true}
+	]`
+
+	res, err := ParseImport([]byte(data), FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, res.Format)
+	require.Equal(t, 2, res.Count)
+	require.Equal(t, ".xiaohongshu.com", res.Cookies[0].Domain)
+	require.EqualValues(t, "None", res.Cookies[0].SameSite)
+	require.Equal(t, "www.xiaohongshu.com", res.Cookies[1].Domain)
+	require.Equal(t, "/", res.Cookies[1].Path)
+}
+
+func TestParseImport_KeepsHostOnlyDomains(t *testing.T) {
+	// 本项目保存的 NetworkCookie 没有 hostOnly 字段，重新导入时域名不变
+	res, err := ParseImport([]byte(`[
+	  {"name":"web_session","value":"abc","domain":".xiaohongshu.com","path":"/","expires":1999999999},
+	  {"name":"xsecappid","value":"x","domain":"www.xiaohongshu.com","path":"/","expires":-1,"session":true}
+	]`), FormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, ".xiaohongshu.com", res.Cookies[0].Domain)
+	require.Equal(t, "www.xiaohongshu.com", res.Cookies[1].Domain)
+
+	// cookies.txt 按 includeSubdomains 列区分
+	res, err = ParseImport([]byte(
+		"xiaohongshu.com\tTRUE\t/\tTRUE\t1999999999\tweb_session\tabc\n"+
+			".www.xiaohongshu.com\tFALSE\t/\tFALSE\t0\txsecappid\tx\n"), FormatNetscape)
+	require.NoError(t, err)
+	require.Equal(t, ".xiaohongshu.com", res.Cookies[0].Domain)
+	require.Equal(t, "www.xiaohongshu.com", res.Cookies[1].Domain)
+}
+
+func TestParseImport_Header(t *testing.T) {
+	res, err := ParseImport([]byte("Cookie: a1=x; webId=y"), FormatAuto)
+	require.NoError(t, err)
+	require.Equal(t, FormatHeader, res.Format)
+	require.Equal(t, 2, res.Count)
+	require.Equal(t, ".xiaohongshu.com", res.Cookies[0].Domain)
+	require.Len(t, res.Warnings, 1)
+}
+
+func TestParseImport_MissingRequiredDomain(t *testing.T) {
+	data := `[{"domain":".example.com","name":"web_session","value":"abc"}]`
+	_, err := ParseImport([]byte(data), FormatJSON)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ".xiaohongshu.com")
+}
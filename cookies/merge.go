@@ -0,0 +1,116 @@
+package cookies
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// SessionCookieName 小红书登录态 cookie 名称，缺失即视为未登录。
+const SessionCookieName = "web_session"
+
+// ErrNoLoginSession 浏览器 cookies 中没有有效的登录态，拒绝写回。
+var ErrNoLoginSession = errors.New("cookies 中缺少有效的登录态（web_session）")
+
+// HasLoginSession 判断 cookies 中是否包含未过期的登录态 cookie。
+func HasLoginSession(cks []*proto.NetworkCookie, now time.Time) bool {
+	for _, c := range cks {
+		if c == nil || c.Name != SessionCookieName || c.Value == "" {
+			continue
+		}
+		if isExpired(c, now) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// SessionValue 返回未过期的登录态 cookie 的值，没有时返回空字符串。
+func SessionValue(cks []*proto.NetworkCookie, now time.Time) string {
+	for _, c := range cks {
+		if c == nil || c.Name != SessionCookieName || c.Value == "" || isExpired(c, now) {
+			continue
+		}
+		return c.Value
+	}
+	return ""
+}
+
+// MergeCookies 以 fresh 覆盖 base 中 name+domain+path 相同的 cookie，
+// 保留 base 中浏览器未返回的条目，并剔除已过期的 cookie。
+func MergeCookies(base, fresh []*proto.NetworkCookie, now time.Time) []*proto.NetworkCookie {
+	type key struct{ name, domain, path string }
+
+	merged := make([]*proto.NetworkCookie, 0, len(base)+len(fresh))
+	index := make(map[key]int, len(base)+len(fresh))
+	put := func(c *proto.NetworkCookie) {
+		if c == nil || c.Name == "" {
+			return
+		}
+		k := key{c.Name, c.Domain, c.Path}
+		if i, ok := index[k]; ok {
+			merged[i] = c
+			return
+		}
+		index[k] = len(merged)
+		merged = append(merged, c)
+	}
+	for _, c := range base {
+		put(c)
+	}
+	for _, c := range fresh {
+		put(c)
+	}
+
+	out := merged[:0]
+	for _, c := range merged {
+		if isExpired(c, now) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// SyncCookies 将浏览器会话中的最新 cookies 合并写回 c。
+// fresh 不含登录态时返回 ErrNoLoginSession，避免用登出状态覆盖原有 cookies；
+// 合并结果与已有内容一致时不写入。
+func SyncCookies(c Cookier, fresh []*proto.NetworkCookie) error {
+	now := time.Now()
+	if !HasLoginSession(fresh, now) {
+		return ErrNoLoginSession
+	}
+
+	old, err := c.LoadCookies()
+	if err != nil {
+		return err
+	}
+	var base []*proto.NetworkCookie
+	if len(bytes.TrimSpace(old)) > 0 {
+		if err := json.Unmarshal(old, &base); err != nil {
+			// 旧文件损坏时直接以浏览器 cookies 为准
+			base = nil
+		}
+	}
+
+	data, err := json.Marshal(MergeCookies(base, fresh, now))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cookies")
+	}
+	if bytes.Equal(data, old) {
+		return nil
+	}
+	return c.SaveCookies(data)
+}
+
+func isExpired(c *proto.NetworkCookie, now time.Time) bool {
+	// 会话 cookie 的 expires 为 -1 或 0
+	if c.Session || c.Expires <= 0 {
+		return false
+	}
+	return float64(c.Expires) < float64(now.Unix())
+}
@@ -0,0 +1,63 @@
+package cookies
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeCookies_FreshOverridesAndDropsExpired(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	base := []*proto.NetworkCookie{
+		{Name: "a1", Value: "old", Domain: ".xiaohongshu.com", Path: "/"},
+		{Name: "keep", Value: "v", Domain: ".xiaohongshu.com", Path: "/"},
+		{Name: "gone", Value: "v", Domain: ".xiaohongshu.com", Path: "/", Expires: proto.TimeSinceEpoch(now.Unix() - 10)},
+	}
+	fresh := []*proto.NetworkCookie{
+		{Name: "a1", Value: "new", Domain: ".xiaohongshu.com", Path: "/"},
+		{Name: "a1", Value: "other-domain", Domain: "creator.xiaohongshu.com", Path: "/"},
+	}
+
+	merged := MergeCookies(base, fresh, now)
+	require.Len(t, merged, 3)
+	require.Equal(t, "new", merged[0].Value)
+	require.Equal(t, "keep", merged[1].Name)
+	require.Equal(t, "other-domain", merged[2].Value)
+}
+
+func TestSyncCookies_RefusesLoggedOutSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	c := NewLoadCookie(path)
+	require.NoError(t, c.SaveCookies([]byte(`[{"name":"web_session","value":"s"}]`)))
+
+	err := SyncCookies(c, []*proto.NetworkCookie{{Name: "a1", Value: "x"}})
+	require.ErrorIs(t, err, ErrNoLoginSession)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `[{"name":"web_session","value":"s"}]`, string(data))
+}
+
+func TestSyncCookies_MergesIntoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	c := NewLoadCookie(path)
+	require.NoError(t, c.SaveCookies([]byte(`[{"name":"a1","value":"x","domain":".xiaohongshu.com","path":"/"}]`)))
+
+	fresh := []*proto.NetworkCookie{{Name: SessionCookieName, Value: "s2", Domain: ".xiaohongshu.com", Path: "/", Expires: -1}}
+	require.NoError(t, SyncCookies(c, fresh))
+
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	var got []*proto.NetworkCookie
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got, 2)
+	require.Equal(t, SessionCookieName, got[1].Name)
+
+	_, err = os.Stat(BackupPath(path))
+	require.NoError(t, err)
+}
@@ -4,3 +4,4 @@ import "errors"
 
 var ErrNoFeeds = errors.New("没有捕获到 feeds 数据")
 var ErrNoFeedDetail = errors.New("没有捕获到 feed 详情数据")
+var ErrFeedsCursorExpired = errors.New("游标已过期，请不带 cursor 重新获取")
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/artifacts"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +27,7 @@ func respondError(c *gin.Context, statusCode int, code, message string, details
 	logrus.Errorf("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
 		c.GetString("account"), statusCode)
 
+	setArtifactHeader(c)
 	c.JSON(statusCode, response)
 }
 
@@ -35,6 +42,7 @@ func respondSuccess(c *gin.Context, data any, message string) {
 	logrus.Infof("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
 		c.GetString("account"), http.StatusOK)
 
+	setArtifactHeader(c)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -120,10 +128,30 @@ func (s *AppServer) publishVideoHandler(c *gin.Context) {
 	respondSuccess(c, result, "视频发布成功")
 }
 
-// listFeedsHandler 获取Feeds列表
+// listFeedsHandler 获取Feeds列表，支持 channel/count/cursor/scroll_speed 查询参数
 func (s *AppServer) listFeedsHandler(c *gin.Context) {
+	opts := xiaohongshu.FeedsListOptions{
+		Channel:     c.Query("channel"),
+		Cursor:      c.Query("cursor"),
+		ScrollSpeed: c.Query("scroll_speed"),
+	}
+	if _, err := xiaohongshu.ResolveFeedChannel(opts.Channel); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_CHANNEL",
+			"频道参数错误", err.Error())
+		return
+	}
+	if v := c.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_COUNT",
+				"count 参数错误", "count must be a non-negative integer")
+			return
+		}
+		opts.Count = n
+	}
+
 	// 获取 Feeds 列表
-	result, err := s.xiaohongshuService.ListFeeds(c.Request.Context())
+	result, err := s.xiaohongshuService.ListFeeds(c.Request.Context(), opts)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "LIST_FEEDS_FAILED",
 			"获取Feeds列表失败", err.Error())
@@ -239,7 +267,7 @@ func (s *AppServer) postCommentHandler(c *gin.Context) {
 	}
 
 	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content)
+	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content, req.Mentions)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "POST_COMMENT_FAILED",
 			"发表评论失败", err.Error())
@@ -259,7 +287,7 @@ func (s *AppServer) replyCommentHandler(c *gin.Context) {
 		return
 	}
 
-	result, err := s.xiaohongshuService.ReplyCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.CommentID, req.UserID, req.Content)
+	result, err := s.xiaohongshuService.ReplyCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.CommentID, req.UserID, req.Content, req.Mentions)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "REPLY_COMMENT_FAILED",
 			"回复评论失败", err.Error())
@@ -312,3 +340,348 @@ func (s *AppServer) getBatchTaskStatusHandler(c *gin.Context) {
 	c.Set("account", "ai-report")
 	respondSuccess(c, status, "获取任务状态成功")
 }
+
+// browserPoolStatsHandler 常驻浏览器池指标
+func (s *AppServer) browserPoolStatsHandler(c *gin.Context) {
+	if s.runtime == nil || s.runtime.BrowserPool == nil {
+		respondSuccess(c, map[string]any{"enabled": false}, "常驻浏览器池未启用")
+		return
+	}
+	stats := s.runtime.BrowserPool.Stats()
+	hitRate := 0.0
+	if total := stats.Hits + stats.Misses; total > 0 {
+		hitRate = float64(stats.Hits) / float64(total)
+	}
+	respondSuccess(c, map[string]any{
+		"enabled":  true,
+		"stats":    stats,
+		"hit_rate": hitRate,
+	}, "获取常驻浏览器池指标成功")
+}
+
+// listArtifactsHandler 列出快照，支持按 account/action 过滤，only_trace=true 只返回网络追踪
+func (s *AppServer) listArtifactsHandler(c *gin.Context) {
+	if s.runtime == nil || s.runtime.Artifacts == nil {
+		respondError(c, http.StatusNotFound, "ARTIFACTS_DISABLED", "现场快照未启用", nil)
+		return
+	}
+	list, err := s.runtime.Artifacts.List()
+	if err != nil {
+		respondArtifactError(c, err)
+		return
+	}
+
+	account, action := c.Query("account"), c.Query("action")
+	onlyTrace := isTruthy(c.Query("only_trace"))
+	limit := 50
+	if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	out := make([]artifacts.Meta, 0, len(list))
+	for _, m := range list {
+		if (account != "" && m.Account != account) || (action != "" && m.Action != action) || (onlyTrace && !m.Trace) {
+			continue
+		}
+		out = append(out, m)
+		if len(out) >= limit {
+			break
+		}
+	}
+	respondSuccess(c, map[string]any{"artifacts": out, "count": len(out)}, "获取快照列表成功")
+}
+
+// getArtifactHandler 获取快照的元信息
+func (s *AppServer) getArtifactHandler(c *gin.Context) {
+	if s.runtime == nil || s.runtime.Artifacts == nil {
+		respondError(c, http.StatusNotFound, "ARTIFACTS_DISABLED", "现场快照未启用", nil)
+		return
+	}
+	meta, err := s.runtime.Artifacts.Get(c.Param("id"))
+	if err != nil {
+		respondArtifactError(c, err)
+		return
+	}
+	respondSuccess(c, meta, "获取现场快照成功")
+}
+
+// getArtifactFileHandler 下载快照中的文件（截图、HTML、trace.har 等）
+func (s *AppServer) getArtifactFileHandler(c *gin.Context) {
+	if s.runtime == nil || s.runtime.Artifacts == nil {
+		respondError(c, http.StatusNotFound, "ARTIFACTS_DISABLED", "现场快照未启用", nil)
+		return
+	}
+	path, err := s.runtime.Artifacts.FilePath(c.Param("id"), c.Param("file"))
+	if err != nil {
+		respondArtifactError(c, err)
+		return
+	}
+	c.File(path)
+}
+
+// uploadMediaHandler 上传图片或视频（multipart/form-data，可包含多个文件），
+// 返回的 media://<sha256> 句柄可用于发布与批量发布请求的 images/video/cover 字段
+func (s *AppServer) uploadMediaHandler(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "请使用 multipart/form-data 上传文件", err.Error())
+		return
+	}
+
+	cache := s.mediaCache()
+	files := make([]map[string]any, 0)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "读取上传内容失败", err.Error())
+			return
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		media, err := cache.Store(part)
+		part.Close()
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "UPLOAD_FAILED", "保存上传文件失败",
+				map[string]any{"filename": part.FileName(), "error": err.Error()})
+			return
+		}
+		logrus.Infof("已保存上传文件: %s -> %s (%s %d bytes)", part.FileName(), media.Handle, media.MimeType, media.Size)
+		files = append(files, map[string]any{
+			"filename":  part.FileName(),
+			"handle":    media.Handle,
+			"sha256":    media.SHA256,
+			"size":      media.Size,
+			"kind":      media.Kind,
+			"mime_type": media.MimeType,
+		})
+	}
+	if len(files) == 0 {
+		respondError(c, http.StatusBadRequest, "MISSING_FILE", "未找到上传的文件", nil)
+		return
+	}
+	respondSuccess(c, map[string]any{"files": files, "count": len(files)}, "上传成功")
+}
+
+// mediaCacheHandler 下载缓存概况与最近使用的条目
+func (s *AppServer) mediaCacheHandler(c *gin.Context) {
+	cache := s.mediaCache()
+	stats, err := cache.Stats()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "MEDIA_CACHE_READ_FAILED", "读取下载缓存失败", err.Error())
+		return
+	}
+	list, err := cache.List()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "MEDIA_CACHE_READ_FAILED", "读取下载缓存失败", err.Error())
+		return
+	}
+	limit := 50
+	if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	respondSuccess(c, map[string]any{"stats": stats, "entries": list}, "获取下载缓存成功")
+}
+
+// cleanupMediaCacheHandler 清理下载缓存
+func (s *AppServer) cleanupMediaCacheHandler(c *gin.Context) {
+	var req MediaCacheCleanupRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求参数错误", err.Error())
+			return
+		}
+	}
+	opts := downloader.CacheCleanupOptions{MaxBytes: req.MaxBytes, All: req.All, DryRun: req.DryRun}
+	if req.OlderThan != "" {
+		d, err := time.ParseDuration(req.OlderThan)
+		if err != nil || d <= 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "older_than 格式错误，示例: 24h", req.OlderThan)
+			return
+		}
+		opts.OlderThan = d
+	}
+
+	res, err := s.mediaCache().Cleanup(opts)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "MEDIA_CACHE_CLEANUP_FAILED", "清理下载缓存失败", err.Error())
+		return
+	}
+	respondSuccess(c, res, "清理下载缓存成功")
+}
+
+func (s *AppServer) mediaCache() *downloader.Cache {
+	if s.runtime != nil && s.runtime.MediaCache != nil {
+		return s.runtime.MediaCache
+	}
+	return downloader.DefaultCache()
+}
+
+func respondArtifactError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, artifacts.ErrInvalidID):
+		respondError(c, http.StatusBadRequest, "INVALID_ARTIFACT_ID", "快照 ID 无效", err.Error())
+	case errors.Is(err, artifacts.ErrNotFound):
+		respondError(c, http.StatusNotFound, "ARTIFACT_NOT_FOUND", "快照不存在或已被清理", err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, "ARTIFACT_READ_FAILED", "读取快照失败", err.Error())
+	}
+}
+
+// userSelectorFromQuery 从查询参数 account / index 构造用户选择器
+func userSelectorFromQuery(c *gin.Context) *UserSelector {
+	sel := &UserSelector{Account: c.Query("account")}
+	if v := c.Query("index"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			sel.Index = &n
+		}
+	}
+	return sel
+}
+
+// listMyNotesHandler 我的笔记列表
+func (s *AppServer) listMyNotesHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	result, err := s.xiaohongshuService.ListMyNotesForAccount(c.Request.Context(), account, c.Query("status"), limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "LIST_NOTES_FAILED",
+			"获取笔记列表失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, "获取笔记列表成功")
+}
+
+// getMyNoteHandler 查看我的笔记
+func (s *AppServer) getMyNoteHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	note, err := s.xiaohongshuService.GetMyNoteForAccount(c.Request.Context(), account, c.Param("id"))
+	if errors.Is(err, xiaohongshu.ErrNoteNotFound) {
+		respondError(c, http.StatusNotFound, "NOTE_NOT_FOUND", "笔记不存在", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "GET_NOTE_FAILED",
+			"查看笔记失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, note, "查看笔记成功")
+}
+
+// deleteMyNoteHandler 删除我的笔记
+func (s *AppServer) deleteMyNoteHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.DeleteMyNoteForAccount(c.Request.Context(), account, c.Param("id"))
+	if errors.Is(err, xiaohongshu.ErrNoteNotFound) {
+		respondError(c, http.StatusNotFound, "NOTE_NOT_FOUND", "笔记不存在", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DELETE_NOTE_FAILED",
+			"删除笔记失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, result.Message)
+}
+
+// editNoteHandler 编辑已发布的笔记
+func (s *AppServer) editNoteHandler(c *gin.Context) {
+	var req EditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.EditNoteForAccount(c.Request.Context(), account, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "EDIT_NOTE_FAILED",
+			"编辑笔记失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, "编辑笔记成功")
+}
+
+// searchTopicsHandler 话题搜索
+func (s *AppServer) searchTopicsHandler(c *gin.Context) {
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		respondError(c, http.StatusBadRequest, "MISSING_KEYWORD",
+			"缺少关键词参数", "keyword parameter is required")
+		return
+	}
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.SearchTopicsForAccount(c.Request.Context(), account, keyword)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "SEARCH_TOPICS_FAILED",
+			"话题搜索失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, "话题搜索成功")
+}
+
+// listDraftsHandler 草稿箱列表
+func (s *AppServer) listDraftsHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.ListDraftsForAccount(c.Request.Context(), account)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "LIST_DRAFTS_FAILED",
+			"获取草稿箱失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, "获取草稿箱成功")
+}
+
+// publishDraftHandler 发布草稿
+func (s *AppServer) publishDraftHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.PublishDraftForAccount(c.Request.Context(), account, c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PUBLISH_DRAFT_FAILED",
+			"发布草稿失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, "发布成功")
+}
+
+// deleteDraftHandler 删除草稿
+func (s *AppServer) deleteDraftHandler(c *gin.Context) {
+	account := s.resolveAccount(userSelectorFromQuery(c))
+
+	result, err := s.xiaohongshuService.DeleteDraftForAccount(c.Request.Context(), account, c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DELETE_DRAFT_FAILED",
+			"删除草稿失败", err.Error())
+		return
+	}
+
+	c.Set("account", account)
+	respondSuccess(c, result, result.Message)
+}
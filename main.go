@@ -6,6 +6,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
 )
 
 func main() {
@@ -15,12 +16,21 @@ func main() {
 		port     string
 		dataDir  string
 		poolSize int
+
+		cookieBackend     string
+		persistentProfile bool
+		warmPool          bool
+		remoteCDP         string
 	)
 	flag.BoolVar(&headless, "headless", true, "是否无头模式")
 	flag.StringVar(&binPath, "bin", "", "浏览器二进制文件路径")
 	flag.StringVar(&port, "port", ":18060", "端口")
 	flag.StringVar(&dataDir, "data_dir", "", "数据目录（users.json/ip.txt/cookies等）")
 	flag.IntVar(&poolSize, "browser_pool_size", 0, "浏览器并发池大小")
+	flag.BoolVar(&persistentProfile, "persistent_profile", false, "每个账号使用持久化的浏览器 profile（<data_dir>/profiles/<account>）")
+	flag.BoolVar(&warmPool, "warm_pool", false, "按账号复用常驻浏览器，减少每次操作的启动开销")
+	flag.StringVar(&remoteCDP, "remote_cdp", "", "连接已有浏览器的 DevTools 端点（ws://... 或 http://host:9222），不再启动本地浏览器")
+	flag.StringVar(&cookieBackend, "cookie_backend", "", "cookies 存储后端（file/bolt/http），默认 file")
 	flag.Parse()
 
 	if len(binPath) == 0 {
@@ -36,6 +46,22 @@ func main() {
 	if poolSize > 0 {
 		configs.InitBrowserPoolSize(poolSize)
 	}
+	if persistentProfile {
+		configs.InitPersistentProfile(true)
+	}
+	if warmPool {
+		configs.InitWarmPool(true)
+	}
+	if remoteCDP != "" {
+		configs.InitRemoteCDP(remoteCDP)
+	}
+	if cookieBackend != "" {
+		configs.InitCookieBackend(cookieBackend)
+	}
+
+	if len(imageproc.ExternalDecoders()) == 0 {
+		logrus.Warn("未找到 ImageMagick/libheif/ffmpeg/sips，image_processing 将无法处理 HEIC/AVIF 图片")
+	}
 
 	runtime, err := NewRuntime(configs.GetDataDir(), configs.GetBrowserPoolSize())
 	if err != nil {
@@ -12,7 +12,7 @@ import (
 func TestPrepareBatchPostForQueue_TitleTooLong(t *testing.T) {
 	s := &AppServer{}
 	post := BatchPost{Title: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Content: "c", Images: []string{"/tmp/a.jpg"}}
-	_, err := s.prepareBatchPostForQueue(context.Background(), post)
+	_, _, err := s.prepareBatchPostForQueue(context.Background(), post)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -23,7 +23,7 @@ func TestPrepareBatchPostForQueue_ContentTooLong(t *testing.T) {
 
 	s := &AppServer{}
 	post := BatchPost{Title: "t", Content: "123456", Images: []string{"/tmp/a.jpg"}}
-	_, err := s.prepareBatchPostForQueue(context.Background(), post)
+	_, _, err := s.prepareBatchPostForQueue(context.Background(), post)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -32,7 +32,7 @@ func TestPrepareBatchPostForQueue_ContentTooLong(t *testing.T) {
 func TestPrepareBatchPostForQueue_ScheduleTooSoon(t *testing.T) {
 	s := &AppServer{}
 	post := BatchPost{Title: "t", Content: "c", Images: []string{"/tmp/a.jpg"}, ScheduleAt: time.Now().Add(30 * time.Minute).Format(time.RFC3339)}
-	_, err := s.prepareBatchPostForQueue(context.Background(), post)
+	_, _, err := s.prepareBatchPostForQueue(context.Background(), post)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -51,9 +51,10 @@ func TestPrepareBatchPostForQueue_LocalImageOk(t *testing.T) {
 		t.Fatalf("write image: %v", err)
 	}
 
+	t.Setenv("XHS_MCP_MEDIA_CACHE_DIR", t.TempDir())
 	s := &AppServer{}
 	post := BatchPost{Title: "t", Content: "c", Images: []string{imgPath}}
-	prepared, err := s.prepareBatchPostForQueue(context.Background(), post)
+	prepared, reports, err := s.prepareBatchPostForQueue(context.Background(), post)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -64,4 +65,37 @@ func TestPrepareBatchPostForQueue_LocalImageOk(t *testing.T) {
 	if prepared.Images[0] != abs {
 		t.Fatalf("expected abs path %q, got %q", abs, prepared.Images[0])
 	}
+	if len(reports) != 1 || reports[0].Path != abs || reports[0].MimeType != "image/png" || reports[0].Bytes != int64(len(pngBytes)) {
+		t.Fatalf("unexpected image report: %+v", reports)
+	}
+}
+
+func TestPrepareBatchPostForQueue_ReportsEveryImage(t *testing.T) {
+	pngBytes, err := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mP8/x8AAwMCAO0V9b0AAAAASUVORK5CYII=")
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	dir := t.TempDir()
+	imgPath := filepath.Join(dir, "a.png")
+	if err := os.WriteFile(imgPath, pngBytes, 0644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	t.Setenv("XHS_MCP_MEDIA_CACHE_DIR", t.TempDir())
+
+	s := &AppServer{}
+	post := BatchPost{Title: "t", Content: "c", Images: []string{filepath.Join(dir, "missing.png"), dir, imgPath}}
+	_, reports, err := s.prepareBatchPostForQueue(context.Background(), post)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	// 本地路径出错时其余图片也会被处理，报告覆盖全部图片
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got %+v", reports)
+	}
+	if reports[0].Error == "" || reports[1].Error == "" {
+		t.Fatalf("expected failures for missing file and directory: %+v", reports)
+	}
+	if reports[2].Error != "" || reports[2].Path == "" {
+		t.Fatalf("expected valid image to be processed: %+v", reports[2])
+	}
 }
@@ -3,10 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +13,7 @@ import (
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/xhsutil"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
@@ -309,8 +307,7 @@ func (s *AppServer) handleDeleteCookies(ctx context.Context, args LoginUserArgs)
 	cookiePath := cookies.GetCookiesFilePath()
 	if s.runtime != nil && s.runtime.CookieStore != nil {
 		u := s.xiaohongshuService.resolveUser(effectiveAccount)
-		abs, _ := s.runtime.CookieStore.CookiePathFor(effectiveAccount, u.CookieFile)
-		cookiePath = abs
+		cookiePath = s.runtime.CookieStore.Location(effectiveAccount, u.CookieFile)
 	}
 	resultText := fmt.Sprintf("Cookies 已成功删除，登录状态已重置。\n\n删除的文件路径: %s\n\n下次操作时，需要重新登录。", cookiePath)
 	return &MCPToolResult{
@@ -321,6 +318,26 @@ func (s *AppServer) handleDeleteCookies(ctx context.Context, args LoginUserArgs)
 	}
 }
 
+// handleImportCookies 处理导入 cookies
+func (s *AppServer) handleImportCookies(ctx context.Context, args ImportCookiesArgs) *MCPToolResult {
+	account := strings.TrimSpace(args.Account)
+	if account == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "导入 cookies 失败: 缺少 account"}}, IsError: true}
+	}
+	logrus.WithFields(logrus.Fields{"account": account, "format": args.Format}).Info("MCP: 导入 cookies")
+
+	result, err := s.xiaohongshuService.ImportCookiesForAccount(ctx, account, args.Content, args.Format)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "导入 cookies 失败: " + err.Error()}}, IsError: true}
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
 // handlePublishContent 处理发布内容
 func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]any) *MCPToolResult {
 	logrus.Info("MCP: 发布内容")
@@ -348,17 +365,26 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]an
 	// 解析定时发布参数
 	scheduleAt, _ := args["schedule_at"].(string)
 	location, _ := args["location"].(string)
+	draft, _ := args["draft"].(bool)
+	options, _ := args["options"].(xiaohongshu.PublishOptions)
+	exactTags, _ := args["exact_tags"].(bool)
+	imageProcessing, _ := args["image_processing"].(*imageproc.Options)
 
 	logrus.Infof("MCP: 发布内容 - 标题: %s, 图片数量: %d, 标签数量: %d, 地点: %s, 定时: %s", title, len(imagePaths), len(tags), location, scheduleAt)
 
 	// 构建发布请求
 	req := &PublishRequest{
-		Title:      title,
-		Content:    content,
-		Images:     imagePaths,
-		Tags:       tags,
-		Location:   location,
-		ScheduleAt: scheduleAt,
+		Title:           title,
+		Content:         content,
+		Images:          imagePaths,
+		Tags:            tags,
+		Mentions:        stringsFromArgs(args, "mentions"),
+		ExactTags:       exactTags,
+		Location:        location,
+		ScheduleAt:      scheduleAt,
+		Draft:           draft,
+		PublishOptions:  options,
+		ImageProcessing: imageProcessing,
 	}
 
 	// 执行发布
@@ -375,6 +401,9 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]an
 	}
 
 	resultText := fmt.Sprintf("内容发布成功: %+v", result)
+	if draft {
+		resultText = fmt.Sprintf("内容已存入草稿箱: %+v", result)
+	}
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
@@ -396,16 +425,16 @@ func (s *AppServer) handlePublishContentBatch(ctx context.Context, args PublishC
 		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "发布失败: images 至少需要 1 张"}}, IsError: true}
 	}
 
-	prepared, err := s.prepareBatchPostForQueue(ctx, BatchPost{
+	prepared, reports, err := s.prepareBatchPostForQueue(ctx, BatchPost{
 		Title:      args.Title,
 		Content:    args.Content,
-		Images:     args.Images,
+		Images:     mediaInputStrings(args.Images),
 		Tags:       args.Tags,
 		Location:   args.Location,
 		ScheduleAt: args.ScheduleAt,
 	})
 	if err != nil {
-		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "预检失败: " + err.Error()}}, IsError: true}
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: precheckFailureText(err, reports)}}, IsError: true}
 	}
 
 	accounts := s.resolveTargetAccounts(args.Targets)
@@ -458,11 +487,13 @@ func (s *AppServer) handlePublishContentBatch(ctx context.Context, args PublishC
 
 // handlePublishVideo 处理发布视频内容（仅本地单个视频文件）
 func (s *AppServer) handlePublishVideo(ctx context.Context, args map[string]any) *MCPToolResult {
-	logrus.Info("MCP: 发布视频内容（本地）")
+	logrus.Info("MCP: 发布视频内容")
 
 	title, _ := args["title"].(string)
 	content, _ := args["content"].(string)
 	videoPath, _ := args["video"].(string)
+	cover, _ := args["cover"].(string)
+	coverAt, _ := args["cover_at"].(string)
 	tagsInterface, _ := args["tags"].([]any)
 
 	var tags []string
@@ -476,7 +507,7 @@ func (s *AppServer) handlePublishVideo(ctx context.Context, args map[string]any)
 		return &MCPToolResult{
 			Content: []MCPContent{{
 				Type: "text",
-				Text: "发布失败: 缺少本地视频文件路径",
+				Text: "发布失败: 缺少视频文件路径或链接",
 			}},
 			IsError: true,
 		}
@@ -485,17 +516,26 @@ func (s *AppServer) handlePublishVideo(ctx context.Context, args map[string]any)
 	// 解析定时发布参数
 	scheduleAt, _ := args["schedule_at"].(string)
 	location, _ := args["location"].(string)
+	draft, _ := args["draft"].(bool)
+	options, _ := args["options"].(xiaohongshu.PublishOptions)
+	exactTags, _ := args["exact_tags"].(bool)
 
 	logrus.Infof("MCP: 发布视频 - 标题: %s, 标签数量: %d, 地点: %s, 定时: %s", title, len(tags), location, scheduleAt)
 
 	// 构建发布请求
 	req := &PublishVideoRequest{
-		Title:      title,
-		Content:    content,
-		Video:      videoPath,
-		Tags:       tags,
-		Location:   location,
-		ScheduleAt: scheduleAt,
+		Title:          title,
+		Content:        content,
+		Video:          videoPath,
+		Cover:          cover,
+		CoverAt:        coverAt,
+		Tags:           tags,
+		Mentions:       stringsFromArgs(args, "mentions"),
+		ExactTags:      exactTags,
+		Location:       location,
+		ScheduleAt:     scheduleAt,
+		Draft:          draft,
+		PublishOptions: options,
 	}
 
 	// 执行发布
@@ -512,6 +552,9 @@ func (s *AppServer) handlePublishVideo(ctx context.Context, args map[string]any)
 	}
 
 	resultText := fmt.Sprintf("视频发布成功: %+v", result)
+	if draft {
+		resultText = fmt.Sprintf("视频已存入草稿箱: %+v", result)
+	}
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
@@ -525,7 +568,12 @@ func (s *AppServer) handleListFeeds(ctx context.Context, args ListFeedsArgs) *MC
 	logrus.Info("MCP: 获取Feeds列表")
 
 	account := s.resolveAccount(args.User)
-	result, err := s.xiaohongshuService.ListFeedsForAccount(ctx, account)
+	result, err := s.xiaohongshuService.ListFeedsForAccount(ctx, account, xiaohongshu.FeedsListOptions{
+		Channel:     args.Channel,
+		Count:       args.Count,
+		Cursor:      args.Cursor,
+		ScrollSpeed: args.ScrollSpeed,
+	})
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -975,7 +1023,7 @@ func (s *AppServer) handlePostComment(ctx context.Context, args map[string]any)
 
 	// 发表评论
 	account := s.resolveAccountFromArgsMap(args)
-	result, err := s.xiaohongshuService.PostCommentToFeedForAccount(ctx, account, feedID, xsecToken, content)
+	result, err := s.xiaohongshuService.PostCommentToFeedForAccount(ctx, account, feedID, xsecToken, content, stringsFromArgs(args, "mentions"))
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -988,6 +1036,9 @@ func (s *AppServer) handlePostComment(ctx context.Context, args map[string]any)
 
 	// 返回成功结果，只包含feed_id
 	resultText := fmt.Sprintf("评论发表成功 - Feed ID: %s", result.FeedID)
+	if len(result.Warnings) > 0 {
+		resultText += "\n注意: " + strings.Join(result.Warnings, "；")
+	}
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
@@ -1050,7 +1101,7 @@ func (s *AppServer) handleReplyComment(ctx context.Context, args map[string]any)
 
 	// 回复评论
 	account := s.resolveAccountFromArgsMap(args)
-	result, err := s.xiaohongshuService.ReplyCommentToFeedForAccount(ctx, account, feedID, xsecToken, commentID, userID, content)
+	result, err := s.xiaohongshuService.ReplyCommentToFeedForAccount(ctx, account, feedID, xsecToken, commentID, userID, content, stringsFromArgs(args, "mentions"))
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -1063,6 +1114,9 @@ func (s *AppServer) handleReplyComment(ctx context.Context, args map[string]any)
 
 	// 返回成功结果
 	responseText := fmt.Sprintf("评论回复成功 - Feed ID: %s, Comment ID: %s, User ID: %s", result.FeedID, result.TargetCommentID, result.TargetUserID)
+	if len(result.Warnings) > 0 {
+		responseText += "\n注意: " + strings.Join(result.Warnings, "；")
+	}
 	return &MCPToolResult{
 		Content: []MCPContent{{
 			Type: "text",
@@ -1095,7 +1149,9 @@ func (s *AppServer) handleBatchTaskOpen(ctx context.Context) *MCPToolResult {
 	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
 }
 
-func (s *AppServer) prepareBatchPostForQueue(ctx context.Context, post BatchPost) (BatchPost, error) {
+// prepareBatchPostForQueue 校验帖子并把图片转换为本地文件；返回每张图片的处理结果，
+// 图片处理失败时结果中标明是哪几张出错
+func (s *AppServer) prepareBatchPostForQueue(ctx context.Context, post BatchPost) (BatchPost, []downloader.ImageReport, error) {
 	_ = ctx
 	logrus.WithFields(logrus.Fields{
 		"title":           shortenForLog(post.Title, 64),
@@ -1109,30 +1165,26 @@ func (s *AppServer) prepareBatchPostForQueue(ctx context.Context, post BatchPost
 	}).Info("batch:add_post precheck begin")
 
 	post.Title = strings.TrimSpace(post.Title)
-	if xhsutil.CalcTitleLength(post.Title) > 20 {
-		return BatchPost{}, fmt.Errorf("标题长度超过限制")
+	if err := checkNoteTitle(post.Title); err != nil {
+		return BatchPost{}, nil, err
 	}
-
-	maxRunes := configs.GetContentMaxRunes()
-	if maxRunes > 0 {
-		if n := len([]rune(post.Content)); n > maxRunes {
-			return BatchPost{}, fmt.Errorf("正文长度超过限制: %d/%d", n, maxRunes)
-		}
+	if err := checkNoteContent(post.Content); err != nil {
+		return BatchPost{}, nil, err
 	}
 
 	if strings.TrimSpace(post.ScheduleAt) != "" {
 		t, err := time.Parse(time.RFC3339, post.ScheduleAt)
 		if err != nil {
-			return BatchPost{}, fmt.Errorf("定时发布时间格式错误，请使用 ISO8601 格式: %v", err)
+			return BatchPost{}, nil, fmt.Errorf("定时发布时间格式错误，请使用 ISO8601 格式: %v", err)
 		}
 		now := time.Now()
 		minTime := now.Add(1 * time.Hour)
 		maxTime := now.Add(14 * 24 * time.Hour)
 		if t.Before(minTime) {
-			return BatchPost{}, fmt.Errorf("定时发布时间必须至少在1小时后")
+			return BatchPost{}, nil, fmt.Errorf("定时发布时间必须至少在1小时后")
 		}
 		if t.After(maxTime) {
-			return BatchPost{}, fmt.Errorf("定时发布时间不能超过14天")
+			return BatchPost{}, nil, fmt.Errorf("定时发布时间不能超过14天")
 		}
 	}
 
@@ -1145,70 +1197,44 @@ func (s *AppServer) prepareBatchPostForQueue(ctx context.Context, post BatchPost
 		images = append(images, img)
 	}
 	if len(images) == 0 {
-		return BatchPost{}, fmt.Errorf("images 至少需要 1 张")
+		return BatchPost{}, nil, fmt.Errorf("images 至少需要 1 张")
 	}
 	if len(images) > 18 {
-		return BatchPost{}, fmt.Errorf("图片数量超过限制: %d/18", len(images))
+		return BatchPost{}, nil, fmt.Errorf("图片数量超过限制: %d/18", len(images))
 	}
 
 	maxImageBytes := configs.GetImageMaxBytes()
-	urlCount := 0
+	remoteCount := 0
 	localCount := 0
 	for _, img := range images {
-		if downloader.IsImageURL(img) {
-			urlCount++
-		} else {
+		if downloader.IsLocalPath(img) {
 			localCount++
+		} else {
+			remoteCount++
 		}
 	}
 	logrus.WithFields(logrus.Fields{
 		"images_total":          len(images),
-		"images_url":            urlCount,
+		"images_remote":         remoteCount,
 		"images_local":          localCount,
 		"images_sample":         shortenSliceForLog(images, 3, 96),
 		"image_max_bytes":       maxImageBytes,
 		"downloader_output_dir": configs.GetImagesPath(),
 	}).Info("batch:add_post images normalized")
 
-	localErrs := make([]error, 0)
-	for i, img := range images {
-		if downloader.IsImageURL(img) {
-			continue
-		}
-		abs, err := filepath.Abs(img)
-		if err != nil {
-			localErrs = append(localErrs, fmt.Errorf("图片路径无效: %s", img))
-			continue
-		}
-		info, err := os.Stat(abs)
-		if err != nil {
-			localErrs = append(localErrs, fmt.Errorf("图片不可访问: %s", img))
-			continue
-		}
-		if info.IsDir() {
-			localErrs = append(localErrs, fmt.Errorf("图片路径是目录: %s", img))
-			continue
-		}
-		if maxImageBytes > 0 && info.Size() > maxImageBytes {
-			localErrs = append(localErrs, fmt.Errorf("图片过大: %s (%d bytes)", img, info.Size()))
-			continue
-		}
-		images[i] = abs
-	}
-	if len(localErrs) > 0 {
-		logrus.WithFields(logrus.Fields{
-			"errors": len(localErrs),
-		}).Warn("batch:add_post local image validation failed")
-		return BatchPost{}, errors.Join(localErrs...)
-	}
-
-	processor := downloader.NewImageProcessor()
-	localPaths, err := processor.ProcessImages(images)
+	// 本地路径的检查（是否存在、是否为目录、大小）与远程图片一起在 ProcessImagesReport 中完成，
+	// 出错时报告中包含每一张图片的结果
+	processor := downloader.NewImageProcessorWithCache(s.mediaCache())
+	reports, err := processor.ProcessImagesReport(images)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error": err.Error(),
 		}).Warn("batch:add_post image processing failed")
-		return BatchPost{}, err
+		return BatchPost{}, reports, err
+	}
+	localPaths := make([]string, len(reports))
+	for i, r := range reports {
+		localPaths[i] = r.Path
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -1217,7 +1243,7 @@ func (s *AppServer) prepareBatchPostForQueue(ctx context.Context, post BatchPost
 	}).Info("batch:add_post precheck ok")
 
 	post.Images = localPaths
-	return post, nil
+	return post, reports, nil
 }
 
 func (s *AppServer) handleBatchTaskAddPost(ctx context.Context, args BatchTaskAddPostArgs) *MCPToolResult {
@@ -1238,15 +1264,18 @@ func (s *AppServer) handleBatchTaskAddPost(ctx context.Context, args BatchTaskAd
 		"images_in_sample": shortenSliceForLog(args.Post.Images, 3, 96),
 	}).Info("batch:add_post request received")
 
-	prepared, err := s.prepareBatchPostForQueue(ctx, args.Post)
+	prepared, reports, err := s.prepareBatchPostForQueue(ctx, args.Post)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"task_id": args.TaskID,
 			"error":   err.Error(),
 		}).Warn("batch:add_post rejected")
-		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "预检失败: " + err.Error()}}, IsError: true}
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: precheckFailureText(err, reports)}}, IsError: true}
 	}
+	// 排队期间缓存中的图片不能被淘汰，帖子发布结束后释放
+	prepared.release = s.mediaCache().Pin(prepared.Images...)
 	if err := s.runtime.BatchTasks.AddPost(args.TaskID, prepared); err != nil {
+		prepared.releaseMedia()
 		logrus.WithFields(logrus.Fields{
 			"task_id": args.TaskID,
 			"error":   err.Error(),
@@ -1261,10 +1290,20 @@ func (s *AppServer) handleBatchTaskAddPost(ctx context.Context, args BatchTaskAd
 		"done":    snap.Done,
 		"failed":  snap.Failed,
 	}).Info("batch:add_post stored")
-	jsonData, _ := json.MarshalIndent(map[string]any{"task_id": args.TaskID, "status": snap}, "", "  ")
+	jsonData, _ := json.MarshalIndent(map[string]any{"task_id": args.TaskID, "status": snap, "images": reports}, "", "  ")
 	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
 }
 
+// precheckFailureText 预检失败的提示，图片处理失败时附带每张图片的结果
+func precheckFailureText(err error, reports []downloader.ImageReport) string {
+	text := "预检失败: " + err.Error()
+	if len(reports) > 0 {
+		data, _ := json.MarshalIndent(map[string]any{"images": reports}, "", "  ")
+		text += "\n" + string(data)
+	}
+	return text
+}
+
 func shortenForLog(s string, maxRunes int) string {
 	s = strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", " "), "\n", " "))
 	if maxRunes <= 0 {
@@ -1359,3 +1398,149 @@ func (s *AppServer) handleBatchTaskRunSync(ctx context.Context, args BatchTaskRu
 	}
 	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
 }
+
+// handleListMyNotes 我的笔记列表
+func (s *AppServer) handleListMyNotes(ctx context.Context, args ListMyNotesArgs) *MCPToolResult {
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "status": args.Status}).Info("MCP: 我的笔记列表")
+
+	result, err := s.xiaohongshuService.ListMyNotesForAccount(ctx, account, strings.TrimSpace(args.Status), args.Limit)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "获取笔记列表失败: " + err.Error()}}, IsError: true}
+	}
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
+// handleGetMyNote 查看我的笔记
+func (s *AppServer) handleGetMyNote(ctx context.Context, args MyNoteArgs) *MCPToolResult {
+	noteID := strings.TrimSpace(args.NoteID)
+	if noteID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "查看笔记失败: 缺少note_id参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+
+	note, err := s.xiaohongshuService.GetMyNoteForAccount(ctx, account, noteID)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "查看笔记失败: " + err.Error()}}, IsError: true}
+	}
+	jsonData, err := json.MarshalIndent(note, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
+// handleDeleteMyNote 删除我的笔记
+func (s *AppServer) handleDeleteMyNote(ctx context.Context, args MyNoteArgs) *MCPToolResult {
+	noteID := strings.TrimSpace(args.NoteID)
+	if noteID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "删除笔记失败: 缺少note_id参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "note_id": noteID}).Info("MCP: 删除笔记")
+
+	res, err := s.xiaohongshuService.DeleteMyNoteForAccount(ctx, account, noteID)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "删除笔记失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s - Note ID: %s", res.Message, res.NoteID)}}}
+}
+
+// handleEditNote 编辑已发布的笔记
+func (s *AppServer) handleEditNote(ctx context.Context, args EditNoteArgs) *MCPToolResult {
+	noteID := strings.TrimSpace(args.NoteID)
+	if noteID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "编辑笔记失败: 缺少note_id参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "note_id": noteID}).Info("MCP: 编辑笔记")
+
+	req := &EditNoteRequest{
+		Title:      args.Title,
+		Content:    args.Content,
+		Tags:       args.Tags,
+		Location:   args.Location,
+		ImageOrder: args.ImageOrder,
+		ExactTags:  args.ExactTags,
+	}
+	res, err := s.xiaohongshuService.EditNoteForAccount(ctx, account, noteID, req)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "编辑笔记失败: " + err.Error()}}, IsError: true}
+	}
+	jsonData, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
+// handleListDrafts 草稿箱列表
+func (s *AppServer) handleListDrafts(ctx context.Context, args ListDraftsArgs) *MCPToolResult {
+	account := s.resolveAccount(args.User)
+	logrus.WithField("account", account).Info("MCP: 草稿箱列表")
+
+	result, err := s.xiaohongshuService.ListDraftsForAccount(ctx, account)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "获取草稿箱失败: " + err.Error()}}, IsError: true}
+	}
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
+
+// handlePublishDraft 发布草稿
+func (s *AppServer) handlePublishDraft(ctx context.Context, args DraftArgs) *MCPToolResult {
+	draftID := strings.TrimSpace(args.DraftID)
+	if draftID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "发布草稿失败: 缺少draft_id参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "draft_id": draftID}).Info("MCP: 发布草稿")
+
+	result, err := s.xiaohongshuService.PublishDraftForAccount(ctx, account, draftID)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "发布草稿失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("草稿发布成功: %+v", result)}}}
+}
+
+// handleDeleteDraft 删除草稿
+func (s *AppServer) handleDeleteDraft(ctx context.Context, args DraftArgs) *MCPToolResult {
+	draftID := strings.TrimSpace(args.DraftID)
+	if draftID == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "删除草稿失败: 缺少draft_id参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "draft_id": draftID}).Info("MCP: 删除草稿")
+
+	res, err := s.xiaohongshuService.DeleteDraftForAccount(ctx, account, draftID)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "删除草稿失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: fmt.Sprintf("%s - Draft ID: %s", res.Message, res.DraftID)}}}
+}
+
+// handleSearchTopics 话题搜索
+func (s *AppServer) handleSearchTopics(ctx context.Context, args SearchTopicsArgs) *MCPToolResult {
+	if strings.TrimSpace(args.Keyword) == "" {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "话题搜索失败: 缺少keyword参数"}}, IsError: true}
+	}
+	account := s.resolveAccount(args.User)
+	logrus.WithFields(logrus.Fields{"account": account, "keyword": args.Keyword}).Info("MCP: 话题搜索")
+
+	res, err := s.xiaohongshuService.SearchTopicsForAccount(ctx, account, args.Keyword)
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "话题搜索失败: " + err.Error()}}, IsError: true}
+	}
+	jsonData, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: "序列化失败: " + err.Error()}}, IsError: true}
+	}
+	return &MCPToolResult{Content: []MCPContent{{Type: "text", Text: string(jsonData)}}}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -116,3 +117,30 @@ func TestResolveTargetAccounts_PriorityAndFallback(t *testing.T) {
 	got = (&AppServer{runtime: &Runtime{UserPool: &userpool.Manager{}}}).resolveTargetAccounts(TargetUsers{AllEnabled: true})
 	require.Equal(t, []string{"default"}, got)
 }
+
+func TestMediaInput_UnmarshalStringsAndEmbeddedResources(t *testing.T) {
+	var args PublishContentArgs
+	err := json.Unmarshal([]byte(`{"title":"t","content":"c","images":[
+		"https://example.com/a.png",
+		"media://abc",
+		{"type":"resource","resource":{"uri":"file:///a.png","mimeType":"image/png","blob":"iVBORw0K"}},
+		{"mimeType":"image/jpeg","blob":"/9j/"},
+		{"type":"resource","resource":{"uri":"https://example.com/b.png"}}
+	]}`), &args)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"https://example.com/a.png",
+		"media://abc",
+		"data:image/png;base64,iVBORw0K",
+		"data:image/jpeg;base64,/9j/",
+		"https://example.com/b.png",
+	}, mediaInputStrings(args.Images))
+
+	require.Error(t, json.Unmarshal([]byte(`{"images":[{"type":"resource","resource":{}}]}`), &args))
+
+	// 生成的 schema 同时接受字符串与对象
+	schema := inputSchemaWithMedia[PublishContentArgs]()
+	items := schema.Properties["images"].Items
+	require.NotNil(t, items)
+	require.Len(t, items.AnyOf, 2)
+}
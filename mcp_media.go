@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// MediaInput 图片输入，可以是字符串（HTTP/HTTPS 链接、服务端本地路径、data URI、media:// 句柄），
+// 也可以是 MCP 内嵌资源 {"type":"resource","resource":{"mimeType":"image/png","blob":"<base64>"}}。
+// 内嵌资源统一转换为 data URI，之后的处理与字符串输入相同。
+type MediaInput string
+
+// mediaResource MCP 内嵌资源的内容部分
+type mediaResource struct {
+	URI      string `json:"uri,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+func (m *MediaInput) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = MediaInput(strings.TrimSpace(s))
+		return nil
+	}
+
+	// 既接受完整的内嵌资源，也接受直接传入的资源内容
+	var obj struct {
+		mediaResource
+		Type     string         `json:"type,omitempty"`
+		Resource *mediaResource `json:"resource,omitempty"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("图片需为字符串或 MCP 内嵌资源: %w", err)
+	}
+	res := obj.mediaResource
+	if obj.Resource != nil {
+		res = *obj.Resource
+	}
+	switch {
+	case res.Blob != "":
+		mime := res.MIMEType
+		if mime == "" {
+			mime = "application/octet-stream"
+		}
+		*m = MediaInput("data:" + mime + ";base64," + res.Blob)
+	case res.URI != "":
+		*m = MediaInput(res.URI)
+	default:
+		return fmt.Errorf("内嵌资源缺少 blob 或 uri")
+	}
+	return nil
+}
+
+// mediaInputSchema MediaInput 的 JSON Schema：字符串或内嵌资源对象
+var mediaInputSchema = &jsonschema.Schema{
+	AnyOf: []*jsonschema.Schema{
+		{Type: "string"},
+		{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"type": {Type: "string", Description: "固定为 resource"},
+				"resource": {
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"uri":      {Type: "string"},
+						"mimeType": {Type: "string"},
+						"blob":     {Type: "string", Description: "base64 编码的图片数据"},
+					},
+				},
+			},
+			Required: []string{"resource"},
+		},
+	},
+}
+
+// inputSchemaWithMedia 推断工具参数的 JSON Schema，其中 MediaInput 字段使用 mediaInputSchema
+func inputSchemaWithMedia[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](&jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeFor[MediaInput](): mediaInputSchema,
+		},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("生成 %s 的输入 schema 失败: %v", reflect.TypeFor[T](), err))
+	}
+	return schema
+}
+
+// mediaInputStrings 辅助函数：MediaInput 列表转换为字符串列表
+func mediaInputStrings(items []MediaInput) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = string(item)
+	}
+	return out
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"runtime/debug"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // Helper functions for annotation pointers
@@ -35,36 +38,55 @@ type CheckLoginStatusBatchArgs struct {
 }
 
 type PublishContentBatchArgs struct {
-	Targets     TargetUsers `json:"targets,omitempty" jsonschema:"批量目标选择（为空则默认 all_enabled=true）"`
-	MaxAccounts int         `json:"max_accounts,omitempty" jsonschema:"最多使用多少个账号执行（从目标集合头部截取）"`
-	Title       string      `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
-	Content     string      `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
-	Images      []string    `json:"images" jsonschema:"图片路径列表（至少需要1张图片）。支持两种方式：1. HTTP/HTTPS图片链接（自动下载）；2. 本地图片绝对路径（推荐，如:/Users/user/image.jpg）"`
-	Tags        []string    `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
-	Location    string      `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
-	ScheduleAt  string      `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
+	Targets     TargetUsers  `json:"targets,omitempty" jsonschema:"批量目标选择（为空则默认 all_enabled=true）"`
+	MaxAccounts int          `json:"max_accounts,omitempty" jsonschema:"最多使用多少个账号执行（从目标集合头部截取）"`
+	Title       string       `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
+	Content     string       `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
+	Images      []MediaInput `json:"images" jsonschema:"图片列表（至少需要1张图片）。支持：1. HTTP/HTTPS图片链接（自动下载）；2. 服务端本地图片绝对路径（如:/Users/user/image.jpg）；3. data:image/png;base64,... 形式的 data URI；4. 上传接口返回的 media://<sha256> 句柄；5. MCP 内嵌资源 {type:resource, resource:{mimeType, blob}}。客户端与服务端不在同一台机器时请用 3-5"`
+	Tags        []string     `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
+	Location    string       `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
+	ScheduleAt  string       `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
 }
 
 // PublishContentArgs 发布内容的参数
 type PublishContentArgs struct {
-	User       *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
-	Title      string        `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
-	Content    string        `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
-	Images     []string      `json:"images" jsonschema:"图片路径列表（至少需要1张图片）。支持两种方式：1. HTTP/HTTPS图片链接（自动下载）；2. 本地图片绝对路径（推荐，如:/Users/user/image.jpg）"`
-	Tags       []string      `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
-	Location   string        `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
-	ScheduleAt string        `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
+	User            *UserSelector      `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	Title           string             `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
+	Content         string             `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
+	Images          []MediaInput       `json:"images" jsonschema:"图片列表（至少需要1张图片）。支持：1. HTTP/HTTPS图片链接（自动下载）；2. 服务端本地图片绝对路径（如:/Users/user/image.jpg）；3. data:image/png;base64,... 形式的 data URI；4. 上传接口返回的 media://<sha256> 句柄；5. MCP 内嵌资源 {type:resource, resource:{mimeType, blob}}。客户端与服务端不在同一台机器时请用 3-5"`
+	Tags            []string           `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
+	Mentions        []string           `json:"mentions,omitempty" jsonschema:"要 @ 的用户昵称列表（可选），追加在正文末尾并从联想列表选中；找不到的用户按普通文本输入并在结果中提示"`
+	ExactTags       bool               `json:"exact_tags,omitempty" jsonschema:"为 true 时标签只关联名称完全一致的话题，找不到则按普通文本输入并提示；默认选择联想列表第一项。实际关联的话题见结果 topics，可先用 search_topics 确认"`
+	Location        string             `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
+	ScheduleAt      string             `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
+	Draft           bool               `json:"draft,omitempty" jsonschema:"为 true 时只上传素材并存入草稿箱，不发布，供人工在创作者中心审核（需持久化 profile 或远程浏览器）"`
+	Visibility      string             `json:"visibility,omitempty" jsonschema:"可见范围（可选）：public 公开（默认）/private 仅自己可见/friends 仅互关好友可见"`
+	Original        bool               `json:"original,omitempty" jsonschema:"是否声明原创（可选）"`
+	Disclosure      string             `json:"disclosure,omitempty" jsonschema:"内容类型声明（可选）：ai_generated 含AI合成内容/fiction 虚构演绎/self_shot 自主拍摄/reposted 来源转载。AI 生成的内容必须声明 ai_generated"`
+	AllowCoCreate   *bool              `json:"allow_co_create,omitempty" jsonschema:"是否允许共创/合拍（可选，不传时保持账号默认）"`
+	Collection      string             `json:"collection,omitempty" jsonschema:"加入的合集名称（可选），合集需已在创作者中心创建"`
+	ImageProcessing *imageproc.Options `json:"image_processing,omitempty" jsonschema:"上传前的图片处理（可选）：WebP/HEIC/AVIF 转 JPEG、按 EXIF 自动旋转并去除 EXIF/GPS、适配 3:4/1:1/4:3 画幅、限制尺寸与文件大小。不填则原图上传"`
 }
 
-// PublishVideoArgs 发布视频的参数（仅支持本地单个视频文件）
+// PublishVideoArgs 发布视频的参数（单个视频文件，本地路径或 URL）
 type PublishVideoArgs struct {
-	User       *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
-	Title      string        `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
-	Content    string        `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
-	Video      string        `json:"video" jsonschema:"本地视频绝对路径（仅支持单个视频文件，如:/Users/user/video.mp4）"`
-	Tags       []string      `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
-	Location   string        `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
-	ScheduleAt string        `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
+	User          *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	Title         string        `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
+	Content       string        `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
+	Video         string        `json:"video" jsonschema:"单个视频文件：HTTP/HTTPS 链接（自动下载，支持断点续传）、本地绝对路径（如:/Users/user/video.mp4）或上传接口返回的 media://<sha256> 句柄。仅支持 H.264/H.265 编码的 MP4/MOV"`
+	Cover         string        `json:"cover,omitempty" jsonschema:"自定义封面图片（可选），HTTP/HTTPS 链接、本地绝对路径、data URI 或 media:// 句柄"`
+	CoverAt       string        `json:"cover_at,omitempty" jsonschema:"从视频截帧作为封面的时间点（可选，与 cover 二选一），如 3.5（秒）、00:03、1m2s"`
+	Tags          []string      `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
+	Mentions      []string      `json:"mentions,omitempty" jsonschema:"要 @ 的用户昵称列表（可选），追加在正文末尾并从联想列表选中；找不到的用户按普通文本输入并在结果中提示"`
+	ExactTags     bool          `json:"exact_tags,omitempty" jsonschema:"为 true 时标签只关联名称完全一致的话题，找不到则按普通文本输入并提示；默认选择联想列表第一项。实际关联的话题见结果 topics，可先用 search_topics 确认"`
+	Location      string        `json:"location,omitempty" jsonschema:"发布地点（可选）。示例：上海迪士尼度假区 / 北京·三里屯"`
+	ScheduleAt    string        `json:"schedule_at,omitempty" jsonschema:"定时发布时间（可选），ISO8601格式如 2024-01-20T10:30:00+08:00，支持1小时至14天内。不填则立即发布"`
+	Draft         bool          `json:"draft,omitempty" jsonschema:"为 true 时只上传素材并存入草稿箱，不发布，供人工在创作者中心审核（需持久化 profile 或远程浏览器）"`
+	Visibility    string        `json:"visibility,omitempty" jsonschema:"可见范围（可选）：public 公开（默认）/private 仅自己可见/friends 仅互关好友可见"`
+	Original      bool          `json:"original,omitempty" jsonschema:"是否声明原创（可选）"`
+	Disclosure    string        `json:"disclosure,omitempty" jsonschema:"内容类型声明（可选）：ai_generated 含AI合成内容/fiction 虚构演绎/self_shot 自主拍摄/reposted 来源转载。AI 生成的内容必须声明 ai_generated"`
+	AllowCoCreate *bool         `json:"allow_co_create,omitempty" jsonschema:"是否允许共创/合拍（可选，不传时保持账号默认）"`
+	Collection    string        `json:"collection,omitempty" jsonschema:"加入的合集名称（可选），合集需已在创作者中心创建"`
 }
 
 // SearchFeedsArgs 搜索内容的参数
@@ -82,7 +104,11 @@ type SearchFeedsBatchArgs struct {
 }
 
 type ListFeedsArgs struct {
-	User *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	User        *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	Channel     string        `json:"channel,omitempty" jsonschema:"发现页频道: 推荐|穿搭|美食|彩妆|影视|职场|情感|家居|游戏|旅行|健身（也可用英文 key 如 fashion/food 或频道 ID），默认为'推荐'"`
+	Count       int           `json:"count,omitempty" jsonschema:"需要获取的数量，通过滚动加载更多并按 ID 去重（最大 500）；不填只返回首屏"`
+	Cursor      string        `json:"cursor,omitempty" jsonschema:"上一次返回的 next_cursor，用于继续获取后面的内容"`
+	ScrollSpeed string        `json:"scroll_speed,omitempty" jsonschema:"滚动速度: slow|normal|fast，默认 normal"`
 }
 
 // FilterOption 筛选选项结构体
@@ -119,6 +145,7 @@ type PostCommentArgs struct {
 	FeedID    string        `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
 	XsecToken string        `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
 	Content   string        `json:"content" jsonschema:"评论内容"`
+	Mentions  []string      `json:"mentions,omitempty" jsonschema:"要 @ 的用户昵称列表（可选），追加在评论末尾；找不到的用户按普通文本输入并在结果中提示"`
 }
 
 // ReplyCommentArgs 回复评论的参数
@@ -129,6 +156,7 @@ type ReplyCommentArgs struct {
 	CommentID string        `json:"comment_id,omitempty" jsonschema:"目标评论ID，从评论列表获取"`
 	UserID    string        `json:"user_id,omitempty" jsonschema:"目标评论用户ID，从评论列表获取"`
 	Content   string        `json:"content" jsonschema:"回复内容"`
+	Mentions  []string      `json:"mentions,omitempty" jsonschema:"要 @ 的用户昵称列表（可选），追加在回复末尾；找不到的用户按普通文本输入并在结果中提示"`
 }
 
 // LikeFeedArgs 点赞参数
@@ -176,6 +204,55 @@ type BatchTaskRunSyncArgs struct {
 	PollIntervalMs int         `json:"poll_interval_ms,omitempty" jsonschema:"轮询间隔（毫秒），默认 500ms"`
 }
 
+// ImportCookiesArgs 导入 cookies 的参数
+type ImportCookiesArgs struct {
+	Account string `json:"account" jsonschema:"导入到的账号（users.json中的account），不存在时自动创建"`
+	Content string `json:"content" jsonschema:"cookies 内容：Netscape cookies.txt、EditThisCookie/Cookie-Editor 导出的 JSON，或 Cookie 请求头字符串（如 a1=xxx; web_session=xxx）"`
+	Format  string `json:"format,omitempty" jsonschema:"内容格式：auto（默认自动识别）/netscape/json/header"`
+}
+
+// ListMyNotesArgs 我的笔记列表参数
+type ListMyNotesArgs struct {
+	User   *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	Status string        `json:"status,omitempty" jsonschema:"按状态过滤：published/scheduled/reviewing/rejected，为空返回全部"`
+	Limit  int           `json:"limit,omitempty" jsonschema:"最多返回条数，默认只返回第一页"`
+}
+
+// MyNoteArgs 单条笔记参数
+type MyNoteArgs struct {
+	User   *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	NoteID string        `json:"note_id" jsonschema:"笔记ID，从 list_my_notes 或发布结果中获取"`
+}
+
+// ListDraftsArgs 草稿箱列表参数
+type ListDraftsArgs struct {
+	User *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+}
+
+// DraftArgs 单个草稿参数
+type DraftArgs struct {
+	User    *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	DraftID string        `json:"draft_id" jsonschema:"草稿ID，从 list_drafts 或 draft=true 的发布结果中获取"`
+}
+
+// EditNoteArgs 编辑笔记参数，未提供的字段保持不变
+type EditNoteArgs struct {
+	User       *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	NoteID     string        `json:"note_id" jsonschema:"笔记ID，从 list_my_notes 或发布结果中获取"`
+	Title      *string       `json:"title,omitempty" jsonschema:"新标题（小红书限制：最多20个中文字或英文单词）"`
+	Content    *string       `json:"content,omitempty" jsonschema:"新正文，会替换原正文；不传 tags 时保留原有话题"`
+	Tags       []string      `json:"tags,omitempty" jsonschema:"新话题标签，会替换原有话题，需要同时提供 content"`
+	Location   *string       `json:"location,omitempty" jsonschema:"新地点"`
+	ImageOrder []int         `json:"image_order,omitempty" jsonschema:"新的图片顺序，元素为原图片下标（从0开始），如 [2,0,1]"`
+	ExactTags  bool          `json:"exact_tags,omitempty" jsonschema:"为 true 时标签只关联名称完全一致的话题"`
+}
+
+// SearchTopicsArgs 话题搜索参数
+type SearchTopicsArgs struct {
+	User    *UserSelector `json:"user,omitempty" jsonschema:"可选用户选择器"`
+	Keyword string        `json:"keyword" jsonschema:"话题关键词，不需要带#"`
+}
+
 // InitMCPServer 初始化 MCP Server
 func InitMCPServer(appServer *AppServer) *mcp.Server {
 	// 创建 MCP Server
@@ -223,7 +300,17 @@ func withPanicRecovery[T any](
 			}
 		}()
 
-		return handler(ctx, req, args)
+		// 客户端可通过 X-XHS-Trace 请求头开启本次调用的网络追踪
+		trace := req != nil && req.Extra != nil && isTruthy(req.Extra.Header.Get(TraceHeader))
+		ctx = withRequestState(ctx, trace)
+
+		result, resp, err = handler(ctx, req, args)
+		if ids := requestArtifacts(ctx); len(ids) > 0 && result != nil {
+			result.Content = append(result.Content, &mcp.TextContent{
+				Text: "artifact_id: " + strings.Join(ids, ","),
+			})
+		}
+		return result, resp, err
 	}
 }
 
@@ -298,6 +385,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 		&mcp.Tool{
 			Name:        "publish_content",
 			Description: "发布小红书图文内容",
+			InputSchema: inputSchemaWithMedia[PublishContentArgs](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Publish Content",
 				DestructiveHint: boolPtr(true),
@@ -309,10 +397,21 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"user":        args.User,
 				"title":       args.Title,
 				"content":     args.Content,
-				"images":      convertStringsToInterfaces(args.Images),
+				"images":      convertStringsToInterfaces(mediaInputStrings(args.Images)),
 				"tags":        convertStringsToInterfaces(args.Tags),
+				"mentions":    convertStringsToInterfaces(args.Mentions),
+				"exact_tags":  args.ExactTags,
 				"location":    args.Location,
 				"schedule_at": args.ScheduleAt,
+				"draft":       args.Draft,
+				"options": xiaohongshu.PublishOptions{
+					Visibility:    args.Visibility,
+					Original:      args.Original,
+					Disclosure:    args.Disclosure,
+					AllowCoCreate: args.AllowCoCreate,
+					Collection:    args.Collection,
+				},
+				"image_processing": args.ImageProcessing,
 			}
 			result := appServer.handlePublishContent(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -324,6 +423,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 		&mcp.Tool{
 			Name:        "publish_content_batch",
 			Description: "批量发布小红书图文内容（按账号并发执行）",
+			InputSchema: inputSchemaWithMedia[PublishContentBatchArgs](),
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Publish Content Batch",
 				DestructiveHint: boolPtr(true),
@@ -339,7 +439,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "list_feeds",
-			Description: "获取首页 Feeds 列表",
+			Description: "获取首页/发现页频道的 Feeds 列表，支持按数量滚动加载与游标分页",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "List Feeds",
 				ReadOnlyHint: true,
@@ -466,6 +566,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"feed_id":    args.FeedID,
 				"xsec_token": args.XsecToken,
 				"content":    args.Content,
+				"mentions":   convertStringsToInterfaces(args.Mentions),
 			}
 			result := appServer.handlePostComment(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -497,6 +598,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"comment_id": args.CommentID,
 				"user_id":    args.UserID,
 				"content":    args.Content,
+				"mentions":   convertStringsToInterfaces(args.Mentions),
 			}
 			result := appServer.handleReplyComment(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -507,7 +609,7 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "publish_with_video",
-			Description: "发布小红书视频内容（仅支持本地单个视频文件）",
+			Description: "发布小红书视频内容（单个视频文件，支持本地路径或 URL）",
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Publish Video",
 				DestructiveHint: boolPtr(true),
@@ -519,9 +621,21 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 				"title":       args.Title,
 				"content":     args.Content,
 				"video":       args.Video,
+				"cover":       args.Cover,
+				"cover_at":    args.CoverAt,
 				"tags":        convertStringsToInterfaces(args.Tags),
+				"mentions":    convertStringsToInterfaces(args.Mentions),
+				"exact_tags":  args.ExactTags,
 				"location":    args.Location,
 				"schedule_at": args.ScheduleAt,
+				"draft":       args.Draft,
+				"options": xiaohongshu.PublishOptions{
+					Visibility:    args.Visibility,
+					Original:      args.Original,
+					Disclosure:    args.Disclosure,
+					AllowCoCreate: args.AllowCoCreate,
+					Collection:    args.Collection,
+				},
 			}
 			result := appServer.handlePublishVideo(ctx, argsMap)
 			return convertToMCPResult(result), nil, nil
@@ -640,7 +754,124 @@ func registerTools(server *mcp.Server, appServer *AppServer) {
 		}),
 	)
 
-	logrus.Infof("Registered %d MCP tools", 19)
+	// 工具 19: 导入 cookies
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "import_cookies",
+			Description: "导入浏览器导出的 cookies（cookies.txt / EditThisCookie / Cookie-Editor JSON / Cookie 请求头）并绑定到账号，无需扫码登录",
+			Annotations: &mcp.ToolAnnotations{Title: "Import Cookies", DestructiveHint: boolPtr(true)},
+		},
+		withPanicRecovery("import_cookies", func(ctx context.Context, req *mcp.CallToolRequest, args ImportCookiesArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleImportCookies(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 20: 我的笔记列表
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "list_my_notes",
+			Description: "列出账号已发布的笔记（创作者中心笔记管理），包含状态（已发布/定时/审核中/未通过）、可见范围与互动数据",
+			Annotations: &mcp.ToolAnnotations{Title: "List My Notes", ReadOnlyHint: true},
+		},
+		withPanicRecovery("list_my_notes", func(ctx context.Context, req *mcp.CallToolRequest, args ListMyNotesArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleListMyNotes(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 21: 查看我的笔记
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_my_note",
+			Description: "查看账号的单条笔记的状态、可见范围与互动数据，可用于确认审核结果",
+			Annotations: &mcp.ToolAnnotations{Title: "Get My Note", ReadOnlyHint: true},
+		},
+		withPanicRecovery("get_my_note", func(ctx context.Context, req *mcp.CallToolRequest, args MyNoteArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleGetMyNote(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 22: 删除我的笔记
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "delete_my_note",
+			Description: "删除账号已发布的笔记，删除后不可恢复",
+			Annotations: &mcp.ToolAnnotations{Title: "Delete My Note", DestructiveHint: boolPtr(true)},
+		},
+		withPanicRecovery("delete_my_note", func(ctx context.Context, req *mcp.CallToolRequest, args MyNoteArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleDeleteMyNote(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 23: 编辑笔记
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "edit_note",
+			Description: "编辑账号已发布的笔记：修改标题、正文、标签、地点或图片顺序，保留点赞评论等互动数据",
+			Annotations: &mcp.ToolAnnotations{Title: "Edit Note", DestructiveHint: boolPtr(false)},
+		},
+		withPanicRecovery("edit_note", func(ctx context.Context, req *mcp.CallToolRequest, args EditNoteArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleEditNote(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 24: 草稿箱列表
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "list_drafts",
+			Description: "列出账号创作者中心草稿箱中的草稿（draft=true 发布时保存的内容）",
+			Annotations: &mcp.ToolAnnotations{Title: "List Drafts", ReadOnlyHint: true},
+		},
+		withPanicRecovery("list_drafts", func(ctx context.Context, req *mcp.CallToolRequest, args ListDraftsArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleListDrafts(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 25: 发布草稿
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "publish_draft",
+			Description: "立即发布草稿箱中的草稿，返回笔记 ID、链接与 xsec_token",
+			Annotations: &mcp.ToolAnnotations{Title: "Publish Draft", DestructiveHint: boolPtr(true)},
+		},
+		withPanicRecovery("publish_draft", func(ctx context.Context, req *mcp.CallToolRequest, args DraftArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handlePublishDraft(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 26: 删除草稿
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "delete_draft",
+			Description: "删除草稿箱中的草稿，删除后不可恢复",
+			Annotations: &mcp.ToolAnnotations{Title: "Delete Draft", DestructiveHint: boolPtr(true)},
+		},
+		withPanicRecovery("delete_draft", func(ctx context.Context, req *mcp.CallToolRequest, args DraftArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleDeleteDraft(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	// 工具 27: 话题搜索
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "search_topics",
+			Description: "按关键词查询发布时的话题联想列表（含浏览量），用于确认标签会关联到哪个话题。会打开发布页并上传一张占位图以启用正文编辑器，不会发布或保存草稿",
+			Annotations: &mcp.ToolAnnotations{Title: "Search Topics", DestructiveHint: boolPtr(false)},
+		},
+		withPanicRecovery("search_topics", func(ctx context.Context, req *mcp.CallToolRequest, args SearchTopicsArgs) (*mcp.CallToolResult, any, error) {
+			result := appServer.handleSearchTopics(ctx, args)
+			return convertToMCPResult(result), nil, nil
+		}),
+	)
+
+	logrus.Infof("Registered %d MCP tools", 28)
 }
 
 // convertToMCPResult 将自定义的 MCPToolResult 转换为官方 SDK 的格式
@@ -682,3 +913,15 @@ func convertStringsToInterfaces(strs []string) []interface{} {
 	}
 	return result
 }
+
+// stringsFromArgs 辅助函数：从参数 map 中取出 []string（由 convertStringsToInterfaces 转换而来）
+func stringsFromArgs(args map[string]any, key string) []string {
+	items, _ := args[key].([]any)
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
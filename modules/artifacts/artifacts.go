@@ -0,0 +1,258 @@
+package artifacts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DirName 现场快照在数据目录下的子目录名。
+const DirName = "artifacts"
+
+// MetaFile 每个快照目录中的元信息文件名。
+const MetaFile = "meta.json"
+
+var (
+	ErrNotFound  = errors.New("artifact not found")
+	ErrInvalidID = errors.New("invalid artifact id")
+
+	idPattern = regexp.MustCompile(`^[0-9]{8}-[0-9]{6}-[0-9a-f]{8}$`)
+)
+
+// Meta 一次失败操作的现场信息。
+type Meta struct {
+	ID            string    `json:"id"`
+	Account       string    `json:"account,omitempty"`
+	Action        string    `json:"action,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	Trace         bool      `json:"trace,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	ConsoleErrors []string  `json:"console_errors,omitempty"`
+	NetworkErrors []string  `json:"network_errors,omitempty"`
+	Files         []string  `json:"files"`
+}
+
+// Retention 保留策略，超过 MaxAge 或超出 MaxCount 的旧快照会被删除；<=0 表示不限制。
+type Retention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+type Store struct {
+	root      string
+	retention Retention
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func NewStore(dataDir string, retention Retention) *Store {
+	return &Store{
+		root:      filepath.Join(dataDir, DirName),
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Artifact 正在写入的快照。
+type Artifact struct {
+	store *Store
+	dir   string
+	meta  Meta
+}
+
+// Create 新建快照目录，并按保留策略清理旧快照。
+func (s *Store) Create(account, action string) (*Artifact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	id := now.Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+	dir := filepath.Join(s.root, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s.pruneLocked(now)
+
+	return &Artifact{
+		store: s,
+		dir:   dir,
+		meta:  Meta{ID: id, Account: account, Action: action, CreatedAt: now, Files: []string{}},
+	}, nil
+}
+
+func (a *Artifact) ID() string {
+	return a.meta.ID
+}
+
+// Meta 返回可修改的元信息，调用 Finish 后写入磁盘。
+func (a *Artifact) Meta() *Meta {
+	return &a.meta
+}
+
+// WriteFile 写入快照文件，name 不允许包含路径。
+func (a *Artifact) WriteFile(name string, data []byte) error {
+	if name == "" || name != filepath.Base(name) || name == MetaFile {
+		return errors.New("invalid artifact file name")
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, name), data, 0644); err != nil {
+		return err
+	}
+	a.meta.Files = append(a.meta.Files, name)
+	return nil
+}
+
+// Finish 写入 meta.json。
+func (a *Artifact) Finish() error {
+	data, err := json.MarshalIndent(a.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.dir, MetaFile), data, 0644)
+}
+
+// Get 读取快照元信息。
+func (s *Store) Get(id string) (Meta, error) {
+	if !idPattern.MatchString(id) {
+		return Meta{}, ErrInvalidID
+	}
+	data, err := os.ReadFile(filepath.Join(s.root, id, MetaFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Meta{}, ErrNotFound
+		}
+		return Meta{}, err
+	}
+	var m Meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Meta{}, err
+	}
+	return m, nil
+}
+
+// FilePath 返回快照中文件的路径，只允许访问 meta 中登记过的文件。
+func (s *Store) FilePath(id, name string) (string, error) {
+	m, err := s.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if name == MetaFile {
+		return filepath.Join(s.root, id, MetaFile), nil
+	}
+	for _, f := range m.Files {
+		if f == name {
+			return filepath.Join(s.root, id, name), nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// List 按创建时间倒序返回所有快照。
+func (s *Store) List() ([]Meta, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Meta, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		m, err := s.Get(ids[i])
+		if err != nil {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// Prune 按保留策略清理旧快照，返回删除数量。
+func (s *Store) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pruneLocked(s.now())
+}
+
+func (s *Store) pruneLocked(now time.Time) int {
+	ids, err := s.ids()
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	keep := ids[:0]
+	for _, id := range ids {
+		t, err := time.ParseInLocation("20060102-150405", id[:15], now.Location())
+		if err == nil && s.retention.MaxAge > 0 && now.Sub(t) > s.retention.MaxAge {
+			if os.RemoveAll(filepath.Join(s.root, id)) == nil {
+				removed++
+			}
+			continue
+		}
+		keep = append(keep, id)
+	}
+	if s.retention.MaxCount > 0 && len(keep) > s.retention.MaxCount {
+		for _, id := range keep[:len(keep)-s.retention.MaxCount] {
+			if os.RemoveAll(filepath.Join(s.root, id)) == nil {
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// ids 返回按时间正序排列的快照 ID。
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() && idPattern.MatchString(e.Name()) {
+			ids = append(ids, e.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Error 携带快照 ID 的错误，Error() 中附带 artifact_id 方便调用方定位现场。
+type Error struct {
+	Err        error
+	ArtifactID string
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + " (artifact_id=" + e.ArtifactID + ")"
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IDFromError 从错误链中取出快照 ID。
+func IDFromError(err error) string {
+	var ae *Error
+	if errors.As(err, &ae) {
+		return ae.ArtifactID
+	}
+	return ""
+}
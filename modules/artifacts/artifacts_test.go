@@ -0,0 +1,69 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_CreateAndGet(t *testing.T) {
+	s := NewStore(t.TempDir(), Retention{})
+
+	a, err := s.Create("u1", "publish")
+	require.NoError(t, err)
+	require.NoError(t, a.WriteFile("page.html", []byte("<html></html>")))
+	require.Error(t, a.WriteFile("../x", []byte("x")))
+	a.Meta().URL = "https://creator.xiaohongshu.com/publish"
+	require.NoError(t, a.Finish())
+
+	m, err := s.Get(a.ID())
+	require.NoError(t, err)
+	require.Equal(t, "publish", m.Action)
+	require.Equal(t, []string{"page.html"}, m.Files)
+
+	p, err := s.FilePath(a.ID(), "page.html")
+	require.NoError(t, err)
+	data, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Equal(t, "<html></html>", string(data))
+
+	_, err = s.FilePath(a.ID(), "other.png")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get("../../etc")
+	require.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestStore_Retention(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.Local)
+	s := NewStore(t.TempDir(), Retention{MaxAge: 48 * time.Hour, MaxCount: 2})
+	s.now = func() time.Time { return now }
+
+	var ids []string
+	for _, d := range []time.Duration{-72 * time.Hour, -3 * time.Hour, -2 * time.Hour, -time.Hour} {
+		now = time.Date(2025, 1, 10, 12, 0, 0, 0, time.Local).Add(d)
+		a, err := s.Create("u1", "search")
+		require.NoError(t, err)
+		require.NoError(t, a.Finish())
+		ids = append(ids, a.ID())
+	}
+
+	now = time.Date(2025, 1, 10, 12, 0, 0, 0, time.Local)
+	s.Prune()
+	list, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, ids[3], list[0].ID)
+	require.Equal(t, ids[2], list[1].ID)
+}
+
+func TestIDFromError(t *testing.T) {
+	base := errors.New("boom")
+	err := &Error{Err: base, ArtifactID: "20250110-120000-deadbeef"}
+	require.ErrorIs(t, err, base)
+	require.Contains(t, err.Error(), "artifact_id=20250110-120000-deadbeef")
+	require.Equal(t, "20250110-120000-deadbeef", IDFromError(err))
+	require.Empty(t, IDFromError(base))
+}
@@ -0,0 +1,353 @@
+package browserpool
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed 池已关闭。
+var ErrClosed = errors.New("browser pool is closed")
+
+// Instance 池中的浏览器实例。
+type Instance interface {
+	// Ping 健康检查，返回错误说明实例已不可用
+	Ping() error
+	Close()
+}
+
+// Factory 创建新实例。
+type Factory func() (Instance, error)
+
+// Config 池配置。
+type Config struct {
+	// MaxSize 最多保留的实例数，超出时按 LRU 淘汰空闲实例
+	MaxSize int
+	// IdleTTL 空闲超过该时长的实例会被关闭
+	IdleTTL time.Duration
+	// SweepInterval 后台清理与健康检查的间隔
+	SweepInterval time.Duration
+}
+
+// Stats 池指标。
+type Stats struct {
+	Hits           int64 `json:"hits"`
+	Misses         int64 `json:"misses"`
+	Evictions      int64 `json:"evictions"`
+	Restarts       int64 `json:"restarts"`
+	HealthFailures int64 `json:"health_failures"`
+	Live           int   `json:"live"`
+	InUse          int   `json:"in_use"`
+}
+
+type entry struct {
+	key       string
+	signature string
+	inst      Instance
+	lastUsed  time.Time
+	inUse     bool
+	// checking 后台健康检查中，Acquire/Invalidate 需等待检查结束
+	checking bool
+	stale    bool
+	elem     *list.Element
+}
+
+// Pool 按 key（账号）缓存存活的浏览器实例。
+// 同一 key 同一时间只允许一个使用者，调用方需自行串行化（Runtime.AccountLock）。
+type Pool struct {
+	cfg Config
+
+	mu      sync.Mutex
+	checked *sync.Cond // 健康检查结束时广播
+	entries map[string]*entry
+	lru     *list.List // 头部为最近使用
+	stats   Stats
+
+	stop   chan struct{}
+	closed bool
+	now    func() time.Time
+}
+
+func New(cfg Config) *Pool {
+	if cfg.MaxSize < 1 {
+		cfg.MaxSize = 1
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = 10 * time.Minute
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = 30 * time.Second
+	}
+	p := &Pool{
+		cfg:     cfg,
+		entries: make(map[string]*entry),
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+		now:     time.Now,
+	}
+	p.checked = sync.NewCond(&p.mu)
+	go p.loop()
+	return p
+}
+
+// Acquire 取出 key 对应的实例。实例不存在、启动参数签名变化或健康检查失败时用 factory 重建。
+// 返回值 hit 表示复用了已有实例。
+func (p *Pool) Acquire(key, signature string, factory Factory) (inst Instance, hit bool, err error) {
+	p.mu.Lock()
+	e, ok := p.waitCheckedLocked(key)
+	if ok && !e.inUse && !e.stale && e.signature == signature {
+		e.inUse = true
+		p.mu.Unlock()
+
+		if err := e.inst.Ping(); err == nil {
+			p.mu.Lock()
+			e.lastUsed = p.now()
+			p.lru.MoveToFront(e.elem)
+			p.stats.Hits++
+			p.mu.Unlock()
+			return e.inst, true, nil
+		}
+
+		p.mu.Lock()
+		p.stats.HealthFailures++
+		p.stats.Restarts++
+		p.removeLocked(e)
+		p.mu.Unlock()
+		e.inst.Close()
+		p.mu.Lock()
+	} else if ok && !e.inUse {
+		// 签名变化或已失效，先关闭旧实例再重建，避免两个浏览器同时使用同一个 profile
+		p.removeLocked(e)
+		p.stats.Restarts++
+		p.mu.Unlock()
+		e.inst.Close()
+		p.mu.Lock()
+	}
+	p.stats.Misses++
+	victims := p.evictForLocked(1)
+	p.mu.Unlock()
+	closeAll(victims)
+
+	inst, err = factory()
+	if err != nil {
+		return nil, false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		go inst.Close()
+		return nil, false, ErrClosed
+	}
+	if old, ok := p.entries[key]; ok {
+		// 理论上不会发生（同一 key 已串行化），保守处理：旧实例失效
+		old.stale = true
+		if !old.inUse {
+			p.removeLocked(old)
+			go old.inst.Close()
+		}
+	}
+	e = &entry{key: key, signature: signature, inst: inst, lastUsed: p.now(), inUse: true}
+	e.elem = p.lru.PushFront(e)
+	p.entries[key] = e
+	return inst, false, nil
+}
+
+// Release 归还实例。broken 为 true（如 rod 会话丢失）时关闭实例，下次 Acquire 会重启。
+func (p *Pool) Release(key string, inst Instance, broken bool) {
+	p.mu.Lock()
+	e, ok := p.entries[key]
+	if !ok || e.inst != inst {
+		p.mu.Unlock()
+		inst.Close()
+		return
+	}
+	e.inUse = false
+	e.lastUsed = p.now()
+	if broken || e.stale || p.closed {
+		if broken {
+			p.stats.Restarts++
+		}
+		p.removeLocked(e)
+		p.mu.Unlock()
+		inst.Close()
+		return
+	}
+	victims := p.evictForLocked(0)
+	p.mu.Unlock()
+	closeAll(victims)
+}
+
+// Invalidate 使 key 的实例失效（如 cookies 被替换），空闲时立即关闭，使用中则在归还时关闭。
+func (p *Pool) Invalidate(key string) {
+	p.mu.Lock()
+	e, ok := p.waitCheckedLocked(key)
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	if e.inUse {
+		e.stale = true
+		p.mu.Unlock()
+		return
+	}
+	p.removeLocked(e)
+	p.mu.Unlock()
+	e.inst.Close()
+}
+
+// Stats 返回当前指标快照。
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Live = len(p.entries)
+	for _, e := range p.entries {
+		if e.inUse {
+			s.InUse++
+		}
+	}
+	return s
+}
+
+// Close 关闭所有空闲实例，使用中的实例在归还时关闭。
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.stop)
+	var victims []Instance
+	for _, e := range p.entries {
+		if e.inUse || e.checking {
+			continue
+		}
+		victims = append(victims, e.inst)
+		p.removeLocked(e)
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, inst := range victims {
+		wg.Add(1)
+		go func(inst Instance) {
+			defer wg.Done()
+			inst.Close()
+		}(inst)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) loop() {
+	t := time.NewTicker(p.cfg.SweepInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			p.sweep()
+		}
+	}
+}
+
+// sweep 关闭空闲超时的实例，并对其余空闲实例做健康检查。
+func (p *Pool) sweep() {
+	now := p.now()
+	var expired, idle []*entry
+
+	p.mu.Lock()
+	for _, e := range p.entries {
+		if e.inUse || e.checking {
+			continue
+		}
+		if now.Sub(e.lastUsed) > p.cfg.IdleTTL {
+			p.removeLocked(e)
+			p.stats.Evictions++
+			expired = append(expired, e)
+			continue
+		}
+		idle = append(idle, e)
+	}
+	p.mu.Unlock()
+
+	for _, e := range expired {
+		e.inst.Close()
+	}
+	for _, e := range idle {
+		p.mu.Lock()
+		if cur, ok := p.entries[e.key]; !ok || cur != e || e.inUse {
+			p.mu.Unlock()
+			continue
+		}
+		// 检查期间标记为检查中：Acquire 会等待检查结束，而不是另起一个浏览器
+		e.checking = true
+		p.mu.Unlock()
+
+		err := e.inst.Ping()
+
+		p.mu.Lock()
+		if err != nil || e.stale || p.closed {
+			if err != nil {
+				p.stats.HealthFailures++
+			}
+			p.removeLocked(e)
+			p.mu.Unlock()
+			// 关闭完成后再唤醒等待者，保证 profile 已释放
+			e.inst.Close()
+			p.mu.Lock()
+		}
+		e.checking = false
+		p.checked.Broadcast()
+		p.mu.Unlock()
+	}
+}
+
+// evictForLocked 为新增 n 个实例腾出空间，按 LRU 淘汰空闲实例，返回待关闭的实例。
+func (p *Pool) evictForLocked(n int) []Instance {
+	var victims []Instance
+	for el := p.lru.Back(); el != nil && len(p.entries)+n > p.cfg.MaxSize; {
+		prev := el.Prev()
+		e := el.Value.(*entry)
+		if !e.inUse && !e.checking {
+			p.removeLocked(e)
+			p.stats.Evictions++
+			victims = append(victims, e.inst)
+		}
+		el = prev
+	}
+	return victims
+}
+
+// waitCheckedLocked 等待 key 的实例结束健康检查后返回当前实例，调用时需持有 p.mu。
+func (p *Pool) waitCheckedLocked(key string) (*entry, bool) {
+	for {
+		e, ok := p.entries[key]
+		if !ok || !e.checking {
+			return e, ok
+		}
+		// 检查失败时实例会被移除，唤醒后重新读取
+		for e.checking {
+			p.checked.Wait()
+		}
+	}
+}
+
+func (p *Pool) removeLocked(e *entry) {
+	if cur, ok := p.entries[e.key]; ok && cur == e {
+		delete(p.entries, e.key)
+	}
+	if e.elem != nil {
+		p.lru.Remove(e.elem)
+		e.elem = nil
+	}
+}
+
+func closeAll(insts []Instance) {
+	for _, inst := range insts {
+		inst.Close()
+	}
+}
@@ -0,0 +1,184 @@
+package browserpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstance struct {
+	id      int
+	healthy atomic.Bool
+	closed  atomic.Bool
+	// block 非空时 Ping 阻塞到其关闭，模拟慢速健康检查
+	block chan struct{}
+}
+
+func (f *fakeInstance) Ping() error {
+	if f.block != nil {
+		<-f.block
+	}
+	if !f.healthy.Load() {
+		return errors.New("session with given id not found")
+	}
+	return nil
+}
+
+func (f *fakeInstance) Close() { f.closed.Store(true) }
+
+func newFactory(created *[]*fakeInstance) Factory {
+	return func() (Instance, error) {
+		inst := &fakeInstance{id: len(*created)}
+		inst.healthy.Store(true)
+		*created = append(*created, inst)
+		return inst, nil
+	}
+}
+
+func TestPool_ReuseAndMetrics(t *testing.T) {
+	p := New(Config{MaxSize: 2, IdleTTL: time.Hour, SweepInterval: time.Hour})
+	defer p.Close()
+	var created []*fakeInstance
+	f := newFactory(&created)
+
+	inst, hit, err := p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	require.False(t, hit)
+	p.Release("a", inst, false)
+
+	inst2, hit, err := p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	require.True(t, hit)
+	require.Same(t, inst, inst2)
+	p.Release("a", inst2, false)
+
+	st := p.Stats()
+	require.EqualValues(t, 1, st.Hits)
+	require.EqualValues(t, 1, st.Misses)
+	require.Equal(t, 1, st.Live)
+	require.Equal(t, 0, st.InUse)
+}
+
+func TestPool_RestartsOnBrokenUnhealthyAndSignatureChange(t *testing.T) {
+	p := New(Config{MaxSize: 2, IdleTTL: time.Hour, SweepInterval: time.Hour})
+	defer p.Close()
+	var created []*fakeInstance
+	f := newFactory(&created)
+
+	inst, _, err := p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	p.Release("a", inst, true)
+	require.True(t, created[0].closed.Load())
+
+	inst, hit, err := p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	require.False(t, hit)
+	p.Release("a", inst, false)
+
+	created[1].healthy.Store(false)
+	inst, hit, err = p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.True(t, created[1].closed.Load())
+	p.Release("a", inst, false)
+
+	_, hit, err = p.Acquire("a", "proxy-changed", f)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Len(t, created, 4)
+
+	st := p.Stats()
+	require.EqualValues(t, 3, st.Restarts)
+	require.EqualValues(t, 1, st.HealthFailures)
+}
+
+func TestPool_EvictsLRUAndIdle(t *testing.T) {
+	p := New(Config{MaxSize: 2, IdleTTL: time.Minute, SweepInterval: time.Hour})
+	defer p.Close()
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	var created []*fakeInstance
+	f := newFactory(&created)
+
+	for _, k := range []string{"a", "b"} {
+		inst, _, err := p.Acquire(k, "", f)
+		require.NoError(t, err)
+		p.Release(k, inst, false)
+	}
+	// 访问 a，使 b 成为最久未使用
+	inst, _, _ := p.Acquire("a", "", f)
+	p.Release("a", inst, false)
+
+	inst, _, err := p.Acquire("c", "", f)
+	require.NoError(t, err)
+	p.Release("c", inst, false)
+	require.True(t, created[1].closed.Load())
+	require.False(t, created[0].closed.Load())
+
+	now = now.Add(2 * time.Minute)
+	p.sweep()
+	st := p.Stats()
+	require.Equal(t, 0, st.Live)
+	require.EqualValues(t, 3, st.Evictions)
+}
+
+func TestPool_InvalidateInUseClosesOnRelease(t *testing.T) {
+	p := New(Config{MaxSize: 1, IdleTTL: time.Hour, SweepInterval: time.Hour})
+	defer p.Close()
+	var created []*fakeInstance
+	f := newFactory(&created)
+
+	inst, _, err := p.Acquire("a", "", f)
+	require.NoError(t, err)
+	p.Invalidate("a")
+	require.False(t, created[0].closed.Load())
+	p.Release("a", inst, false)
+	require.True(t, created[0].closed.Load())
+	require.Equal(t, 0, p.Stats().Live)
+}
+
+func TestPool_AcquireWaitsForHealthCheck(t *testing.T) {
+	p := New(Config{MaxSize: 2, IdleTTL: time.Hour, SweepInterval: time.Hour})
+	defer p.Close()
+	var created []*fakeInstance
+	f := newFactory(&created)
+
+	inst, _, err := p.Acquire("a", "sig", f)
+	require.NoError(t, err)
+	p.Release("a", inst, false)
+
+	created[0].block = make(chan struct{})
+	go p.sweep()
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.entries["a"].checking
+	}, time.Second, time.Millisecond)
+
+	type result struct {
+		inst Instance
+		hit  bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		inst, hit, err := p.Acquire("a", "sig", f)
+		require.NoError(t, err)
+		done <- result{inst, hit}
+	}()
+
+	// 检查期间不会另起一个浏览器
+	select {
+	case <-done:
+		t.Fatal("acquire should wait for the health check")
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(created[0].block)
+
+	res := <-done
+	require.True(t, res.hit)
+	require.Same(t, inst, res.inst)
+	require.Len(t, created, 1)
+}
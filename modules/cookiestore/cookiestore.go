@@ -1,20 +1,37 @@
 package cookiestore
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
 )
 
 type Store struct {
 	dataDir string
+	backend cookies.Backend
 }
 
 func NewStore(dataDir string) *Store {
-	return &Store{dataDir: dataDir}
+	return NewStoreWithBackend(dataDir, nil)
+}
+
+// NewStoreWithBackend 使用指定存储后端，backend 为 nil 时使用文件后端。
+func NewStoreWithBackend(dataDir string, backend cookies.Backend) *Store {
+	if backend == nil {
+		backend = cookies.NewFileBackend()
+	}
+	return &Store{dataDir: dataDir, backend: backend}
+}
+
+// BackendName 当前存储后端名称。
+func (s *Store) BackendName() string {
+	return s.backend.Name()
 }
 
 func (s *Store) CookiePathFor(account string, cookieFileHint string) (absPath string, relPath string) {
@@ -48,7 +65,56 @@ func (s *Store) CookiePathFor(account string, cookieFileHint string) (absPath st
 	return defaultAbs, defaultRel
 }
 
+// CookierFor 返回账号对应的 Cookier，以及应写入 users.json cookie_file 的引用。
+// 文件后端沿用 CookiePathFor 的路径规则；其它后端以账号名为 key，引用为空。
+func (s *Store) CookierFor(account string, cookieFileHint string) (cookies.Cookier, string) {
+	if s.backend.Name() == cookies.BackendFile {
+		abs, rel := s.CookiePathFor(account, cookieFileHint)
+		_ = s.EnsureDir(abs)
+		return s.backend.Open(abs), rel
+	}
+	return s.backend.Open(accountKey(account)), ""
+}
+
+// Location 返回账号 cookies 的存储位置描述。
+func (s *Store) Location(account string, cookieFileHint string) string {
+	if s.backend.Name() == cookies.BackendFile {
+		abs, _ := s.CookiePathFor(account, cookieFileHint)
+		return abs
+	}
+	return s.backend.Location(accountKey(account))
+}
+
+// Import 将导入的 cookies 写入账号存储，返回应写入 users.json 的 cookie_file 引用。
+func (s *Store) Import(account string, cookieFileHint string, cks []*proto.NetworkCookie) (string, error) {
+	data, err := json.Marshal(cks)
+	if err != nil {
+		return "", err
+	}
+	c, ref := s.CookierFor(account, cookieFileHint)
+	if err := c.SaveCookies(data); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
 func (s *Store) EnsureDir(absPath string) error {
 	dir := filepath.Dir(absPath)
 	return os.MkdirAll(dir, 0755)
 }
+
+// Close 释放后端占用的资源（如 bolt 数据库文件锁）。
+func (s *Store) Close() error {
+	if c, ok := s.backend.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func accountKey(account string) string {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return "default"
+	}
+	return account
+}
@@ -0,0 +1,266 @@
+package profilestore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
+)
+
+// DirName 持久化 profile 在数据目录下的子目录名。
+const DirName = "profiles"
+
+// Chrome 单实例锁文件，进程异常退出后会残留并导致下次启动失败。
+var singletonFiles = []string{"SingletonLock", "SingletonSocket", "SingletonCookie"}
+
+// ErrProfileInUse profile 正被其他 Chrome 进程使用，两个 Chrome 共用一个 profile 会损坏数据。
+var ErrProfileInUse = errors.New("profile is in use by another browser")
+
+// 可安全删除的缓存目录，不影响 localStorage / IndexedDB / cookies。
+var cacheDirs = []string{
+	filepath.Join("Default", "Cache"),
+	filepath.Join("Default", "Code Cache"),
+	filepath.Join("Default", "GPUCache"),
+	filepath.Join("Default", "Service Worker", "CacheStorage"),
+	"GrShaderCache",
+	"ShaderCache",
+	"GraphiteDawnCache",
+}
+
+type Store struct {
+	root string
+}
+
+// Profile 一个账号的 profile 目录信息。
+type Profile struct {
+	Name      string    `json:"name"`
+	Dir       string    `json:"dir"`
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time"`
+	// InUse profile 正被运行中的 Chrome 打开，清理时会跳过
+	InUse bool `json:"in_use"`
+}
+
+// ErrNoCleanupCondition 没有指定任何清理条件。清理会删除登录态与草稿，清理全部 profile 必须显式指定 All
+var ErrNoCleanupCondition = errors.New("未指定清理条件，清理全部 profile 需要显式指定 all")
+
+// CleanupOptions 清理条件，Accounts/OlderThan/Orphans 之间为“或”关系；全部为空时必须指定 All。
+type CleanupOptions struct {
+	// All 清理所有 profile
+	All       bool
+	Accounts  []string
+	OlderThan time.Duration
+	// Orphans 清理不在 KnownAccounts 中的 profile
+	Orphans       bool
+	KnownAccounts []string
+	// CacheOnly 只删除缓存目录，保留登录态与本地存储
+	CacheOnly bool
+	DryRun    bool
+}
+
+func NewStore(dataDir string) *Store {
+	return &Store{root: filepath.Join(dataDir, DirName)}
+}
+
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Dir 返回账号的 user-data-dir 路径。
+func (s *Store) Dir(account string) string {
+	return filepath.Join(s.root, userpool.SafeAccount(account))
+}
+
+// Prepare 创建账号的 user-data-dir 并清除残留的单实例锁，返回目录的绝对路径。
+// 只清除持有进程已退出的锁；profile 仍被其他 Chrome（常驻池、扫码登录、cmd/login）
+// 打开时返回 ErrProfileInUse。
+func (s *Store) Prepare(account string) (string, error) {
+	dir, err := filepath.Abs(s.Dir(account))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := clearStaleLocks(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// clearStaleLocks 只有确认是本机且进程已退出时才删除单实例锁。
+func clearStaleLocks(dir string) error {
+	// 没有锁时残留的 socket/cookie 链接同样不属于任何进程
+	if _, err := checkLock(dir); err != nil {
+		return err
+	}
+	for _, name := range singletonFiles {
+		_ = os.Remove(filepath.Join(dir, name))
+	}
+	return nil
+}
+
+// checkLock SingletonLock 是指向 "<hostname>-<pid>" 的符号链接。没有锁时返回 false；
+// 锁属于已退出的本机进程时返回 true；锁无法识别、属于其他主机或进程仍存活时返回 ErrProfileInUse。
+func checkLock(dir string) (bool, error) {
+	lock := filepath.Join(dir, "SingletonLock")
+	if _, err := os.Lstat(lock); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	target, err := os.Readlink(lock)
+	if err != nil {
+		return true, fmt.Errorf("%w: 无法识别锁文件 %s: %v", ErrProfileInUse, lock, err)
+	}
+	host, pid, ok := parseLockTarget(target)
+	if !ok {
+		return true, fmt.Errorf("%w: 无法识别锁文件 %s -> %s", ErrProfileInUse, lock, target)
+	}
+	if hostname, _ := os.Hostname(); host != hostname {
+		return true, fmt.Errorf("%w: 被主机 %s 的进程 %d 占用，确认已退出后手动删除 %s", ErrProfileInUse, host, pid, lock)
+	}
+	if processAlive(pid) {
+		return true, fmt.Errorf("%w: 被进程 %d 占用 (%s)", ErrProfileInUse, pid, dir)
+	}
+	return true, nil
+}
+
+// inUse profile 是否被运行中的 Chrome 打开，无法确认锁已失效时同样视为使用中
+func inUse(dir string) bool {
+	_, err := checkLock(dir)
+	return err != nil
+}
+
+func parseLockTarget(target string) (string, int, bool) {
+	i := strings.LastIndex(target, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	pid, err := strconv.Atoi(target[i+1:])
+	if err != nil || pid <= 0 {
+		return "", 0, false
+	}
+	return target[:i], pid, true
+}
+
+// processAlive 向进程发送 0 号信号探测是否存活，无权限时同样视为存活
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// List 列出所有 profile。
+func (s *Store) List() ([]Profile, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []Profile
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(s.root, e.Name())
+		size, mod := dirUsage(dir)
+		out = append(out, Profile{Name: e.Name(), Dir: dir, SizeBytes: size, ModTime: mod, InUse: inUse(dir)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Remove 删除账号的 profile。
+func (s *Store) Remove(account string) error {
+	return os.RemoveAll(s.Dir(account))
+}
+
+// Cleanup 按条件清理 profile，返回匹配条件的 profile（DryRun 时为将被清理的）。
+// 正被 Chrome 使用的 profile 不会被删除，返回时 InUse 为 true。
+func (s *Store) Cleanup(opts CleanupOptions) ([]Profile, error) {
+	matchAll := opts.All
+	if !matchAll && len(opts.Accounts) == 0 && opts.OlderThan <= 0 && !opts.Orphans {
+		return nil, ErrNoCleanupCondition
+	}
+
+	profiles, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(opts.Accounts))
+	for _, a := range opts.Accounts {
+		selected[userpool.SafeAccount(a)] = true
+	}
+	known := make(map[string]bool, len(opts.KnownAccounts))
+	for _, a := range opts.KnownAccounts {
+		known[userpool.SafeAccount(a)] = true
+	}
+	now := time.Now()
+
+	var removed []Profile
+	for _, p := range profiles {
+		match := matchAll ||
+			selected[p.Name] ||
+			(opts.OlderThan > 0 && now.Sub(p.ModTime) > opts.OlderThan) ||
+			(opts.Orphans && !known[p.Name])
+		if !match {
+			continue
+		}
+		removed = append(removed, p)
+		if opts.DryRun || p.InUse {
+			continue
+		}
+		if opts.CacheOnly {
+			for _, c := range cacheDirs {
+				if err := os.RemoveAll(filepath.Join(p.Dir, c)); err != nil {
+					return removed, err
+				}
+			}
+			continue
+		}
+		if err := os.RemoveAll(p.Dir); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// dirUsage 统计目录大小与最近修改时间。
+func dirUsage(dir string) (int64, time.Time) {
+	var (
+		size int64
+		mod  time.Time
+	)
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(mod) {
+			mod = info.ModTime()
+		}
+		if !d.IsDir() && !strings.HasPrefix(d.Name(), "Singleton") {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, mod
+}
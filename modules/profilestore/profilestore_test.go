@@ -0,0 +1,110 @@
+package profilestore
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_PrepareRemovesStaleLocks(t *testing.T) {
+	s := NewStore(t.TempDir())
+	dir, err := s.Prepare("a/b")
+	require.NoError(t, err)
+	require.Equal(t, "a_b", filepath.Base(dir))
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	lock := filepath.Join(dir, "SingletonLock")
+
+	// 进程已退出的锁被清除
+	require.NoError(t, os.Symlink(host+"-999999999", lock))
+	require.NoError(t, os.Symlink("/tmp/gone/SingletonSocket", filepath.Join(dir, "SingletonSocket")))
+	_, err = s.Prepare("a/b")
+	require.NoError(t, err)
+	_, err = os.Lstat(lock)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Lstat(filepath.Join(dir, "SingletonSocket"))
+	require.True(t, os.IsNotExist(err))
+
+	// 仍在运行的进程持有的锁保留
+	require.NoError(t, os.Symlink(host+"-"+strconv.Itoa(os.Getpid()), lock))
+	_, err = s.Prepare("a/b")
+	require.ErrorIs(t, err, ErrProfileInUse)
+	_, err = os.Lstat(lock)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(lock))
+
+	// 其他主机的锁无法确认，不删除
+	require.NoError(t, os.Symlink("other-host-1", lock))
+	_, err = s.Prepare("a/b")
+	require.ErrorIs(t, err, ErrProfileInUse)
+}
+
+func TestStore_CleanupOrphansAndCacheOnly(t *testing.T) {
+	s := NewStore(t.TempDir())
+	for _, a := range []string{"u1", "u2", "gone"} {
+		dir, err := s.Prepare(a)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "Default", "Cache"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Default", "Cache", "data"), []byte("x"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Default", "Preferences"), []byte("{}"), 0644))
+	}
+
+	removed, err := s.Cleanup(CleanupOptions{Orphans: true, KnownAccounts: []string{"u1", "u2"}, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	require.Equal(t, "gone", removed[0].Name)
+	_, err = os.Stat(s.Dir("gone"))
+	require.NoError(t, err)
+
+	_, err = s.Cleanup(CleanupOptions{Orphans: true, KnownAccounts: []string{"u1", "u2"}})
+	require.NoError(t, err)
+	_, err = os.Stat(s.Dir("gone"))
+	require.True(t, os.IsNotExist(err))
+
+	removed, err = s.Cleanup(CleanupOptions{Accounts: []string{"u1"}, CacheOnly: true})
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	_, err = os.Stat(filepath.Join(s.Dir("u1"), "Default", "Cache"))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(s.Dir("u1"), "Default", "Preferences"))
+	require.NoError(t, err)
+
+	removed, err = s.Cleanup(CleanupOptions{OlderThan: time.Hour})
+	require.NoError(t, err)
+	require.Empty(t, removed)
+
+	profiles, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+}
+
+func TestStore_CleanupRequiresConditionAndSkipsLiveProfiles(t *testing.T) {
+	s := NewStore(t.TempDir())
+	for _, a := range []string{"u1", "u2"} {
+		_, err := s.Prepare(a)
+		require.NoError(t, err)
+	}
+
+	// 不带任何条件时不清理
+	_, err := s.Cleanup(CleanupOptions{})
+	require.ErrorIs(t, err, ErrNoCleanupCondition)
+
+	// 正在运行的 Chrome 持有锁的 profile 跳过
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	require.NoError(t, os.Symlink(host+"-"+strconv.Itoa(os.Getpid()), filepath.Join(s.Dir("u1"), "SingletonLock")))
+
+	removed, err := s.Cleanup(CleanupOptions{All: true})
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	require.True(t, removed[0].InUse)
+	_, err = os.Stat(s.Dir("u1"))
+	require.NoError(t, err)
+	_, err = os.Stat(s.Dir("u2"))
+	require.True(t, os.IsNotExist(err))
+}
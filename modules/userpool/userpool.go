@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
 )
 
 type User struct {
@@ -15,6 +17,15 @@ type User struct {
 	CookieFile string `json:"cookie_file,omitempty"`
 	IPRef      any    `json:"ip_ref,omitempty"`
 	Enabled    bool   `json:"enabled"`
+
+	// RemoteCDP 账号专用的远程浏览器 DevTools 端点，优先于全局配置
+	RemoteCDP string `json:"remote_cdp,omitempty"`
+
+	// Trace 记录该账号所有浏览器操作的网络流量（HAR）
+	Trace bool `json:"trace,omitempty"`
+
+	// Fingerprint 账号固定的浏览器指纹，首次使用时生成
+	Fingerprint *fingerprint.Profile `json:"fingerprint,omitempty"`
 }
 
 type UserFile struct {
@@ -28,6 +39,7 @@ type UserSummary struct {
 	Enabled    bool   `json:"enabled"`
 	CookieFile string `json:"cookie_file,omitempty"`
 	IPRef      any    `json:"ip_ref,omitempty"`
+	RemoteCDP  string `json:"remote_cdp,omitempty"`
 }
 
 type Manager struct {
@@ -65,6 +77,7 @@ func (m *Manager) ListSummaries() []UserSummary {
 			Enabled:    u.Enabled,
 			CookieFile: u.CookieFile,
 			IPRef:      u.IPRef,
+			RemoteCDP:  u.RemoteCDP,
 		})
 	}
 	return out
@@ -191,6 +204,29 @@ func (m *Manager) UpsertCookie(account string, cookieFile string) (User, error)
 	return u, err
 }
 
+// EnsureFingerprint 返回账号的浏览器指纹，不存在时用 gen 生成并写回 users.json。
+// 账号不在 users.json 中时只返回生成结果，不创建用户。
+func (m *Manager) EnsureFingerprint(account string, gen func() fingerprint.Profile) (fingerprint.Profile, error) {
+	if account == "" {
+		account = "default"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.f.Users {
+		if m.f.Users[i].Account != account {
+			continue
+		}
+		if fp := m.f.Users[i].Fingerprint; fp != nil && !fp.IsZero() {
+			return *fp, nil
+		}
+		fp := gen()
+		m.f.Users[i].Fingerprint = &fp
+		return fp, m.saveLocked()
+	}
+	return gen(), nil
+}
+
 func (m *Manager) load() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
 )
 
 func TestManager_EnabledAccounts_OrderPreserved(t *testing.T) {
@@ -61,3 +62,36 @@ func TestManager_IndexOfAccount_DefaultFallback(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, 0, idx)
 }
+
+func TestManager_EnsureFingerprint_GeneratedOnceAndPersisted(t *testing.T) {
+	tempDir := t.TempDir()
+	data := []byte(`{"version":1,"users":[{"account":"u1","enabled":true}]}`)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "users.json"), data, 0644))
+
+	m, err := NewManager(tempDir)
+	require.NoError(t, err)
+
+	calls := 0
+	gen := func() fingerprint.Profile {
+		calls++
+		return fingerprint.Generate("u1")
+	}
+	fp, err := m.EnsureFingerprint("u1", gen)
+	require.NoError(t, err)
+	fp2, err := m.EnsureFingerprint("u1", gen)
+	require.NoError(t, err)
+	require.Equal(t, fp, fp2)
+	require.Equal(t, 1, calls)
+
+	reloaded, err := NewManager(tempDir)
+	require.NoError(t, err)
+	u, err := reloaded.Resolve("u1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, u.Fingerprint)
+	require.Equal(t, fp.UserAgent, u.Fingerprint.UserAgent)
+
+	_, err = m.EnsureFingerprint("unknown", gen)
+	require.NoError(t, err)
+	_, err = reloaded.Resolve("unknown", nil)
+	require.Error(t, err)
+}
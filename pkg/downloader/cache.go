@@ -0,0 +1,798 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+const (
+	// cacheBlobsDir 缓存内容文件的子目录，文件名为 <sha256>.<ext>
+	cacheBlobsDir = "blobs"
+	// cacheIndexFile 缓存索引文件名
+	cacheIndexFile = "index.json"
+	// cacheLockFile 跨进程读写索引时加锁的文件
+	cacheLockFile = "index.lock"
+	// cachePinsDir 各进程登记正在使用的文件的目录，每个进程一个文件，进程存活期间持有该文件的锁
+	cachePinsDir = "pins"
+)
+
+// errFileLocked 文件锁已被其他进程持有
+var errFileLocked = errors.New("file is locked")
+
+var (
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	extPattern    = regexp.MustCompile(`^[0-9a-z]{1,8}$`)
+)
+
+// CacheEntry 缓存中的一份内容，多个 URL 下载到相同内容时共用一个文件
+type CacheEntry struct {
+	SHA256 string   `json:"sha256"`
+	Ext    string   `json:"ext"`
+	Size   int64    `json:"size"`
+	URLs   []string `json:"urls,omitempty"`
+	// Kind 通过上传接口或 data URI 保存的内容的媒体类型（image/video）
+	Kind string `json:"kind,omitempty"`
+	// Upload 上传的内容只按 TTL 过期，不参与按大小淘汰
+	Upload     bool      `json:"upload,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// CachePolicy 缓存保留策略：超过 TTL 未使用或总大小超过 MaxBytes 时淘汰；<=0 表示不限制
+type CachePolicy struct {
+	TTL      time.Duration
+	MaxBytes int64
+}
+
+// CacheStats 缓存概况
+type CacheStats struct {
+	Root     string        `json:"root"`
+	Entries  int           `json:"entries"`
+	URLs     int           `json:"urls"`
+	Bytes    int64         `json:"bytes"`
+	TTL      time.Duration `json:"ttl"`
+	MaxBytes int64         `json:"max_bytes"`
+}
+
+// CacheCleanupOptions 手动清理参数，零值时使用缓存自身的策略
+type CacheCleanupOptions struct {
+	// OlderThan 清理超过该时长未使用的内容
+	OlderThan time.Duration `json:"older_than,omitempty"`
+	// MaxBytes 清理后缓存总大小不超过该值
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// All 清空全部缓存
+	All bool `json:"all,omitempty"`
+	// DryRun 只返回将被清理的内容，不实际删除
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// CacheCleanupResult 清理结果
+type CacheCleanupResult struct {
+	Removed      []CacheEntry `json:"removed"`
+	RemovedBytes int64        `json:"removed_bytes"`
+	// Files 缓存目录中被清理的其他文件（处理后的图片、未完成的视频等）
+	Files     []string `json:"files,omitempty"`
+	FileBytes int64    `json:"file_bytes"`
+	DryRun    bool     `json:"dry_run,omitempty"`
+}
+
+// Cache 按内容寻址的下载缓存：内容以 SHA256 命名保存，索引记录 URL 与内容的对应关系，
+// 同一个 URL 或相同内容只下载、保存一次。
+type Cache struct {
+	root   string
+	policy CachePolicy
+
+	mu       sync.Mutex
+	entries  map[string]*CacheEntry
+	urls     map[string]string
+	indexMod time.Time
+	loaded   bool
+	inflight map[string]*cacheCall
+	// pins 正在使用的文件（排队中的批量帖子、进行中的发布）的引用计数，按绝对路径记录
+	pins map[string]int
+	// pinFile 本进程登记 pins 的文件，otherPins 为其他存活进程登记的文件
+	pinFile   *os.File
+	otherPins map[string]bool
+	lockFile  *os.File
+
+	now func() time.Time
+}
+
+type cacheCall struct {
+	wg   sync.WaitGroup
+	path string
+	err  error
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = map[string]*Cache{}
+)
+
+// OpenCache 打开 root 目录下的缓存，同一目录在进程内共用一个实例
+func OpenCache(root string, policy CachePolicy) *Cache {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	if c, ok := caches[root]; ok {
+		c.mu.Lock()
+		c.policy = policy
+		c.mu.Unlock()
+		return c
+	}
+	c := newCache(root, policy)
+	caches[root] = c
+	return c
+}
+
+func newCache(root string, policy CachePolicy) *Cache {
+	return &Cache{
+		root:     root,
+		policy:   policy,
+		inflight: map[string]*cacheCall{},
+		pins:     map[string]int{},
+		now:      time.Now,
+	}
+}
+
+// DefaultCache 按配置打开全局数据目录下的下载缓存
+func DefaultCache() *Cache {
+	return DataDirCache(configs.GetDataDir())
+}
+
+// DataDirCache 按配置打开 dataDir 下的下载缓存
+func DataDirCache(dataDir string) *Cache {
+	return OpenCache(configs.MediaCachePathIn(dataDir), CachePolicy{
+		TTL:      configs.GetMediaCacheTTL(),
+		MaxBytes: configs.GetMediaCacheMaxBytes(),
+	})
+}
+
+func (c *Cache) Root() string {
+	return c.root
+}
+
+func (c *Cache) blobPath(sha, ext string) string {
+	return filepath.Join(c.root, cacheBlobsDir, sha+"."+ext)
+}
+
+// managedDirs 缓存自己管理的子目录，清理时只会删除这些目录中的文件
+func (c *Cache) managedDirs() []string {
+	return []string{
+		filepath.Join(c.root, cacheBlobsDir),
+		filepath.Join(c.root, configs.ImagesDir),
+		filepath.Join(c.root, configs.VideosDir),
+		filepath.Join(c.root, uploadsDir),
+	}
+}
+
+// Pin 标记 paths 中位于缓存目录内的文件正在使用，在返回的 release 调用前不会被淘汰或清理；
+// 缓存目录之外的路径忽略。用于排队中的批量帖子与进行中的发布；
+// pin 同时登记到 pins 目录，cleanup 命令等其他进程同样不会清理这些文件
+func (c *Cache) Pin(paths ...string) (release func()) {
+	unlock, err := c.lock()
+	if err != nil {
+		// 拿不到跨进程锁时仍在本进程内保护这些文件
+		logrus.Warnf("锁定缓存索引失败: %v", err)
+		c.mu.Lock()
+		unlock = c.mu.Unlock
+	}
+	defer unlock()
+	_ = c.loadLocked()
+
+	now := c.now()
+	var pinned []string
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(c.root, abs); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		c.pins[abs]++
+		pinned = append(pinned, abs)
+		// 同时刷新使用时间，避免其他进程（如 cleanup 命令）按 TTL 清理
+		if e := c.entryForPathLocked(abs); e != nil {
+			e.LastUsedAt = now
+		}
+	}
+	if len(pinned) > 0 {
+		c.savePinsLocked()
+		_ = c.saveLocked()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unlock, err := c.lock()
+			if err != nil {
+				logrus.Warnf("锁定缓存索引失败: %v", err)
+				c.mu.Lock()
+				unlock = c.mu.Unlock
+			}
+			defer unlock()
+			_ = c.loadLocked()
+			for _, p := range pinned {
+				if c.pins[p]--; c.pins[p] <= 0 {
+					delete(c.pins, p)
+				}
+				if e := c.entryForPathLocked(p); e != nil {
+					e.LastUsedAt = c.now()
+				}
+			}
+			if len(pinned) > 0 {
+				c.savePinsLocked()
+				_ = c.saveLocked()
+			}
+		})
+	}
+}
+
+// entryForPathLocked 返回 blobs 目录中 path 对应的缓存条目
+func (c *Cache) entryForPathLocked(path string) *CacheEntry {
+	if filepath.Dir(path) != filepath.Join(c.root, cacheBlobsDir) {
+		return nil
+	}
+	name := filepath.Base(path)
+	e := c.entries[strings.TrimSuffix(name, filepath.Ext(name))]
+	if e == nil || c.blobPath(e.SHA256, e.Ext) != path {
+		return nil
+	}
+	return e
+}
+
+func (c *Cache) pinnedLocked(e *CacheEntry) bool {
+	return c.pathPinnedLocked(c.blobPath(e.SHA256, e.Ext))
+}
+
+// pathPinnedLocked path 是否被本进程或其他存活进程使用
+func (c *Cache) pathPinnedLocked(path string) bool {
+	return c.pins[path] > 0 || c.otherPins[path]
+}
+
+// lock 加进程内锁与跨进程的索引文件锁，并读取其他进程登记的正在使用的文件；
+// cleanup 命令等其他进程与服务共用同一个缓存目录
+func (c *Cache) lock() (unlock func(), err error) {
+	c.mu.Lock()
+	if c.lockFile == nil {
+		if err := os.MkdirAll(c.root, 0755); err != nil {
+			c.mu.Unlock()
+			return nil, errors.Wrap(err, "创建缓存目录失败")
+		}
+		f, err := os.OpenFile(filepath.Join(c.root, cacheLockFile), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, errors.Wrap(err, "打开缓存锁文件失败")
+		}
+		c.lockFile = f
+	}
+	if err := lockFile(c.lockFile, true); err != nil {
+		c.mu.Unlock()
+		return nil, errors.Wrap(err, "锁定缓存索引失败")
+	}
+	c.loadOtherPinsLocked()
+	return func() {
+		_ = unlockFile(c.lockFile)
+		c.mu.Unlock()
+	}, nil
+}
+
+// loadOtherPinsLocked 读取其他进程登记的正在使用的文件；登记文件的锁能被拿到说明其进程已退出，直接删除
+func (c *Cache) loadOtherPinsLocked() {
+	c.otherPins = map[string]bool{}
+	dir := filepath.Join(c.root, cachePinsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, d := range entries {
+		path := filepath.Join(dir, d.Name())
+		if !d.Type().IsRegular() || (c.pinFile != nil && c.pinFile.Name() == path) {
+			continue
+		}
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			continue
+		}
+		if err := lockFile(f, false); err == nil {
+			f.Close()
+			os.Remove(path)
+			continue
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		var paths []string
+		if err != nil || json.Unmarshal(data, &paths) != nil {
+			continue
+		}
+		for _, p := range paths {
+			c.otherPins[p] = true
+		}
+	}
+}
+
+// savePinsLocked 把本进程的 pins 写入登记文件，没有 pin 时删除登记文件
+func (c *Cache) savePinsLocked() {
+	if len(c.pins) == 0 {
+		if c.pinFile != nil {
+			_ = unlockFile(c.pinFile)
+			c.pinFile.Close()
+			os.Remove(c.pinFile.Name())
+			c.pinFile = nil
+		}
+		return
+	}
+	if c.pinFile == nil {
+		dir := filepath.Join(c.root, cachePinsDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logrus.Warnf("创建缓存 pin 目录失败: %v", err)
+			return
+		}
+		f, err := os.CreateTemp(dir, fmt.Sprintf("%d-*.json", os.Getpid()))
+		if err != nil {
+			logrus.Warnf("创建缓存 pin 文件失败: %v", err)
+			return
+		}
+		if err := lockFile(f, false); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			logrus.Warnf("锁定缓存 pin 文件失败: %v", err)
+			return
+		}
+		c.pinFile = f
+	}
+	paths := make([]string, 0, len(c.pins))
+	for p := range c.pins {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	data, _ := json.Marshal(paths)
+	err := c.pinFile.Truncate(0)
+	if err == nil {
+		_, err = c.pinFile.WriteAt(data, 0)
+	}
+	if err != nil {
+		logrus.Warnf("保存缓存 pin 文件失败: %v", err)
+	}
+}
+
+// Lookup 按 URL 查找缓存，命中时返回本地文件路径并刷新使用时间
+func (c *Cache) Lookup(rawURL string) (string, bool) {
+	unlock, err := c.lock()
+	if err != nil {
+		return "", false
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return "", false
+	}
+	sha, ok := c.urls[rawURL]
+	if !ok {
+		return "", false
+	}
+	return c.useLocked(sha)
+}
+
+// LookupHash 按内容 SHA256 查找缓存
+func (c *Cache) LookupHash(sha string) (string, bool) {
+	unlock, err := c.lock()
+	if err != nil {
+		return "", false
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return "", false
+	}
+	return c.useLocked(strings.ToLower(sha))
+}
+
+// useLocked 校验文件仍然存在且未过期，并刷新使用时间
+func (c *Cache) useLocked(sha string) (string, bool) {
+	e, ok := c.entries[sha]
+	if !ok {
+		return "", false
+	}
+	path := c.blobPath(e.SHA256, e.Ext)
+	now := c.now()
+	if _, err := os.Stat(path); err != nil || (c.expired(e, now, c.policy.TTL) && !c.pinnedLocked(e)) {
+		c.removeLocked(e)
+		c.saveLocked()
+		return "", false
+	}
+	e.LastUsedAt = now
+	c.saveLocked()
+	return path, true
+}
+
+// Fetch 按 URL 取缓存，未命中时调用 fetch 下载并写入缓存。
+// 同一 URL 的并发请求只会下载一次，其余请求等待并复用结果。
+func (c *Cache) Fetch(rawURL string, fetch func() ([]byte, string, error)) (string, bool, error) {
+	unlock, err := c.lock()
+	if err != nil {
+		return "", false, err
+	}
+	if err := c.loadLocked(); err != nil {
+		unlock()
+		return "", false, err
+	}
+	if sha, ok := c.urls[rawURL]; ok {
+		if path, ok := c.useLocked(sha); ok {
+			unlock()
+			return path, true, nil
+		}
+	}
+	if call, ok := c.inflight[rawURL]; ok {
+		unlock()
+		call.wg.Wait()
+		return call.path, call.err == nil, call.err
+	}
+	call := &cacheCall{}
+	call.wg.Add(1)
+	c.inflight[rawURL] = call
+	unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, rawURL)
+		c.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	data, ext, err := fetch()
+	if err != nil {
+		call.err = err
+		return "", false, err
+	}
+	call.path, call.err = c.Put(rawURL, data, ext)
+	return call.path, false, call.err
+}
+
+// Put 写入内容，rawURL 为空时只按内容缓存；相同内容已存在时不重复写文件
+func (c *Cache) Put(rawURL string, data []byte, ext string) (string, error) {
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+	return c.put(rawURL, sha, ext, int64(len(data)), func(dst string) error {
+		return writeFileAtomic(dst, data)
+	})
+}
+
+// PutFile 把已下载到本地的文件移入缓存，sha 为文件内容的 SHA256
+func (c *Cache) PutFile(rawURL, srcPath, sha, ext string) (string, error) {
+	st, err := os.Stat(srcPath)
+	if err != nil {
+		return "", errors.Wrap(err, "读取待缓存文件失败")
+	}
+	path, err := c.put(rawURL, strings.ToLower(sha), ext, st.Size(), func(dst string) error {
+		return os.Rename(srcPath, dst)
+	})
+	if err != nil {
+		return "", err
+	}
+	// 相同内容已在缓存中时源文件未被移动，直接删除
+	os.Remove(srcPath)
+	return path, nil
+}
+
+func (c *Cache) put(rawURL, sha, ext string, size int64, write func(dst string) error) (string, error) {
+	ext = strings.TrimPrefix(strings.ToLower(ext), ".")
+	if !sha256Pattern.MatchString(sha) || !extPattern.MatchString(ext) {
+		return "", errors.Errorf("无效的缓存键: %s.%s", sha, ext)
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return "", err
+	}
+
+	now := c.now()
+	e, ok := c.entries[sha]
+	if ok {
+		if _, err := os.Stat(c.blobPath(e.SHA256, e.Ext)); err != nil {
+			ok = false
+		}
+	}
+	if !ok {
+		if err := os.MkdirAll(filepath.Join(c.root, cacheBlobsDir), 0755); err != nil {
+			return "", errors.Wrap(err, "创建缓存目录失败")
+		}
+		if err := write(c.blobPath(sha, ext)); err != nil {
+			return "", errors.Wrap(err, "写入缓存失败")
+		}
+		if e == nil {
+			e = &CacheEntry{SHA256: sha, CreatedAt: now}
+			c.entries[sha] = e
+		}
+		e.Ext, e.Size = ext, size
+	}
+	e.LastUsedAt = now
+	if rawURL != "" {
+		if old, ok := c.urls[rawURL]; ok && old != sha {
+			// URL 对应的内容已更新，旧内容交给淘汰策略处理
+			if oe := c.entries[old]; oe != nil {
+				oe.URLs = removeString(oe.URLs, rawURL)
+			}
+		}
+		if c.urls[rawURL] != sha {
+			e.URLs = append(e.URLs, rawURL)
+			c.urls[rawURL] = sha
+		}
+	}
+
+	for _, victim := range c.planLocked(now, c.policy.TTL, c.policy.MaxBytes, false, sha) {
+		logrus.Debugf("淘汰下载缓存: %s (%d bytes)", victim.SHA256, victim.Size)
+		c.removeLocked(victim)
+	}
+	if err := c.saveLocked(); err != nil {
+		return "", err
+	}
+	return c.blobPath(e.SHA256, e.Ext), nil
+}
+
+// Stats 返回缓存概况
+func (c *Cache) Stats() (CacheStats, error) {
+	unlock, err := c.lock()
+	if err != nil {
+		return CacheStats{}, err
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return CacheStats{}, err
+	}
+	st := CacheStats{Root: c.root, Entries: len(c.entries), URLs: len(c.urls), TTL: c.policy.TTL, MaxBytes: c.policy.MaxBytes}
+	for _, e := range c.entries {
+		st.Bytes += e.Size
+	}
+	return st, nil
+}
+
+// List 按最近使用时间倒序返回缓存条目
+func (c *Cache) List() ([]CacheEntry, error) {
+	unlock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return nil, err
+	}
+	out := make([]CacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsedAt.After(out[j].LastUsedAt) })
+	return out, nil
+}
+
+// Prune 按缓存策略清理
+func (c *Cache) Prune() (*CacheCleanupResult, error) {
+	return c.Cleanup(CacheCleanupOptions{})
+}
+
+// Cleanup 清理过期或超出大小上限的内容，同时清理缓存子目录中索引之外的过期文件；
+// 正在使用（Pin）的文件不会被清理
+func (c *Cache) Cleanup(opts CacheCleanupOptions) (*CacheCleanupResult, error) {
+	unlock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		return nil, err
+	}
+
+	ttl, maxBytes := opts.OlderThan, opts.MaxBytes
+	if ttl <= 0 {
+		ttl = c.policy.TTL
+	}
+	if maxBytes <= 0 {
+		maxBytes = c.policy.MaxBytes
+	}
+
+	now := c.now()
+	res := &CacheCleanupResult{Removed: []CacheEntry{}, DryRun: opts.DryRun}
+	for _, e := range c.planLocked(now, ttl, maxBytes, opts.All, "") {
+		res.Removed = append(res.Removed, *e)
+		res.RemovedBytes += e.Size
+		if !opts.DryRun {
+			c.removeLocked(e)
+		}
+	}
+	if !opts.DryRun {
+		if err := c.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 索引之外的文件：处理后的图片、下载中断的视频以及索引丢失的内容。
+	// 缓存目录可能被配置到与其他数据共用的位置，只遍历缓存自己的子目录
+	keep := map[string]bool{}
+	for _, e := range c.entries {
+		keep[c.blobPath(e.SHA256, e.Ext)] = true
+	}
+	for _, dir := range c.managedDirs() {
+		c.cleanupDirLocked(dir, keep, now, ttl, opts, res)
+	}
+	return res, nil
+}
+
+func (c *Cache) cleanupDirLocked(dir string, keep map[string]bool, now time.Time, ttl time.Duration, opts CacheCleanupOptions, res *CacheCleanupResult) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || keep[path] || c.pathPinnedLocked(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if !opts.All && (ttl <= 0 || now.Sub(info.ModTime()) <= ttl) {
+			return nil
+		}
+		res.Files = append(res.Files, path)
+		res.FileBytes += info.Size()
+		if !opts.DryRun {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// planLocked 计算需要淘汰的条目：先淘汰过期的，再按最近使用时间从旧到新淘汰直到不超过 maxBytes；
+// keep 为刚写入的内容，不参与大小淘汰；正在使用（Pin）的内容始终保留
+func (c *Cache) planLocked(now time.Time, ttl time.Duration, maxBytes int64, all bool, keep string) []*CacheEntry {
+	list := make([]*CacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastUsedAt.Before(list[j].LastUsedAt) })
+
+	var victims, remain []*CacheEntry
+	var total int64
+	for _, e := range list {
+		if c.pinnedLocked(e) {
+			total += e.Size
+			continue
+		}
+		if all || (e.SHA256 != keep && c.expired(e, now, ttl)) {
+			victims = append(victims, e)
+			continue
+		}
+		remain = append(remain, e)
+		total += e.Size
+	}
+	for _, e := range remain {
+		if maxBytes <= 0 || total <= maxBytes {
+			break
+		}
+		if e.SHA256 == keep || e.Upload {
+			continue
+		}
+		victims = append(victims, e)
+		total -= e.Size
+	}
+	return victims
+}
+
+func (c *Cache) expired(e *CacheEntry, now time.Time, ttl time.Duration) bool {
+	return ttl > 0 && now.Sub(e.LastUsedAt) > ttl
+}
+
+func (c *Cache) removeLocked(e *CacheEntry) {
+	if err := os.Remove(c.blobPath(e.SHA256, e.Ext)); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("删除缓存文件失败: %v", err)
+		return
+	}
+	for _, u := range e.URLs {
+		if c.urls[u] == e.SHA256 {
+			delete(c.urls, u)
+		}
+	}
+	delete(c.entries, e.SHA256)
+}
+
+type cacheIndex struct {
+	Entries []*CacheEntry `json:"entries"`
+}
+
+// loadLocked 读取索引；索引文件被其他进程（如 cleanup 命令）修改过时重新加载
+func (c *Cache) loadLocked() error {
+	path := filepath.Join(c.root, cacheIndexFile)
+	st, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "读取缓存索引失败")
+		}
+		if !c.loaded {
+			c.entries, c.urls, c.loaded = map[string]*CacheEntry{}, map[string]string{}, true
+		}
+		return nil
+	}
+	if c.loaded && st.ModTime().Equal(c.indexMod) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "读取缓存索引失败")
+	}
+	var idx cacheIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		// 索引损坏时重建，未登记的文件会在清理时删除
+		logrus.Warnf("缓存索引损坏，将重建: %v", err)
+	}
+	c.entries, c.urls = map[string]*CacheEntry{}, map[string]string{}
+	for _, e := range idx.Entries {
+		if e == nil || !sha256Pattern.MatchString(e.SHA256) || !extPattern.MatchString(e.Ext) {
+			continue
+		}
+		c.entries[e.SHA256] = e
+		for _, u := range e.URLs {
+			c.urls[u] = e.SHA256
+		}
+	}
+	c.indexMod, c.loaded = st.ModTime(), true
+	return nil
+}
+
+func (c *Cache) saveLocked() error {
+	idx := cacheIndex{Entries: make([]*CacheEntry, 0, len(c.entries))}
+	for _, e := range c.entries {
+		idx.Entries = append(idx.Entries, e)
+	}
+	sort.Slice(idx.Entries, func(i, j int) bool { return idx.Entries[i].SHA256 < idx.Entries[j].SHA256 })
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.root, 0755); err != nil {
+		return errors.Wrap(err, "创建缓存目录失败")
+	}
+	path := filepath.Join(c.root, cacheIndexFile)
+	if err := writeFileAtomic(path, data); err != nil {
+		return errors.Wrap(err, "保存缓存索引失败")
+	}
+	if st, err := os.Stat(path); err == nil {
+		c.indexMod = st.ModTime()
+	}
+	return nil
+}
+
+// writeFileAtomic 先写临时文件再改名，避免中断后留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package downloader
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mP8/x8AAwMCAO0V9b0AAAAASUVORK5CYII=")
+	require.NoError(t, err)
+	return data
+}
+
+func TestImageDownloader_CacheDedupesByURLAndContent(t *testing.T) {
+	png := testPNG(t)
+	var calls int32
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(png)
+	}))
+	defer srv.Close()
+
+	cache := OpenCache(t.TempDir(), CachePolicy{})
+	d := NewImageDownloader(t.TempDir()).WithCache(cache)
+
+	// 同一 URL 并发下载只请求一次
+	var wg sync.WaitGroup
+	paths := make([]string, 5)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := d.DownloadImage(srv.URL + "/a.png")
+			assert.NoError(t, err)
+			paths[i] = p
+		}(i)
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	for _, p := range paths {
+		assert.Equal(t, paths[0], p)
+	}
+
+	// 不同 URL、相同内容共用一个文件
+	p2, err := d.DownloadImage(srv.URL + "/b.png")
+	require.NoError(t, err)
+	assert.Equal(t, paths[0], p2)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// 重新打开同一目录时从索引恢复
+	delete(caches, cache.Root())
+	reopened := OpenCache(cache.Root(), CachePolicy{})
+	p3, ok := reopened.Lookup(srv.URL + "/b.png")
+	require.True(t, ok)
+	assert.Equal(t, paths[0], p3)
+	stats, err := reopened.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, 2, stats.URLs)
+}
+
+func TestCache_TTLAndSizeEviction(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := OpenCache(t.TempDir(), CachePolicy{TTL: time.Hour, MaxBytes: 25})
+	cache.now = func() time.Time { return now }
+
+	pathA, err := cache.Put("https://example.com/a", []byte("aaaaaaaaaa"), "jpg")
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = cache.Put("https://example.com/b", []byte("bbbbbbbbbb"), "jpg")
+	require.NoError(t, err)
+
+	// 超过大小上限时淘汰最久未使用的 a
+	now = now.Add(time.Minute)
+	_, err = cache.Put("https://example.com/c", []byte("cccccccccc"), "jpg")
+	require.NoError(t, err)
+	_, ok := cache.Lookup("https://example.com/a")
+	assert.False(t, ok)
+	assert.NoFileExists(t, pathA)
+
+	// 超过 TTL 未使用视为未命中
+	now = now.Add(2 * time.Hour)
+	_, ok = cache.Lookup("https://example.com/b")
+	assert.False(t, ok)
+	_, ok = cache.LookupHash("")
+	assert.False(t, ok)
+}
+
+func TestCache_Cleanup(t *testing.T) {
+	now := time.Now()
+	cache := OpenCache(t.TempDir(), CachePolicy{})
+	cache.now = func() time.Time { return now }
+
+	_, err := cache.Put("https://example.com/old", []byte("old"), "png")
+	require.NoError(t, err)
+	now = now.Add(48 * time.Hour)
+	kept, err := cache.Put("https://example.com/new", []byte("new"), "png")
+	require.NoError(t, err)
+
+	// 索引之外的旧文件（如中断的视频下载）也会被清理
+	part := filepath.Join(cache.Root(), "xiaohongshu_videos", "vid_1.part")
+	require.NoError(t, os.MkdirAll(filepath.Dir(part), 0755))
+	require.NoError(t, os.WriteFile(part, []byte("partial"), 0644))
+	require.NoError(t, os.Chtimes(part, now.Add(-48*time.Hour), now.Add(-48*time.Hour)))
+
+	res, err := cache.Cleanup(CacheCleanupOptions{OlderThan: 24 * time.Hour, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, res.Removed, 1)
+	assert.Equal(t, []string{"https://example.com/old"}, res.Removed[0].URLs)
+	assert.Equal(t, []string{part}, res.Files)
+	assert.FileExists(t, part)
+
+	res, err = cache.Cleanup(CacheCleanupOptions{OlderThan: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Len(t, res.Removed, 1)
+	assert.NoFileExists(t, part)
+	assert.FileExists(t, kept)
+
+	res, err = cache.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.Len(t, res.Removed, 1)
+	assert.NoFileExists(t, kept)
+}
+
+func TestCache_CleanupOnlyTouchesCacheDirs(t *testing.T) {
+	root := t.TempDir()
+	cache := OpenCache(root, CachePolicy{})
+
+	// 缓存目录被配置到数据目录时，数据目录中的其他文件不能被删除
+	other := filepath.Join(root, "users.json")
+	require.NoError(t, os.WriteFile(other, []byte("{}"), 0644))
+	nested := filepath.Join(root, "profiles", "a", "Cookies")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nested), 0755))
+	require.NoError(t, os.WriteFile(nested, []byte("x"), 0644))
+
+	res, err := cache.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.Empty(t, res.Files)
+	assert.FileExists(t, other)
+	assert.FileExists(t, nested)
+}
+
+func TestCache_PinnedEntriesSurviveEviction(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := OpenCache(t.TempDir(), CachePolicy{TTL: time.Hour, MaxBytes: 15})
+	cache.now = func() time.Time { return now }
+
+	pathA, err := cache.Put("https://example.com/a", []byte("aaaaaaaaaa"), "jpg")
+	require.NoError(t, err)
+	release := cache.Pin(pathA, "/outside/cache.jpg")
+
+	// 超过大小上限也不淘汰正在使用的 a
+	now = now.Add(time.Minute)
+	_, err = cache.Put("https://example.com/b", []byte("bbbbbbbbbb"), "jpg")
+	require.NoError(t, err)
+	assert.FileExists(t, pathA)
+
+	// 过期与清空也不影响
+	now = now.Add(2 * time.Hour)
+	path, ok := cache.Lookup("https://example.com/a")
+	assert.True(t, ok)
+	assert.Equal(t, pathA, path)
+	_, err = cache.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.FileExists(t, pathA)
+
+	// 释放后按策略正常清理，重复释放无副作用
+	release()
+	release()
+	_, err = cache.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.NoFileExists(t, pathA)
+}
+
+func TestCache_PinsVisibleToOtherProcesses(t *testing.T) {
+	root := t.TempDir()
+	// 两个实例模拟服务进程与 cleanup 命令共用同一个缓存目录
+	server, cli := newCache(root, CachePolicy{}), newCache(root, CachePolicy{})
+
+	path, err := server.Put("https://example.com/a", []byte("aaaa"), "jpg")
+	require.NoError(t, err)
+	release := server.Pin(path)
+
+	res, err := cli.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.Empty(t, res.Removed)
+	assert.FileExists(t, path)
+
+	// 进程退出后遗留的登记文件（锁已释放）被忽略并删除
+	stale := filepath.Join(root, cachePinsDir, "stale.json")
+	require.NoError(t, os.WriteFile(stale, []byte(`["`+path+`"]`), 0644))
+	release()
+	res, err = cli.Cleanup(CacheCleanupOptions{All: true})
+	require.NoError(t, err)
+	assert.Len(t, res.Removed, 1)
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, stale)
+}
@@ -15,6 +15,9 @@ func TestImageDownloader_DownloadImage_RespectsMaxBytes(t *testing.T) {
 		t.Fatalf("decode png: %v", err)
 	}
 
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "image/png")
 		w.WriteHeader(http.StatusOK)
@@ -38,6 +41,9 @@ func TestImageDownloader_DownloadImage_AllowsSmallImage(t *testing.T) {
 		t.Fatalf("decode png: %v", err)
 	}
 
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "image/png")
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,286 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// FetchPolicy 下载远程文件时的地址限制，防止通过图片/视频链接访问内网服务（SSRF）
+type FetchPolicy struct {
+	// AllowPrivate 允许访问内网、回环、链路本地等非公网地址
+	AllowPrivate bool
+	// AllowHosts 非空时只允许这些域名/网段；网段可放行指定的内网地址
+	AllowHosts []string
+	// DenyHosts 禁止的域名/网段，优先于 AllowHosts
+	DenyHosts []string
+	// MaxRedirects 最多跟随的重定向次数，每一跳都会重新校验
+	MaxRedirects int
+}
+
+// DefaultFetchPolicy 按配置生成下载策略
+func DefaultFetchPolicy() FetchPolicy {
+	return FetchPolicy{
+		AllowPrivate: configs.IsFetchAllowPrivate(),
+		AllowHosts:   configs.GetFetchAllowHosts(),
+		DenyHosts:    configs.GetFetchDenyHosts(),
+		MaxRedirects: configs.GetFetchMaxRedirects(),
+	}
+}
+
+// BlockedError 地址被下载策略拒绝
+type BlockedError struct {
+	Target string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("禁止下载 %s: %s", e.Target, e.Reason)
+}
+
+// 除标准库已识别的私有/回环/链路本地之外，仍不应从服务端访问的网段
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),  // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"),  // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),    // 保留地址与广播
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64，内嵌 IPv4 另行校验
+	netip.MustParsePrefix("64:ff9b:1::/48"), // 本地 NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // 文档示例
+	netip.MustParsePrefix("fec0::/10"),      // 已废弃的站点本地地址
+}
+
+// checkURL 校验协议、黑白名单，并解析域名校验所有地址
+func (p FetchPolicy) checkURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &BlockedError{Target: u.Redacted(), Reason: "只支持 http/https"}
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return &BlockedError{Target: u.Redacted(), Reason: "缺少主机名"}
+	}
+	if err := p.checkHost(host); err != nil {
+		return err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return p.checkIP(host, host)
+	}
+	// 经过代理时实际连接由代理发起，这里提前解析一次；直连时拨号前还会再次校验
+	if !p.AllowPrivate {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return err
+		}
+		for _, a := range addrs {
+			if err := p.checkIP(host, a.IP.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkHost 按黑白名单校验主机名（或 IP 字面量）
+func (p FetchPolicy) checkHost(host string) error {
+	for _, rule := range p.DenyHosts {
+		if hostMatches(rule, host) {
+			return &BlockedError{Target: host, Reason: "主机在黑名单中"}
+		}
+	}
+	// 白名单里有网段时，需要等解析出地址后再判断
+	if !p.nameAllowed(host) && !hasCIDR(p.AllowHosts) {
+		return &BlockedError{Target: host, Reason: "主机不在白名单中"}
+	}
+	return nil
+}
+
+// nameAllowed 主机名是否命中白名单中的域名规则；白名单为空时都允许
+func (p FetchPolicy) nameAllowed(host string) bool {
+	if len(p.AllowHosts) == 0 {
+		return true
+	}
+	for _, rule := range p.AllowHosts {
+		if hostMatches(rule, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIP 校验解析得到的地址；白名单中的网段可以放行内网地址
+func (p FetchPolicy) checkIP(host, ip string) error {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return &BlockedError{Target: host, Reason: "无法识别的地址 " + ip}
+	}
+	addr = addr.Unmap()
+	for _, rule := range p.DenyHosts {
+		if ipMatches(rule, addr) {
+			return &BlockedError{Target: host, Reason: "地址 " + addr.String() + " 在黑名单中"}
+		}
+	}
+	inAllowedNet := false
+	for _, rule := range p.AllowHosts {
+		if ipMatches(rule, addr) {
+			inAllowedNet = true
+			break
+		}
+	}
+	if !inAllowedNet && !p.nameAllowed(host) {
+		return &BlockedError{Target: host, Reason: "地址 " + addr.String() + " 不在白名单中"}
+	}
+	if !inAllowedNet && !p.AllowPrivate && !isPublicIP(addr) {
+		return &BlockedError{Target: host, Reason: "地址 " + addr.String() + " 属于内网/回环/链路本地等保留网段"}
+	}
+	return nil
+}
+
+// isPublicIP 判断是否为可从公网访问的单播地址
+func isPublicIP(addr netip.Addr) bool {
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			// NAT64 地址按内嵌的 IPv4 判断
+			if prefix.Bits() == 96 && addr.Is6() {
+				b := addr.As16()
+				return isPublicIP(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// hostMatches 域名规则匹配自身及子域名，"*.example.com" 只匹配子域名
+func hostMatches(rule, host string) bool {
+	if strings.Contains(rule, "/") {
+		if addr, err := netip.ParseAddr(host); err == nil {
+			return ipMatches(rule, addr.Unmap())
+		}
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(rule, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	rule = strings.TrimPrefix(rule, ".")
+	return host == rule || strings.HasSuffix(host, "."+rule)
+}
+
+// ipMatches 网段或单个 IP 规则匹配地址，域名规则不匹配
+func ipMatches(rule string, addr netip.Addr) bool {
+	if prefix, err := netip.ParsePrefix(rule); err == nil {
+		return prefix.Contains(addr)
+	}
+	if ip, err := netip.ParseAddr(rule); err == nil {
+		return ip.Unmap() == addr
+	}
+	return false
+}
+
+func hasCIDR(rules []string) bool {
+	for _, r := range rules {
+		if _, err := netip.ParsePrefix(r); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// newClient 创建按策略校验的 HTTP 客户端：请求与每次重定向前校验 URL，
+// 直连时在拨号前重新解析并校验地址，只连接校验通过的 IP，防止 DNS 重绑定
+func (p FetchPolicy) newClient(timeout time.Duration, transport *http.Transport) *http.Client {
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSHandshakeTimeout: 10 * time.Second,
+		}
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	proxies := proxyAddrs()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if proxies[addr] {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(strings.TrimSuffix(host, "."))
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			if err := p.checkIP(name, ip.IP.String()); err != nil {
+				lastErr = err
+				continue
+			}
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no address for %s", host)
+		}
+		return nil, lastErr
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &policyTransport{policy: p, next: transport},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > p.MaxRedirects {
+				return &BlockedError{Target: req.URL.Redacted(), Reason: fmt.Sprintf("重定向超过 %d 次", p.MaxRedirects)}
+			}
+			return nil
+		},
+	}
+}
+
+// policyTransport 每次发出请求（包括重定向后的请求）前校验 URL
+type policyTransport struct {
+	policy FetchPolicy
+	next   http.RoundTripper
+}
+
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.checkURL(req.Context(), req.URL); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// proxyAddrs 环境变量中配置的代理地址，连接代理本身不受地址限制
+func proxyAddrs() map[string]bool {
+	out := map[string]bool{}
+	for _, target := range []string{"http://example.com", "https://example.com"} {
+		req, _ := http.NewRequest("GET", target, nil)
+		u, err := http.ProxyFromEnvironment(req)
+		if err != nil || u == nil {
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = map[string]string{"https": "443", "socks5": "1080"}[u.Scheme]
+			if port == "" {
+				port = "80"
+			}
+		}
+		out[net.JoinHostPort(u.Hostname(), port)] = true
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"8.8.8.8":          true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"64:ff9b::a00:1":   false, // NAT64 内嵌 10.0.0.1
+		"64:ff9b::808:808": true,
+	} {
+		assert.Equal(t, want, isPublicIP(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestFetchPolicy_CheckURL(t *testing.T) {
+	ctx := context.Background()
+	check := func(p FetchPolicy, raw string) error {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return p.checkURL(ctx, u)
+	}
+	var blocked *BlockedError
+
+	p := FetchPolicy{}
+	assert.ErrorAs(t, check(p, "http://127.0.0.1/a.png"), &blocked)
+	assert.ErrorAs(t, check(p, "http://[::ffff:169.254.169.254]/latest/meta-data"), &blocked)
+	assert.ErrorAs(t, check(p, "ftp://8.8.8.8/a.png"), &blocked)
+	assert.NoError(t, check(p, "https://8.8.8.8/a.png"))
+
+	// 白名单网段可以放行指定内网地址
+	p = FetchPolicy{AllowHosts: []string{"10.0.0.0/8"}}
+	assert.NoError(t, check(p, "http://10.1.2.3/a.png"))
+	assert.ErrorAs(t, check(p, "http://192.168.1.1/a.png"), &blocked)
+	assert.ErrorAs(t, check(p, "http://8.8.8.8/a.png"), &blocked)
+
+	p = FetchPolicy{AllowHosts: []string{"xhscdn.com"}, DenyHosts: []string{"bad.xhscdn.com"}}
+	assert.ErrorAs(t, check(p, "https://example.com/a.png"), &blocked)
+	assert.ErrorAs(t, check(p, "https://bad.xhscdn.com/a.png"), &blocked)
+	assert.True(t, p.nameAllowed("sns-img.xhscdn.com"))
+	assert.False(t, hostMatches("*.xhscdn.com", "xhscdn.com"))
+
+	p = FetchPolicy{AllowPrivate: true, DenyHosts: []string{"169.254.0.0/16"}}
+	assert.NoError(t, check(p, "http://127.0.0.1/a.png"))
+	assert.ErrorAs(t, check(p, "http://169.254.169.254/"), &blocked)
+}
+
+func TestFetchPolicy_ClientBlocksLoopbackAndRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/to-localhost":
+			http.Redirect(w, r, strings.Replace(srvURL(r), "127.0.0.1", "localhost", 1)+"/ok", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/loop"):
+			http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	get := func(p FetchPolicy, path string) error {
+		resp, err := p.newClient(5*time.Second, nil).Get(srv.URL + path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	var blocked *BlockedError
+
+	// 默认禁止访问回环地址
+	err := get(FetchPolicy{MaxRedirects: 5}, "/ok")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &blocked))
+
+	allow := FetchPolicy{AllowPrivate: true, MaxRedirects: 5}
+	assert.NoError(t, get(allow, "/ok"))
+
+	// 重定向后的每一跳都重新校验
+	deny := allow
+	deny.DenyHosts = []string{"localhost"}
+	err = get(deny, "/to-localhost")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &blocked))
+
+	limited := allow
+	limited.MaxRedirects = 2
+	err = get(limited, "/loop")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &blocked))
+	assert.Contains(t, err.Error(), "重定向超过 2 次")
+}
+
+func srvURL(r *http.Request) string {
+	return "http://" + r.Host
+}
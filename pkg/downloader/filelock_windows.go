@@ -0,0 +1,36 @@
+//go:build windows
+
+package downloader
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// 锁定文件内容之外的一个字节，加锁后其他进程仍可读写文件内容
+const (
+	lockOffsetLow  = 0xFFFFFFFF
+	lockOffsetHigh = 0x7FFFFFFF
+)
+
+// lockFile 对 f 加排他锁（LockFileEx），block 为 false 且锁被占用时返回 errFileLocked。
+// 锁随文件关闭或进程退出自动释放
+func lockFile(f *os.File, block bool) error {
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK)
+	if !block {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	ol := windows.Overlapped{Offset: lockOffsetLow, OffsetHigh: lockOffsetHigh}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, &ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errFileLocked
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	ol := windows.Overlapped{Offset: lockOffsetLow, OffsetHigh: lockOffsetHigh}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &ol)
+}
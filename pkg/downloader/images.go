@@ -13,6 +13,7 @@ import (
 
 	"github.com/h2non/filetype"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
@@ -20,6 +21,8 @@ import (
 type ImageDownloader struct {
 	savePath   string
 	httpClient *http.Client
+	// cache 不为 nil 时下载结果写入缓存，同一 URL 不重复下载
+	cache *Cache
 }
 
 // NewImageDownloader 创建图片下载器
@@ -30,13 +33,17 @@ func NewImageDownloader(savePath string) *ImageDownloader {
 	}
 
 	return &ImageDownloader{
-		savePath: savePath,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		savePath:   savePath,
+		httpClient: DefaultFetchPolicy().newClient(30*time.Second, nil),
 	}
 }
 
+// WithCache 使用下载缓存
+func (d *ImageDownloader) WithCache(cache *Cache) *ImageDownloader {
+	d.cache = cache
+	return d
+}
+
 // DownloadImage 下载图片
 // 返回本地文件路径
 func (d *ImageDownloader) DownloadImage(imageURL string) (string, error) {
@@ -45,11 +52,45 @@ func (d *ImageDownloader) DownloadImage(imageURL string) (string, error) {
 		return "", errors.New("invalid image URL format")
 	}
 
+	if d.cache != nil {
+		path, hit, err := d.cache.Fetch(imageURL, func() ([]byte, string, error) {
+			return d.fetchImage(imageURL)
+		})
+		if err == nil && hit {
+			logrus.Debugf("图片命中下载缓存: %s -> %s", imageURL, path)
+		}
+		return path, err
+	}
+
+	imageData, ext, err := d.fetchImage(imageURL)
+	if err != nil {
+		return "", err
+	}
+
+	// 生成唯一文件名
+	fileName := d.generateFileName(imageURL, ext)
+	filePath := filepath.Join(d.savePath, fileName)
+
+	// 如果文件已存在，直接返回路径
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath, nil
+	}
+
+	// 保存到文件
+	if err := os.WriteFile(filePath, imageData, 0644); err != nil {
+		return "", errors.Wrap(err, "failed to save image")
+	}
+
+	return filePath, nil
+}
+
+// fetchImage 下载并校验图片，返回图片数据与扩展名
+func (d *ImageDownloader) fetchImage(imageURL string) ([]byte, string, error) {
 	maxBytes := configs.GetImageMaxBytes()
 	// 创建请求并设置请求头
 	req, err := http.NewRequest("GET", imageURL, nil)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create request")
+		return nil, "", errors.Wrap(err, "failed to create request")
 	}
 
 	// 设置 User-Agent，模拟浏览器请求
@@ -64,15 +105,15 @@ func (d *ImageDownloader) DownloadImage(imageURL string) (string, error) {
 	// 下载图片数据
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to download image from %s", imageURL)
+		return nil, "", errors.Wrapf(err, "failed to download image from %s", imageURL)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download failed with status %d for URL: %s", resp.StatusCode, imageURL)
+		return nil, "", fmt.Errorf("download failed with status %d for URL: %s", resp.StatusCode, imageURL)
 	}
 	if maxBytes > 0 && resp.ContentLength > 0 && resp.ContentLength > maxBytes {
-		return "", fmt.Errorf("image too large: %d bytes (max %d)", resp.ContentLength, maxBytes)
+		return nil, "", fmt.Errorf("image too large: %d bytes (max %d)", resp.ContentLength, maxBytes)
 	}
 
 	// 读取图片数据
@@ -82,37 +123,23 @@ func (d *ImageDownloader) DownloadImage(imageURL string) (string, error) {
 	}
 	imageData, err := io.ReadAll(reader)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to read image data")
+		return nil, "", errors.Wrap(err, "failed to read image data")
 	}
 	if maxBytes > 0 && int64(len(imageData)) > maxBytes {
-		return "", fmt.Errorf("image too large: %d bytes (max %d)", len(imageData), maxBytes)
+		return nil, "", fmt.Errorf("image too large: %d bytes (max %d)", len(imageData), maxBytes)
 	}
 
 	// 检测图片格式
 	kind, err := filetype.Match(imageData)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to detect file type")
+		return nil, "", errors.Wrap(err, "failed to detect file type")
 	}
 
 	if !filetype.IsImage(imageData) {
-		return "", errors.New("downloaded file is not a valid image")
+		return nil, "", errors.New("downloaded file is not a valid image")
 	}
 
-	// 生成唯一文件名
-	fileName := d.generateFileName(imageURL, kind.Extension)
-	filePath := filepath.Join(d.savePath, fileName)
-
-	// 如果文件已存在，直接返回路径
-	if _, err := os.Stat(filePath); err == nil {
-		return filePath, nil
-	}
-
-	// 保存到文件
-	if err := os.WriteFile(filePath, imageData, 0644); err != nil {
-		return "", errors.Wrap(err, "failed to save image")
-	}
-
-	return filePath, nil
+	return imageData, kind.Extension, nil
 }
 
 // DownloadImages 批量下载图片
@@ -0,0 +1,300 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/filetype"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// MediaHandlePrefix 服务端媒体句柄前缀，句柄形如 media://<sha256>
+const MediaHandlePrefix = "media://"
+
+// 上传文件写入缓存前的临时目录
+const uploadsDir = "uploads"
+
+// 媒体类型
+const (
+	MediaKindImage = "image"
+	MediaKindVideo = "video"
+)
+
+// StoredMedia 写入缓存的上传文件或 data URI
+type StoredMedia struct {
+	Handle   string `json:"handle"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+	MimeType string `json:"mime_type"`
+	Path     string `json:"-"`
+}
+
+// IsDataURI 判断是否为 data: URI
+func IsDataURI(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), "data:")
+}
+
+// IsMediaHandle 判断是否为服务端媒体句柄
+func IsMediaHandle(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), MediaHandlePrefix)
+}
+
+// IsLocalPath 既不是链接、data URI 也不是媒体句柄时视为服务端本地路径
+func IsLocalPath(s string) bool {
+	return !IsImageURL(s) && !IsDataURI(s) && !IsMediaHandle(s)
+}
+
+// parseDataURI 解析 data:<mime>;base64,<data> 的头部，返回 MIME 与 base64 数据部分
+func parseDataURI(uri string) (string, string, error) {
+	if !IsDataURI(uri) {
+		return "", "", errors.New("不是 data URI")
+	}
+	meta, payload, ok := strings.Cut(uri[len("data:"):], ",")
+	if !ok {
+		return "", "", errors.New("data URI 格式错误：缺少逗号分隔的数据部分")
+	}
+	params := strings.Split(meta, ";")
+	mime := strings.ToLower(strings.TrimSpace(params[0]))
+	isBase64 := false
+	for _, p := range params[1:] {
+		if strings.EqualFold(strings.TrimSpace(p), "base64") {
+			isBase64 = true
+		}
+	}
+	if !isBase64 {
+		return "", "", errors.New("data URI 只支持 base64 编码")
+	}
+	return mime, payload, nil
+}
+
+// 客户端常把长 base64 折行
+func isBase64Space(r rune) bool {
+	switch r {
+	case '\n', '\r', ' ', '\t':
+		return true
+	}
+	return false
+}
+
+// base64Chars 不计空白的 base64 字符数
+func base64Chars(payload string) int {
+	n := 0
+	for _, r := range payload {
+		if !isBase64Space(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// payloadEncoding 按数据判断使用 URL 安全字母表还是标准字母表、是否省略了填充
+func payloadEncoding(payload string, chars int) *base64.Encoding {
+	urlSafe := strings.ContainsAny(payload, "-_")
+	padded := strings.HasSuffix(strings.TrimRightFunc(payload, isBase64Space), "=") || chars%4 == 0
+	switch {
+	case urlSafe && padded:
+		return base64.URLEncoding
+	case urlSafe:
+		return base64.RawURLEncoding
+	case padded:
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
+
+// spaceSkipReader 读取时跳过空白字符
+type spaceSkipReader struct {
+	s string
+}
+
+func (r *spaceSkipReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && len(r.s) > 0 {
+		c := r.s[0]
+		r.s = r.s[1:]
+		if isBase64Space(rune(c)) {
+			continue
+		}
+		p[n] = c
+		n++
+	}
+	if n == 0 && len(r.s) == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// DecodeDataURI 解析 data:<mime>;base64,<data>，只支持 base64 编码
+func DecodeDataURI(uri string) ([]byte, string, error) {
+	mime, payload, err := parseDataURI(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 客户端常把长 base64 折行，或使用 URL 安全字母表/省略填充
+	payload = strings.Map(func(r rune) rune {
+		if isBase64Space(r) {
+			return -1
+		}
+		return r
+	}, payload)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(payload); err == nil {
+			return data, mime, nil
+		}
+	}
+	return nil, "", errors.New("data URI 的 base64 数据无法解码")
+}
+
+// StoreDataURI 把 data URI 中的图片或视频写入缓存。解码前按 base64 长度估算大小并校验上限，
+// 解码时直接流式写入文件，不在内存中保留整份数据
+func (c *Cache) StoreDataURI(uri string) (*StoredMedia, error) {
+	_, payload, err := parseDataURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	chars := base64Chars(payload)
+	if chars == 0 {
+		return nil, errors.New("data URI 数据为空")
+	}
+	limit := max(configs.GetImageMaxBytes(), configs.GetVideoMaxBytes())
+	if size := int64(chars) / 4 * 3; limit > 0 && size > limit {
+		return nil, fmt.Errorf("data URI 过大: 约 %d bytes (max %d)", size, limit)
+	}
+	media, err := c.Store(base64.NewDecoder(payloadEncoding(payload, chars), &spaceSkipReader{s: payload}))
+	if err != nil {
+		return nil, errors.Wrap(err, "data URI 的 base64 数据无法解码")
+	}
+	return media, nil
+}
+
+// Store 保存上传的图片或视频，按文件头识别类型并按对应的大小上限校验，
+// 相同内容只保存一份，返回可在发布请求中使用的媒体句柄
+func (c *Cache) Store(r io.Reader) (*StoredMedia, error) {
+	dir := filepath.Join(c.root, uploadsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建上传目录失败")
+	}
+	f, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "创建上传文件失败")
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	imageMax, videoMax := configs.GetImageMaxBytes(), configs.GetVideoMaxBytes()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, max(imageMax, videoMax)+1))
+	f.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "保存上传文件失败")
+	}
+	if n == 0 {
+		return nil, errors.New("上传文件为空")
+	}
+
+	head := make([]byte, 262)
+	if hf, err := os.Open(tmp); err == nil {
+		k, _ := io.ReadFull(hf, head)
+		head = head[:k]
+		hf.Close()
+	}
+	kind, err := filetype.Match(head)
+	if err != nil || kind == filetype.Unknown {
+		return nil, errors.New("无法识别的文件类型，只支持图片和 MP4/MOV 视频")
+	}
+
+	out := &StoredMedia{SHA256: hex.EncodeToString(h.Sum(nil)), Size: n, MimeType: kind.MIME.Value}
+	switch {
+	case filetype.IsImage(head):
+		out.Kind = MediaKindImage
+		if imageMax > 0 && n > imageMax {
+			return nil, fmt.Errorf("图片过大: %d bytes (max %d)", n, imageMax)
+		}
+	case kind.Extension == "mp4" || kind.Extension == "mov":
+		out.Kind = MediaKindVideo
+		if videoMax > 0 && n > videoMax {
+			return nil, fmt.Errorf("视频过大: %d bytes (max %d)", n, videoMax)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的文件类型: %s", kind.MIME.Value)
+	}
+
+	out.Path, err = c.PutFile("", tmp, out.SHA256, kind.Extension)
+	if err != nil {
+		return nil, err
+	}
+	c.markUpload(out.SHA256, out.Kind)
+	out.Handle = MediaHandlePrefix + out.SHA256
+	return out, nil
+}
+
+// markUpload 记录上传内容的媒体类型；上传内容只按 TTL 过期，不参与按大小淘汰，
+// 避免句柄在使用前被其他下载挤掉
+func (c *Cache) markUpload(sha, kind string) {
+	unlock, err := c.lock()
+	if err != nil {
+		logrus.Warnf("保存缓存索引失败: %v", err)
+		return
+	}
+	defer unlock()
+	if err := c.loadLocked(); err != nil {
+		logrus.Warnf("读取缓存索引失败: %v", err)
+		return
+	}
+	if e := c.entries[sha]; e != nil {
+		e.Kind, e.Upload = kind, true
+		if err := c.saveLocked(); err != nil {
+			logrus.Warnf("保存缓存索引失败: %v", err)
+		}
+	}
+}
+
+// mediaKindOf 缓存条目的媒体类型，旧索引中没有记录时按扩展名判断
+func mediaKindOf(e *CacheEntry) string {
+	if e.Kind != "" {
+		return e.Kind
+	}
+	switch e.Ext {
+	case "mp4", "mov":
+		return MediaKindVideo
+	}
+	return MediaKindImage
+}
+
+// ResolveHandle 返回媒体句柄对应的本地文件路径，kind 不为空时校验媒体类型
+func (c *Cache) ResolveHandle(handle, kind string) (string, error) {
+	if !IsMediaHandle(handle) {
+		return "", fmt.Errorf("无效的媒体句柄: %s", handle)
+	}
+	sha := strings.ToLower(strings.TrimSpace(handle[len(MediaHandlePrefix):]))
+	if !sha256Pattern.MatchString(sha) {
+		return "", fmt.Errorf("无效的媒体句柄: %s", handle)
+	}
+	path, ok := c.LookupHash(sha)
+	if !ok {
+		return "", fmt.Errorf("媒体句柄不存在或已过期，请重新上传: %s", handle)
+	}
+	if kind != "" {
+		c.mu.Lock()
+		e := c.entries[sha]
+		got := ""
+		if e != nil {
+			got = mediaKindOf(e)
+		}
+		c.mu.Unlock()
+		if got != kind {
+			return "", fmt.Errorf("媒体句柄类型不符: %s 是 %s，需要 %s", handle, got, kind)
+		}
+	}
+	return path, nil
+}
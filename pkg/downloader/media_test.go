@@ -0,0 +1,186 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
+)
+
+func TestDecodeDataURI(t *testing.T) {
+	png := testPNG(t)
+	std := base64.StdEncoding.EncodeToString(png)
+
+	for _, uri := range []string{
+		"data:image/png;base64," + std,
+		"DATA:image/png;charset=binary;BASE64," + std[:40] + "\n" + std[40:],
+		"data:image/png;base64," + base64.RawURLEncoding.EncodeToString(png),
+	} {
+		data, mime, err := DecodeDataURI(uri)
+		require.NoError(t, err, uri)
+		assert.Equal(t, png, data)
+		assert.Equal(t, "image/png", mime)
+	}
+
+	_, _, err := DecodeDataURI("data:image/png," + std)
+	assert.Error(t, err)
+	_, _, err = DecodeDataURI("data:image/png;base64")
+	assert.Error(t, err)
+}
+
+func TestImageProcessor_DataURIAndMediaHandle(t *testing.T) {
+	cache := OpenCache(t.TempDir(), CachePolicy{})
+	p := &ImageProcessor{downloader: NewImageDownloader(t.TempDir()).WithCache(cache)}
+
+	media, err := cache.Store(strings.NewReader(string(testPNG(t))))
+	require.NoError(t, err)
+	assert.Equal(t, MediaKindImage, media.Kind)
+	assert.True(t, IsMediaHandle(media.Handle))
+
+	local := filepath.Join(t.TempDir(), "a.png")
+	require.NoError(t, os.WriteFile(local, testPNG(t), 0644))
+	uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG(t))
+	paths, err := p.ProcessImages([]string{uri, media.Handle, local})
+	require.NoError(t, err)
+	// 相同内容的 data URI 与上传文件共用一份缓存
+	assert.Equal(t, []string{media.Path, media.Path, local}, paths)
+	_, err = os.Stat(media.Path)
+	require.NoError(t, err)
+
+	_, err = p.ProcessImages([]string{"media://" + strings.Repeat("0", 64)})
+	assert.ErrorContains(t, err, "不存在或已过期")
+	_, err = p.ProcessImages([]string{"data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte("hello"))})
+	assert.Error(t, err)
+}
+
+func TestImageProcessor_ReportKeepsOrderAndMarksFailures(t *testing.T) {
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+	t.Setenv("XHS_MCP_IMAGE_FETCH_CONCURRENCY", "3")
+
+	png := testPNG(t)
+	var inflight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			m := atomic.LoadInt32(&peak)
+			if n <= m || atomic.CompareAndSwapInt32(&peak, m, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/missing.png") {
+			http.NotFound(w, r)
+			return
+		}
+		// 每个 URL 返回不同内容，避免缓存合并
+		_, _ = w.Write(append(append([]byte{}, png...), []byte(r.URL.Path)...))
+	}))
+	defer srv.Close()
+
+	p := &ImageProcessor{downloader: NewImageDownloader(t.TempDir()).WithCache(OpenCache(t.TempDir(), CachePolicy{}))}
+	images := []string{srv.URL + "/1.png", srv.URL + "/2.png", srv.URL + "/missing.png", srv.URL + "/4.png", srv.URL + "/5.png", "/no/such/file.png"}
+	reports, err := p.ProcessImagesReport(images)
+
+	var imagesErr *ImagesError
+	require.ErrorAs(t, err, &imagesErr)
+	assert.Contains(t, err.Error(), "2/6 张图片处理失败")
+	require.Len(t, reports, len(images))
+	for i, r := range reports {
+		assert.Equal(t, i, r.Index)
+		assert.Equal(t, images[i], r.Source)
+	}
+	assert.Contains(t, reports[2].Error, "404")
+	assert.Contains(t, reports[5].Error, "不可访问")
+	for _, i := range []int{0, 1, 3, 4} {
+		assert.Empty(t, reports[i].Error)
+		assert.Equal(t, "image/png", reports[i].MimeType)
+		assert.Greater(t, reports[i].Bytes, int64(0))
+		assert.FileExists(t, reports[i].Path)
+	}
+	assert.NotEqual(t, reports[0].Path, reports[1].Path)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+	assert.Greater(t, atomic.LoadInt32(&peak), int32(1))
+
+	_, err = p.ProcessImages(images)
+	assert.ErrorAs(t, err, &imagesErr)
+}
+
+func TestImageProcessor_OptionsCompressOversizedLocalImage(t *testing.T) {
+	t.Setenv("XHS_MCP_IMAGE_MAX_BYTES", "20480")
+
+	// 噪声图难以压缩，PNG 远超上限
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = byte(seed >> 24)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	local := filepath.Join(t.TempDir(), "noise.png")
+	require.NoError(t, os.WriteFile(local, buf.Bytes(), 0644))
+
+	p := &ImageProcessor{downloader: NewImageDownloader(t.TempDir()).WithCache(OpenCache(t.TempDir(), CachePolicy{}))}
+	_, err := p.ProcessImages([]string{local})
+	assert.ErrorContains(t, err, "图片过大")
+
+	paths, err := p.ProcessImagesWithOptions([]string{local}, &imageproc.Options{})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	st, err := os.Stat(paths[0])
+	require.NoError(t, err)
+	assert.LessOrEqual(t, st.Size(), int64(20480))
+}
+
+func TestCache_MediaHandleKindAndRetention(t *testing.T) {
+	cache := OpenCache(t.TempDir(), CachePolicy{MaxBytes: 100})
+
+	media, err := cache.StoreDataURI("data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG(t)))
+	require.NoError(t, err)
+	assert.Equal(t, MediaKindImage, media.Kind)
+
+	path, err := cache.ResolveHandle(media.Handle, MediaKindImage)
+	require.NoError(t, err)
+	assert.Equal(t, media.Path, path)
+	_, err = cache.ResolveHandle(media.Handle, MediaKindVideo)
+	assert.ErrorContains(t, err, "类型不符")
+
+	// 其他下载超出大小上限时不淘汰上传的内容
+	_, err = cache.Put("https://example.com/big", make([]byte, 200), "jpg")
+	require.NoError(t, err)
+	_, err = cache.ResolveHandle(media.Handle, MediaKindImage)
+	assert.NoError(t, err)
+}
+
+func TestCache_StoreDataURIChecksSizeBeforeDecoding(t *testing.T) {
+	t.Setenv("XHS_MCP_IMAGE_MAX_BYTES", "1024")
+	t.Setenv("XHS_MCP_VIDEO_MAX_BYTES", "1024")
+	cache := OpenCache(t.TempDir(), CachePolicy{})
+
+	// 无效的 base64 也会先因长度被拒绝，说明没有先解码
+	_, err := cache.StoreDataURI("data:image/png;base64," + strings.Repeat("!", 4096))
+	assert.ErrorContains(t, err, "过大")
+
+	// 折行、URL 安全字母表与省略填充仍可流式解码
+	png := testPNG(t)
+	enc := base64.RawURLEncoding.EncodeToString(png)
+	media, err := cache.StoreDataURI("data:image/png;base64," + enc[:20] + "\r\n" + enc[20:])
+	require.NoError(t, err)
+	got, err := os.ReadFile(media.Path)
+	require.NoError(t, err)
+	assert.Equal(t, png, got)
+}
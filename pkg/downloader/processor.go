@@ -2,8 +2,15 @@ package downloader
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/h2non/filetype"
 
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
 )
 
 // ImageProcessor 图片处理器
@@ -11,39 +18,274 @@ type ImageProcessor struct {
 	downloader *ImageDownloader
 }
 
-// NewImageProcessor 创建图片处理器
+// NewImageProcessor 创建使用全局下载缓存的图片处理器
 func NewImageProcessor() *ImageProcessor {
+	return NewImageProcessorWithCache(DefaultCache())
+}
+
+// NewImageProcessorWithCache 创建图片处理器，下载与处理后的图片都放在 cache 目录下
+func NewImageProcessorWithCache(cache *Cache) *ImageProcessor {
 	return &ImageProcessor{
-		downloader: NewImageDownloader(configs.GetImagesPath()),
+		downloader: NewImageDownloader(filepath.Join(cache.Root(), configs.ImagesDir)).WithCache(cache),
 	}
 }
 
+// ImageReport 单张图片的处理结果
+type ImageReport struct {
+	Index    int    `json:"index"`
+	Source   string `json:"source"`
+	Path     string `json:"path,omitempty"`
+	Bytes    int64  `json:"bytes,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImagesError 部分图片处理失败，Reports 包含全部图片（含成功的）的结果
+type ImagesError struct {
+	Reports []ImageReport
+}
+
+func (e *ImagesError) Error() string {
+	var failed []string
+	for _, r := range e.Reports {
+		if r.Error != "" {
+			failed = append(failed, fmt.Sprintf("第 %d 张 %s: %s", r.Index+1, r.Source, r.Error))
+		}
+	}
+	return fmt.Sprintf("%d/%d 张图片处理失败: %s", len(failed), len(e.Reports), strings.Join(failed, "; "))
+}
+
 // ProcessImages 处理图片列表，返回本地文件路径
-// 支持两种输入格式：
+// 支持以下输入格式：
 // 1. URL格式 (http/https开头) - 自动下载到本地
-// 2. 本地文件路径 - 直接使用
-// 保持原始图片顺序，如果下载失败直接返回错误
+// 2. data:image/...;base64, URI - 解码后写入缓存
+// 3. media://<sha256> 句柄 - 通过上传接口保存的文件
+// 4. 本地文件路径 - 直接使用
+// 保持原始图片顺序，任意一张失败时返回 *ImagesError
 func (p *ImageProcessor) ProcessImages(images []string) ([]string, error) {
-	localPaths := make([]string, 0, len(images))
-
-	// 按顺序处理每张图片
-	for _, image := range images {
-		if IsImageURL(image) {
-			// URL图片：立即下载，失败直接返回错误
-			localPath, err := p.downloader.DownloadImage(image)
-			if err != nil {
-				return nil, fmt.Errorf("下载图片失败 %s: %w", image, err)
+	reports, err := p.ProcessImagesReport(images)
+	if err != nil {
+		return nil, err
+	}
+	localPaths := make([]string, len(reports))
+	for i, r := range reports {
+		localPaths[i] = r.Path
+	}
+	return localPaths, nil
+}
+
+// ProcessImagesReport 并发处理图片（并发数见 XHS_MCP_IMAGE_FETCH_CONCURRENCY），
+// 按原始顺序返回每张图片的结果；有失败时同时返回 *ImagesError
+func (p *ImageProcessor) ProcessImagesReport(images []string) ([]ImageReport, error) {
+	return p.processImagesReport(images, true)
+}
+
+// processImagesReport limitSize 为 false 时不按 XHS_MCP_IMAGE_MAX_BYTES 拒绝本地文件，
+// 由后续的图片处理压缩到上限以内
+func (p *ImageProcessor) processImagesReport(images []string, limitSize bool) ([]ImageReport, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no valid images found")
+	}
+
+	reports := make([]ImageReport, len(images))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(configs.GetImageFetchConcurrency(), len(images)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				reports[i] = p.processImage(i, images[i], limitSize)
 			}
-			localPaths = append(localPaths, localPath)
-		} else {
-			// 本地路径直接使用
-			localPaths = append(localPaths, image)
+		}()
+	}
+	for i := range images {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, r := range reports {
+		if r.Error != "" {
+			return reports, &ImagesError{Reports: reports}
 		}
 	}
+	return reports, nil
+}
 
-	if len(localPaths) == 0 {
-		return nil, fmt.Errorf("no valid images found")
+// processImage 把单张图片转换为本地文件，并记录大小与类型
+func (p *ImageProcessor) processImage(index int, image string, limitSize bool) ImageReport {
+	r := ImageReport{Index: index, Source: describeSource(image)}
+	var err error
+	switch {
+	case IsImageURL(image):
+		// URL图片：下载到缓存
+		r.Path, err = p.downloader.DownloadImage(image)
+		if err != nil {
+			err = fmt.Errorf("下载图片失败: %w", err)
+		}
+	case IsDataURI(image):
+		var media *StoredMedia
+		media, err = p.cache().StoreDataURI(image)
+		switch {
+		case err != nil:
+			err = fmt.Errorf("data URI 无效: %w", err)
+		case media.Kind != MediaKindImage:
+			err = fmt.Errorf("data URI 不是图片: %s", media.MimeType)
+		default:
+			r.Path = media.Path
+		}
+	case IsMediaHandle(image):
+		r.Path, err = p.cache().ResolveHandle(image, MediaKindImage)
+	default:
+		// 本地路径直接使用，统一为绝对路径
+		r.Path, err = filepath.Abs(image)
+		if err != nil {
+			err = fmt.Errorf("图片路径无效: %w", err)
+		}
+	}
+	if err != nil {
+		r.Path, r.Error = "", err.Error()
+		return r
 	}
 
-	return localPaths, nil
+	st, err := os.Stat(r.Path)
+	if err != nil {
+		r.Path, r.Error = "", fmt.Sprintf("图片不可访问: %v", err)
+		return r
+	}
+	if st.IsDir() {
+		r.Path, r.Error = "", "图片路径是目录"
+		return r
+	}
+	if maxBytes := configs.GetImageMaxBytes(); limitSize && maxBytes > 0 && st.Size() > maxBytes {
+		r.Path, r.Error = "", fmt.Sprintf("图片过大: %d bytes (max %d)", st.Size(), maxBytes)
+		return r
+	}
+	r.Bytes = st.Size()
+	if kind, err := filetype.MatchFile(r.Path); err == nil && kind != filetype.Unknown {
+		r.MimeType = kind.MIME.Value
+	}
+	return r
+}
+
+// describeSource 报告中的图片来源，data URI 只保留类型与长度
+func describeSource(image string) string {
+	if IsDataURI(image) {
+		meta, payload, _ := strings.Cut(image, ",")
+		return fmt.Sprintf("%s,...(%d chars)", meta, len(payload))
+	}
+	return image
+}
+
+func (p *ImageProcessor) cache() *Cache {
+	if p.downloader.cache != nil {
+		return p.downloader.cache
+	}
+	return DefaultCache()
+}
+
+// ProcessImagesWithOptions 下载图片后按 opts 做格式转换、方向校正、去除元数据、画幅适配与压缩；
+// opts 为 nil 时与 ProcessImages 相同，原图直接上传；否则超过 XHS_MCP_IMAGE_MAX_BYTES 的本地图片会先压缩到上限以内
+func (p *ImageProcessor) ProcessImagesWithOptions(images []string, opts *imageproc.Options) ([]string, error) {
+	if opts == nil {
+		return p.ProcessImages(images)
+	}
+	reports, err := p.processImagesReport(images, false)
+	if err != nil {
+		return nil, err
+	}
+
+	proc := imageproc.NewProcessor(p.downloader.savePath, configs.GetImageMaxBytes(), configs.GetImageMaxPixels())
+	out := make([]string, 0, len(reports))
+	for _, r := range reports {
+		res, err := proc.Process(r.Path, *opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res.Path)
+	}
+	return out, nil
+}
+
+// ProcessedVideo 可直接上传的本地视频
+type ProcessedVideo struct {
+	Path   string     `json:"path"`
+	Size   int64      `json:"size"`
+	SHA256 string     `json:"sha256,omitempty"`
+	Info   *VideoInfo `json:"info"`
+}
+
+// VideoProcessor 视频处理器
+type VideoProcessor struct {
+	downloader *VideoDownloader
+}
+
+// NewVideoProcessor 创建使用全局下载缓存的视频处理器
+func NewVideoProcessor() *VideoProcessor {
+	return NewVideoProcessorWithCache(DefaultCache())
+}
+
+// NewVideoProcessorWithCache 创建视频处理器，下载的视频放在 cache 目录下
+func NewVideoProcessorWithCache(cache *Cache) *VideoProcessor {
+	return &VideoProcessor{
+		downloader: NewVideoDownloader(filepath.Join(cache.Root(), configs.VideosDir)).WithCache(cache),
+	}
+}
+
+// ProcessVideo 处理视频：URL 先下载到本地，media:// 句柄与 data URI 解析为缓存中的文件，然后解析文件头并按平台限制校验，
+// 在启动浏览器前拒绝小红书不接受的文件
+func (p *VideoProcessor) ProcessVideo(video string) (*ProcessedVideo, error) {
+	out := &ProcessedVideo{Path: video}
+	switch {
+	case IsMediaHandle(video):
+		path, err := p.cache().ResolveHandle(video, MediaKindVideo)
+		if err != nil {
+			return nil, err
+		}
+		video, out.Path = path, path
+	case IsDataURI(video):
+		media, err := p.cache().StoreDataURI(video)
+		if err != nil {
+			return nil, fmt.Errorf("视频 data URI 无效: %w", err)
+		}
+		if media.Kind != MediaKindVideo {
+			return nil, fmt.Errorf("data URI 不是 MP4/MOV 视频: %s", media.MimeType)
+		}
+		video, out.Path = media.Path, media.Path
+	}
+	if IsImageURL(video) {
+		f, err := p.downloader.DownloadVideo(video)
+		if err != nil {
+			return nil, fmt.Errorf("下载视频失败 %s: %w", video, err)
+		}
+		out.Path, out.Size, out.SHA256 = f.Path, f.Size, f.SHA256
+	} else {
+		st, err := os.Stat(video)
+		if err != nil {
+			return nil, fmt.Errorf("视频文件不存在或不可访问: %w", err)
+		}
+		out.Size = st.Size()
+	}
+
+	if maxBytes := configs.GetVideoMaxBytes(); out.Size > maxBytes {
+		return nil, fmt.Errorf("视频文件过大: %d bytes (max %d)", out.Size, maxBytes)
+	}
+
+	info, err := ProbeVideo(out.Path)
+	if err != nil {
+		return nil, fmt.Errorf("无法识别视频文件: %w", err)
+	}
+	if err := ValidateVideo(info, configs.GetVideoMaxDuration()); err != nil {
+		return nil, err
+	}
+	out.Info = info
+	return out, nil
+}
+
+func (p *VideoProcessor) cache() *Cache {
+	if p.downloader.cache != nil {
+		return p.downloader.cache
+	}
+	return DefaultCache()
 }
@@ -0,0 +1,360 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// 单次下载中断后的最大续传次数
+const maxVideoDownloadAttempts = 5
+
+// VideoFile 下载完成的视频
+type VideoFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// VideoDownloader 视频下载器，支持断点续传
+type VideoDownloader struct {
+	savePath   string
+	maxBytes   int64
+	httpClient *http.Client
+	// cache 不为 nil 时下载完成的视频移入缓存，同一 URL 不重复下载
+	cache *Cache
+}
+
+// NewVideoDownloader 创建视频下载器
+func NewVideoDownloader(savePath string) *VideoDownloader {
+	if err := os.MkdirAll(savePath, 0755); err != nil {
+		panic(fmt.Sprintf("failed to create save path: %v", err))
+	}
+
+	return &VideoDownloader{
+		savePath: savePath,
+		maxBytes: configs.GetVideoMaxBytes(),
+		// 视频较大，不限制整体耗时，只限制建连与响应头
+		httpClient: DefaultFetchPolicy().newClient(0, &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			TLSHandshakeTimeout:   30 * time.Second,
+			ResponseHeaderTimeout: 60 * time.Second,
+		}),
+	}
+}
+
+// WithCache 使用下载缓存
+func (d *VideoDownloader) WithCache(cache *Cache) *VideoDownloader {
+	d.cache = cache
+	return d
+}
+
+// 同一临时文件同一时间只允许一个下载写入
+var partLocks sync.Map
+
+func lockPart(path string) func() {
+	v, _ := partLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// DownloadVideo 下载视频，中断后从已下载的位置用 Range 续传（用 If-Range 校验远端文件未变化），
+// 写入时同步计算 SHA256
+func (d *VideoDownloader) DownloadVideo(videoURL string) (*VideoFile, error) {
+	if !IsImageURL(videoURL) {
+		return nil, errors.New("invalid video URL format")
+	}
+	if f := d.lookupCache(videoURL); f != nil {
+		return f, nil
+	}
+
+	hash := sha256.Sum256([]byte(videoURL))
+	partPath := filepath.Join(d.savePath, fmt.Sprintf("vid_%x.part", hash[:8]))
+	unlock := lockPart(partPath)
+	defer unlock()
+	// 等锁期间同一 URL 可能已由其他请求下载完成
+	if f := d.lookupCache(videoURL); f != nil {
+		return f, nil
+	}
+
+	part, err := openVideoPart(partPath, videoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxVideoDownloadAttempts; attempt++ {
+		done, err := d.fetch(videoURL, part)
+		if err == nil && done {
+			break
+		}
+		var fatal *fatalDownloadError
+		if errors.As(err, &fatal) {
+			part.remove()
+			return nil, fatal.err
+		}
+		lastErr = err
+		logrus.Warnf("视频下载中断，准备续传 (%d/%d): %v", attempt, maxVideoDownloadAttempts, err)
+		if attempt == maxVideoDownloadAttempts {
+			return nil, errors.Wrapf(lastErr, "failed to download video from %s", videoURL)
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+
+	sum, size := hex.EncodeToString(part.hash.Sum(nil)), part.size
+	finalPath := strings.TrimSuffix(partPath, ".part") + videoExt(partPath)
+	if err := os.Rename(partPath, finalPath); err != nil {
+		return nil, errors.Wrap(err, "failed to save video")
+	}
+	os.Remove(part.metaPath())
+	if d.cache != nil {
+		cached, err := d.cache.PutFile(videoURL, finalPath, sum, filepath.Ext(finalPath))
+		if err != nil {
+			logrus.Warnf("视频写入下载缓存失败: %v", err)
+		} else {
+			finalPath = cached
+		}
+	}
+	logrus.Infof("视频下载完成: %s size=%d sha256=%s", finalPath, size, sum)
+	return &VideoFile{Path: finalPath, Size: size, SHA256: sum}, nil
+}
+
+func (d *VideoDownloader) lookupCache(videoURL string) *VideoFile {
+	if d.cache == nil {
+		return nil
+	}
+	path, ok := d.cache.Lookup(videoURL)
+	if !ok {
+		return nil
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	logrus.Infof("视频命中下载缓存: %s -> %s", videoURL, path)
+	sha := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &VideoFile{Path: path, Size: st.Size(), SHA256: sha}
+}
+
+// fatalDownloadError 不可通过续传恢复的错误（状态码、超过大小限制等）
+type fatalDownloadError struct{ err error }
+
+func (e *fatalDownloadError) Error() string { return e.err.Error() }
+
+// partMeta 与临时文件一起保存的远端文件校验信息，续传时通过 If-Range 确认文件未变化
+type partMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// ifRange 可用于 If-Range 的校验值：强 ETag 优先，其次 Last-Modified
+func (m partMeta) ifRange() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// videoPart 下载中的临时文件，写入的同时计算 SHA256
+type videoPart struct {
+	path string
+	meta partMeta
+	hash hash.Hash
+	size int64
+}
+
+func (p *videoPart) metaPath() string { return p.path + ".json" }
+
+// openVideoPart 打开已有的临时文件以便续传；没有可校验的远端信息时从头下载。
+// 上次进程留下的部分只在这里读取一次以恢复哈希状态。
+func openVideoPart(path, videoURL string) (*videoPart, error) {
+	p := &videoPart{path: path, hash: sha256.New()}
+	data, err := os.ReadFile(p.metaPath())
+	if err == nil && json.Unmarshal(data, &p.meta) == nil && p.meta.URL == videoURL && p.meta.ifRange() != "" {
+		if f, err := os.Open(path); err == nil {
+			n, err := io.Copy(p.hash, f)
+			f.Close()
+			if err == nil {
+				p.size = n
+				return p, nil
+			}
+		}
+	}
+	p.meta = partMeta{URL: videoURL}
+	if err := p.reset(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// reset 清空临时文件，从头下载
+func (p *videoPart) reset() error {
+	p.hash.Reset()
+	p.size = 0
+	if err := os.WriteFile(p.path, nil, 0644); err != nil {
+		return &fatalDownloadError{errors.Wrap(err, "failed to open video file")}
+	}
+	return nil
+}
+
+func (p *videoPart) saveMeta() {
+	data, _ := json.Marshal(p.meta)
+	if err := os.WriteFile(p.metaPath(), data, 0644); err != nil {
+		logrus.Warnf("保存视频续传信息失败: %v", err)
+	}
+}
+
+func (p *videoPart) remove() {
+	os.Remove(p.path)
+	os.Remove(p.metaPath())
+}
+
+// fetch 从临时文件已有的长度处继续下载，返回是否下载完整
+func (d *VideoDownloader) fetch(videoURL string, part *videoPart) (bool, error) {
+	// 上一次写入失败时文件可能多出未计入哈希的字节
+	if err := os.Truncate(part.path, part.size); err != nil {
+		return false, &fatalDownloadError{errors.Wrap(err, "failed to open video file")}
+	}
+	if part.size > 0 && part.meta.ifRange() == "" {
+		// 没有 ETag/Last-Modified 无法确认远端文件未变化，不能续传
+		if err := part.reset(); err != nil {
+			return false, err
+		}
+	}
+	offset := part.size
+
+	req, err := http.NewRequest("GET", videoURL, nil)
+	if err != nil {
+		return false, &fatalDownloadError{errors.Wrap(err, "failed to create request")}
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	if u, _ := url.Parse(videoURL); u != nil {
+		req.Header.Set("Referer", fmt.Sprintf("%s://%s/", u.Scheme, u.Host))
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// 远端文件已变化时服务端返回完整的 200 响应
+		req.Header.Set("If-Range", part.meta.ifRange())
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		var blocked *BlockedError
+		if errors.As(err, &blocked) {
+			return false, &fatalDownloadError{err}
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var total int64 = -1
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if start := contentRangeStart(resp.Header.Get("Content-Range")); start != offset {
+			// 服务端没有按请求的位置返回，拼接会损坏文件，从头重新下载
+			if err := part.reset(); err != nil {
+				return false, err
+			}
+			return false, fmt.Errorf("range response starts at %d, want %d", start, offset)
+		}
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		// 服务端不支持 Range 或文件已变化，从头开始
+		if err := part.reset(); err != nil {
+			return false, err
+		}
+		offset = 0
+		total = resp.ContentLength
+		part.meta.ETag = resp.Header.Get("ETag")
+		part.meta.LastModified = resp.Header.Get("Last-Modified")
+		part.saveMeta()
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 已下载完整
+		if offset > 0 && contentRangeTotal(resp.Header.Get("Content-Range")) == offset {
+			return true, nil
+		}
+		return false, &fatalDownloadError{fmt.Errorf("download failed with status %d for URL: %s", resp.StatusCode, videoURL)}
+	default:
+		return false, &fatalDownloadError{fmt.Errorf("download failed with status %d for URL: %s", resp.StatusCode, videoURL)}
+	}
+
+	if d.maxBytes > 0 && total > d.maxBytes {
+		return false, &fatalDownloadError{fmt.Errorf("video too large: %d bytes (max %d)", total, d.maxBytes)}
+	}
+
+	f, err := os.OpenFile(part.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return false, &fatalDownloadError{errors.Wrap(err, "failed to open video file")}
+	}
+	defer f.Close()
+
+	var reader io.Reader = resp.Body
+	if d.maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, d.maxBytes-offset+1)
+	}
+	// 先写文件再计入哈希，写入失败的数据块不会进入哈希，下次续传前截断到已计入的长度
+	n, err := io.Copy(io.MultiWriter(f, part.hash), reader)
+	part.size += n
+	if d.maxBytes > 0 && part.size > d.maxBytes {
+		return false, &fatalDownloadError{fmt.Errorf("video too large: more than %d bytes", d.maxBytes)}
+	}
+	if err != nil {
+		return false, err
+	}
+	if total > 0 && part.size < total {
+		return false, io.ErrUnexpectedEOF
+	}
+	return true, nil
+}
+
+// contentRangeStart 解析 "bytes 100-199/200" 中的起始位置，无法解析时返回 -1
+func contentRangeStart(v string) int64 {
+	v = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(v), "bytes"))
+	start, _, ok := strings.Cut(v, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// contentRangeTotal 解析 "bytes 0-99/200" 或 "bytes */200" 中的总长度，未知时返回 -1
+func contentRangeTotal(v string) int64 {
+	_, total, ok := strings.Cut(v, "/")
+	if !ok || total == "*" {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// videoExt 根据文件头选择扩展名
+func videoExt(path string) string {
+	if info, err := ProbeVideo(path); err == nil && info.Container == "mov" {
+		return ".mov"
+	}
+	return ".mp4"
+}
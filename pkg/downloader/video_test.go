@@ -0,0 +1,149 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoDownloader_ResumesAfterInterruption(t *testing.T) {
+	data := buildMP4("isom", "avc1", 720, 1280, 3)
+	data = append(data, bytes.Repeat([]byte{0}, 4096)...)
+	want := sha256.Sum256(data)
+
+	var calls, ranged int32
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 第一次只返回一半后断开
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data[:len(data)/2])
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(&ranged, 1)
+		}
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	d := NewVideoDownloader(t.TempDir())
+	f, err := d.DownloadVideo(srv.URL + "/v.mp4")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(len(data)), f.Size)
+	assert.Equal(t, hex.EncodeToString(want[:]), f.SHA256)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ranged), "second request should resume with Range")
+
+	got, err := os.ReadFile(f.Path)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestVideoDownloader_RestartsWhenRemoteChanged(t *testing.T) {
+	old := bytes.Repeat([]byte{1}, 4096)
+	data := bytes.Repeat([]byte{2}, 4096)
+	want := sha256.Sum256(data)
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 第一次返回旧版本的一半后断开
+			w.Header().Set("ETag", `"old"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(old)))
+			_, _ = w.Write(old[:len(old)/2])
+			return
+		}
+		assert.Equal(t, `"old"`, r.Header.Get("If-Range"))
+		// 文件已变化，If-Range 不匹配时 ServeContent 返回完整的 200
+		w.Header().Set("ETag", `"new"`)
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	d := NewVideoDownloader(t.TempDir())
+	f, err := d.DownloadVideo(srv.URL + "/v.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(want[:]), f.SHA256)
+
+	got, err := os.ReadFile(f.Path)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestVideoDownloader_RejectsMisplacedRange(t *testing.T) {
+	data := bytes.Repeat([]byte{3}, 4096)
+	want := sha256.Sum256(data)
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data[:len(data)/2])
+		case 2:
+			// 忽略请求的起始位置，从头返回 206
+			w.Header().Set("Content-Range", "bytes 0-4095/4096")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(data)
+		default:
+			http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(data))
+		}
+	}))
+	defer srv.Close()
+
+	d := NewVideoDownloader(t.TempDir())
+	f, err := d.DownloadVideo(srv.URL + "/v.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), f.Size)
+	assert.Equal(t, hex.EncodeToString(want[:]), f.SHA256)
+}
+
+func TestVideoDownloader_RespectsMaxBytes(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 2048)
+	// httptest 监听在回环地址，需要放开内网限制
+	t.Setenv("XHS_MCP_FETCH_ALLOW_PRIVATE", "true")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	t.Setenv("XHS_MCP_VIDEO_MAX_BYTES", "1024")
+
+	d := NewVideoDownloader(t.TempDir())
+	_, err := d.DownloadVideo(srv.URL + "/v.mp4")
+	assert.Error(t, err)
+}
+
+func TestContentRangeTotal(t *testing.T) {
+	assert.Equal(t, int64(200), contentRangeTotal("bytes 0-99/200"))
+	assert.Equal(t, int64(200), contentRangeTotal("bytes */200"))
+	assert.Equal(t, int64(-1), contentRangeTotal("bytes 0-99/*"))
+	assert.Equal(t, int64(-1), contentRangeTotal(""))
+}
+
+func TestContentRangeStart(t *testing.T) {
+	assert.Equal(t, int64(100), contentRangeStart("bytes 100-199/200"))
+	assert.Equal(t, int64(0), contentRangeStart("bytes 0-99/*"))
+	assert.Equal(t, int64(-1), contentRangeStart("bytes */200"))
+	assert.Equal(t, int64(-1), contentRangeStart(""))
+}
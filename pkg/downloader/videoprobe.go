@@ -0,0 +1,352 @@
+package downloader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// VideoInfo 从文件头解析出的视频信息
+type VideoInfo struct {
+	Container  string        `json:"container"` // mp4/mov
+	Brand      string        `json:"brand,omitempty"`
+	VideoCodec string        `json:"video_codec"`
+	AudioCodec string        `json:"audio_codec,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Width      int           `json:"width"`
+	Height     int           `json:"height"`
+	// Fragmented 分片 MP4（moov 中有 mvex），时长可能只能从 mehd 得到或完全缺失
+	Fragmented bool `json:"fragmented,omitempty"`
+}
+
+// 创作者中心接受 H.264/H.265 编码的 MP4/MOV
+var supportedVideoCodecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "h265",
+	"hev1": "h265",
+}
+
+// 分辨率短边下限，低于该值平台会拒绝或严重压糊
+const minVideoShortSide = 360
+
+// ValidateVideo 按小红书的上传限制检查视频，maxDuration<=0 表示不限制时长
+func ValidateVideo(info *VideoInfo, maxDuration time.Duration) error {
+	if info.Container != "mp4" && info.Container != "mov" {
+		return errors.Errorf("不支持的视频格式: %s（仅支持 MP4/MOV）", info.Container)
+	}
+	if info.VideoCodec == "" {
+		return errors.New("文件中没有视频轨道")
+	}
+	if _, ok := supportedVideoCodecs[info.VideoCodec]; !ok {
+		return errors.Errorf("不支持的视频编码: %s（仅支持 H.264/H.265）", info.VideoCodec)
+	}
+	// 分片 MP4 可以不写总时长（没有 mehd），此时跳过时长检查
+	if info.Duration <= 0 && !info.Fragmented {
+		return errors.New("无法读取视频时长，文件可能不完整")
+	}
+	if maxDuration > 0 && info.Duration > maxDuration {
+		return errors.Errorf("视频时长超过限制: %s（最长 %s）", info.Duration.Round(time.Second), maxDuration)
+	}
+	if info.Width <= 0 || info.Height <= 0 {
+		return errors.New("无法读取视频分辨率")
+	}
+	if min(info.Width, info.Height) < minVideoShortSide {
+		return errors.Errorf("视频分辨率过低: %dx%d（短边至少 %d）", info.Width, info.Height, minVideoShortSide)
+	}
+	return nil
+}
+
+// ProbeVideo 解析 MP4/MOV（ISO BMFF）文件头，读取容器、编码、时长与分辨率
+func ProbeVideo(path string) (*VideoInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "打开视频文件失败")
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "读取视频文件信息失败")
+	}
+	return probeISOBMFF(f, st.Size())
+}
+
+type box struct {
+	typ        string
+	start      int64 // 内容起始位置（跳过头部）
+	end        int64
+	headerSize int64
+}
+
+// readBox 读取 off 处的 box 头
+func readBox(r io.ReaderAt, off, limit int64) (box, error) {
+	var hdr [16]byte
+	if _, err := r.ReadAt(hdr[:8], off); err != nil {
+		return box{}, err
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[:4]))
+	b := box{typ: string(hdr[4:8]), headerSize: 8}
+	switch size {
+	case 0:
+		size = limit - off
+	case 1:
+		if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+			return box{}, err
+		}
+		size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+		b.headerSize = 16
+	}
+	if size < b.headerSize || off+size > limit {
+		return box{}, errors.Errorf("box %q 大小无效: %d", b.typ, size)
+	}
+	b.start = off + b.headerSize
+	b.end = off + size
+	return b, nil
+}
+
+// children 遍历 [start,end) 内的子 box
+func children(r io.ReaderAt, start, end int64, fn func(b box) error) error {
+	for off := start; off+8 <= end; {
+		b, err := readBox(r, off, end)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+		off = b.end
+	}
+	return nil
+}
+
+func probeISOBMFF(r io.ReaderAt, size int64) (*VideoInfo, error) {
+	info := &VideoInfo{}
+	var sawFtyp, sawMoov bool
+	var movieDuration time.Duration
+	var movieTimescale uint32
+	var fragmentDuration uint64
+
+	err := children(r, 0, size, func(b box) error {
+		switch b.typ {
+		case "ftyp":
+			sawFtyp = true
+			brand := make([]byte, 4)
+			if _, err := r.ReadAt(brand, b.start); err != nil {
+				return err
+			}
+			info.Brand = strings.TrimSpace(string(brand))
+			info.Container = "mp4"
+			if info.Brand == "qt" {
+				info.Container = "mov"
+			}
+		case "moov":
+			sawMoov = true
+			return children(r, b.start, b.end, func(c box) error {
+				switch c.typ {
+				case "mvhd":
+					timescale, duration, err := readMediaHeader(r, c)
+					if err != nil {
+						return err
+					}
+					movieTimescale = timescale
+					movieDuration = scaleDuration(duration, timescale)
+				case "mvex":
+					info.Fragmented = true
+					return children(r, c.start, c.end, func(e box) error {
+						if e.typ != "mehd" {
+							return nil
+						}
+						d, err := readFragmentDuration(r, e)
+						if err != nil {
+							return err
+						}
+						fragmentDuration = d
+						return nil
+					})
+				case "trak":
+					return readTrak(r, c, info)
+				}
+				return nil
+			})
+		case "moof":
+			info.Fragmented = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "解析视频文件失败")
+	}
+	if !sawFtyp {
+		// 老式 QuickTime 文件可能没有 ftyp
+		if !sawMoov {
+			return nil, errors.New("不是 MP4/MOV 文件")
+		}
+		info.Container = "mov"
+	}
+	if !sawMoov {
+		return nil, errors.New("视频缺少 moov 信息，文件可能不完整")
+	}
+	if movieDuration > 0 {
+		info.Duration = movieDuration
+	} else if fragmentDuration > 0 {
+		// 分片 MP4 的 mvhd 时长通常为 0，总时长记录在 mvex/mehd 中（单位为 mvhd 的 timescale）
+		info.Duration = scaleDuration(fragmentDuration, movieTimescale)
+	}
+	return info, nil
+}
+
+// readMediaDuration 解析 mvhd/mdhd 中的时长
+func readMediaDuration(r io.ReaderAt, b box) (time.Duration, error) {
+	timescale, duration, err := readMediaHeader(r, b)
+	if err != nil {
+		return 0, err
+	}
+	return scaleDuration(duration, timescale), nil
+}
+
+// readMediaHeader 解析 mvhd/mdhd 中的 timescale 与 duration
+func readMediaHeader(r io.ReaderAt, b box) (uint32, uint64, error) {
+	buf := make([]byte, 32)
+	n, _ := r.ReadAt(buf, b.start)
+	buf = buf[:n]
+	if len(buf) < 1 {
+		return 0, 0, errors.Errorf("%s 过短", b.typ)
+	}
+	var timescale uint32
+	var duration uint64
+	if buf[0] == 1 {
+		if len(buf) < 32 {
+			return 0, 0, errors.Errorf("%s 过短", b.typ)
+		}
+		timescale = binary.BigEndian.Uint32(buf[20:24])
+		duration = binary.BigEndian.Uint64(buf[24:32])
+	} else {
+		if len(buf) < 20 {
+			return 0, 0, errors.Errorf("%s 过短", b.typ)
+		}
+		timescale = binary.BigEndian.Uint32(buf[12:16])
+		duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+	}
+	return timescale, duration, nil
+}
+
+// readFragmentDuration 解析 mehd 中的 fragment_duration
+func readFragmentDuration(r io.ReaderAt, b box) (uint64, error) {
+	buf := make([]byte, 12)
+	n, _ := r.ReadAt(buf, b.start)
+	buf = buf[:n]
+	switch {
+	case len(buf) >= 12 && buf[0] == 1:
+		return binary.BigEndian.Uint64(buf[4:12]), nil
+	case len(buf) >= 8 && buf[0] == 0:
+		return uint64(binary.BigEndian.Uint32(buf[4:8])), nil
+	}
+	return 0, errors.New("mehd 过短")
+}
+
+func scaleDuration(duration uint64, timescale uint32) time.Duration {
+	if timescale == 0 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// readTrak 读取轨道类型、编码与分辨率
+func readTrak(r io.ReaderAt, trak box, info *VideoInfo) error {
+	var handler, codec string
+	var width, height int
+	var trackDuration time.Duration
+
+	var walk func(b box) error
+	walk = func(b box) error {
+		switch b.typ {
+		case "tkhd":
+			w, h, err := readTkhdSize(r, b)
+			if err != nil {
+				return err
+			}
+			width, height = w, h
+		case "mdhd":
+			d, err := readMediaDuration(r, b)
+			if err != nil {
+				return err
+			}
+			trackDuration = d
+		case "hdlr":
+			buf := make([]byte, 12)
+			if _, err := r.ReadAt(buf, b.start); err != nil {
+				return err
+			}
+			handler = string(buf[8:12])
+		case "mdia", "minf", "stbl":
+			return children(r, b.start, b.end, walk)
+		case "stsd":
+			// version/flags(4) + entry_count(4)，之后是第一个 sample entry
+			if b.end-b.start < 16 {
+				return nil
+			}
+			entry, err := readBox(r, b.start+8, b.end)
+			if err != nil {
+				return err
+			}
+			codec = entry.typ
+			// VisualSampleEntry: reserved(6) + data_reference_index(2) + 16 字节预留后是宽高
+			if entry.end-entry.start >= 28 {
+				buf := make([]byte, 4)
+				if _, err := r.ReadAt(buf, entry.start+24); err == nil {
+					if width == 0 || height == 0 {
+						width = int(binary.BigEndian.Uint16(buf[:2]))
+						height = int(binary.BigEndian.Uint16(buf[2:]))
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := children(r, trak.start, trak.end, walk); err != nil {
+		return err
+	}
+
+	switch handler {
+	case "vide":
+		if info.VideoCodec == "" {
+			info.VideoCodec = codec
+			info.Width, info.Height = width, height
+			if info.Duration == 0 {
+				info.Duration = trackDuration
+			}
+		}
+	case "soun":
+		if info.AudioCodec == "" {
+			info.AudioCodec = codec
+		}
+	}
+	return nil
+}
+
+// readTkhdSize 读取 tkhd 中 16.16 定点数的显示宽高
+func readTkhdSize(r io.ReaderAt, b box) (int, int, error) {
+	ver := make([]byte, 1)
+	if _, err := r.ReadAt(ver, b.start); err != nil {
+		return 0, 0, err
+	}
+	// v0 的宽高位于第 76 字节；v1 的时间字段为 64 位，后移 12 字节
+	off := int64(76)
+	if ver[0] == 1 {
+		off = 88
+	}
+	if b.end-b.start < off+8 {
+		return 0, 0, fmt.Errorf("tkhd 过短")
+	}
+	buf := make([]byte, 8)
+	if _, err := r.ReadAt(buf, b.start+off); err != nil {
+		return 0, 0, err
+	}
+	return int(binary.BigEndian.Uint32(buf[:4]) >> 16), int(binary.BigEndian.Uint32(buf[4:]) >> 16), nil
+}
@@ -0,0 +1,152 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out, uint32(8+len(body)))
+	copy(out[4:], typ)
+	return append(out, body...)
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// mediaHeader 构造 v0 的 mvhd/mdhd 内容
+func mediaHeader(timescale, duration uint32, pad int) []byte {
+	return bytes.Join([][]byte{u32(0), u32(0), u32(0), u32(timescale), u32(duration), make([]byte, pad)}, nil)
+}
+
+func tkhd(width, height uint32) []byte {
+	b := make([]byte, 84)
+	binary.BigEndian.PutUint32(b[76:], width<<16)
+	binary.BigEndian.PutUint32(b[80:], height<<16)
+	return b
+}
+
+func track(handler, codec string, width, height uint32) []byte {
+	entry := mp4Box(codec, make([]byte, 78))
+	return mp4Box("trak",
+		mp4Box("tkhd", tkhd(width, height)),
+		mp4Box("mdia",
+			mp4Box("mdhd", mediaHeader(1000, 12000, 4)),
+			mp4Box("hdlr", u32(0), u32(0), []byte(handler), make([]byte, 12)),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", u32(0), u32(1), entry))),
+		),
+	)
+}
+
+func buildMP4(brand, videoCodec string, width, height uint32, seconds uint32) []byte {
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte(brand), u32(0), []byte("isom")),
+		mp4Box("mdat", make([]byte, 64)),
+		mp4Box("moov",
+			mp4Box("mvhd", mediaHeader(600, seconds*600, 80)),
+			track("vide", videoCodec, width, height),
+			track("soun", "mp4a", 0, 0),
+		),
+	}, nil)
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, data, 0644))
+	return p
+}
+
+func TestProbeVideo(t *testing.T) {
+	path := writeTemp(t, "a.mp4", buildMP4("isom", "avc1", 1080, 1920, 15))
+
+	info, err := ProbeVideo(path)
+	require.NoError(t, err)
+	assert.Equal(t, "mp4", info.Container)
+	assert.Equal(t, "isom", info.Brand)
+	assert.Equal(t, "avc1", info.VideoCodec)
+	assert.Equal(t, "mp4a", info.AudioCodec)
+	assert.Equal(t, 15*time.Second, info.Duration)
+	assert.Equal(t, 1080, info.Width)
+	assert.Equal(t, 1920, info.Height)
+	assert.NoError(t, ValidateVideo(info, time.Hour))
+
+	mov, err := ProbeVideo(writeTemp(t, "b.mov", buildMP4("qt  ", "hvc1", 1920, 1080, 5)))
+	require.NoError(t, err)
+	assert.Equal(t, "mov", mov.Container)
+	assert.Equal(t, "hvc1", mov.VideoCodec)
+}
+
+func TestProbeVideo_Fragmented(t *testing.T) {
+	fragmented := func(mvex []byte) []byte {
+		return bytes.Join([][]byte{
+			mp4Box("ftyp", []byte("iso5"), u32(0), []byte("isom")),
+			mp4Box("moov",
+				mp4Box("mvhd", mediaHeader(1000, 0, 80)),
+				mp4Box("trak",
+					mp4Box("tkhd", tkhd(1080, 1920)),
+					mp4Box("mdia",
+						mp4Box("mdhd", mediaHeader(1000, 0, 4)),
+						mp4Box("hdlr", u32(0), u32(0), []byte("vide"), make([]byte, 12)),
+						mp4Box("minf", mp4Box("stbl", mp4Box("stsd", u32(0), u32(1), mp4Box("avc1", make([]byte, 78))))),
+					),
+				),
+				mvex,
+			),
+			mp4Box("moof", mp4Box("mfhd", u32(0), u32(1))),
+			mp4Box("mdat", make([]byte, 64)),
+		}, nil)
+	}
+
+	withMehd, err := ProbeVideo(writeTemp(t, "f.mp4", fragmented(mp4Box("mvex", mp4Box("mehd", u32(0), u32(20000))))))
+	require.NoError(t, err)
+	assert.True(t, withMehd.Fragmented)
+	assert.Equal(t, 20*time.Second, withMehd.Duration)
+	assert.NoError(t, ValidateVideo(withMehd, time.Hour))
+	assert.Error(t, ValidateVideo(withMehd, 10*time.Second))
+
+	noMehd, err := ProbeVideo(writeTemp(t, "g.mp4", fragmented(mp4Box("mvex", mp4Box("trex", make([]byte, 24))))))
+	require.NoError(t, err)
+	assert.True(t, noMehd.Fragmented)
+	assert.Zero(t, noMehd.Duration)
+	assert.NoError(t, ValidateVideo(noMehd, time.Hour))
+}
+
+func TestProbeVideo_NotVideo(t *testing.T) {
+	_, err := ProbeVideo(writeTemp(t, "x.mp4", []byte("definitely not a video file")))
+	assert.Error(t, err)
+}
+
+func TestValidateVideo(t *testing.T) {
+	ok := &VideoInfo{Container: "mp4", VideoCodec: "avc1", Duration: time.Minute, Width: 720, Height: 1280}
+	assert.NoError(t, ValidateVideo(ok, time.Hour))
+
+	prores := *ok
+	prores.VideoCodec = "apch"
+	assert.Error(t, ValidateVideo(&prores, time.Hour))
+
+	long := *ok
+	long.Duration = 2 * time.Hour
+	assert.Error(t, ValidateVideo(&long, time.Hour))
+	assert.NoError(t, ValidateVideo(&long, 0))
+
+	tiny := *ok
+	tiny.Width, tiny.Height = 320, 240
+	assert.Error(t, ValidateVideo(&tiny, time.Hour))
+
+	noVideo := *ok
+	noVideo.VideoCodec = ""
+	assert.Error(t, ValidateVideo(&noVideo, time.Hour))
+}
@@ -0,0 +1,338 @@
+package fingerprint
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// 指纹模板对应的操作系统
+const (
+	OSMac     = "mac"
+	OSWindows = "windows"
+	OSLinux   = "linux"
+)
+
+// Profile 账号的浏览器指纹，生成后随账号保存在 users.json 中保持不变。
+type Profile struct {
+	UserAgent         string   `json:"user_agent"`
+	Platform          string   `json:"platform"`
+	ViewportWidth     int      `json:"viewport_width"`
+	ViewportHeight    int      `json:"viewport_height"`
+	DeviceScaleFactor float64  `json:"device_scale_factor"`
+	Languages         []string `json:"languages"`
+	Timezone          string   `json:"timezone"`
+	WebGLVendor       string   `json:"webgl_vendor"`
+	WebGLRenderer     string   `json:"webgl_renderer"`
+	// BrowserVersion 实际运行的 Chrome 完整版本号，由 ForBrowser 填入，不保存
+	BrowserVersion string `json:"-"`
+}
+
+type gpu struct {
+	vendor   string
+	renderer string
+}
+
+type osTemplate struct {
+	os        string
+	uaFormat  string
+	platform  string
+	scales    []float64
+	viewports [][2]int
+	gpus      []gpu
+}
+
+var chromeVersions = []string{"122.0.0.0", "123.0.0.0", "124.0.0.0", "125.0.0.0", "126.0.0.0"}
+
+// UA 精简后只保留主版本号，Client Hints 的完整版本号使用各主版本的稳定版
+var chromeFullVersions = map[string]string{
+	"122": "122.0.6261.129",
+	"123": "123.0.6312.122",
+	"124": "124.0.6367.207",
+	"125": "125.0.6422.142",
+	"126": "126.0.6478.127",
+}
+
+// 冻结的 UA 中不含真实系统版本，Client Hints 按平台给出固定值
+var clientHintPlatforms = map[string][2]string{
+	"MacIntel": {"macOS", "14.5.0"},
+	"Win32":    {"Windows", "15.0.0"},
+}
+
+var chromeVersionRe = regexp.MustCompile(`Chrome/(\d+)(\.[\d.]+)?`)
+
+// templates Generate 从中挑选的系统模板
+var templates = []osTemplate{
+	{
+		os:       OSMac,
+		uaFormat: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+		platform: "MacIntel",
+		scales:   []float64{2},
+		viewports: [][2]int{
+			{1440, 900}, {1512, 982}, {1536, 960}, {1680, 1050}, {1728, 1117},
+		},
+		gpus: []gpu{
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M1, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M1 Pro, Unspecified Version)"},
+			{"Google Inc. (Intel Inc.)", "ANGLE (Intel Inc., Intel(R) Iris(TM) Plus Graphics 655, OpenGL 4.1)"},
+		},
+	},
+	{
+		os:       OSWindows,
+		uaFormat: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+		platform: "Win32",
+		scales:   []float64{1, 1.25, 1.5},
+		viewports: [][2]int{
+			{1366, 768}, {1536, 864}, {1600, 900}, {1920, 1080}, {1280, 720},
+		},
+		gpus: []gpu{
+			{"Google Inc. (NVIDIA)", "ANGLE (NVIDIA, NVIDIA GeForce GTX 1660 SUPER Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (NVIDIA)", "ANGLE (NVIDIA, NVIDIA GeForce RTX 3060 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (Intel)", "ANGLE (Intel, Intel(R) UHD Graphics 630 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon RX 580 Series Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+		},
+	},
+}
+
+// linuxTemplate 只在实际运行于 Linux 时使用，Generate 不会生成
+var linuxTemplate = osTemplate{
+	os:       OSLinux,
+	uaFormat: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+	platform: "Linux x86_64",
+	scales:   []float64{1},
+	viewports: [][2]int{
+		{1366, 768}, {1600, 900}, {1920, 1080}, {1280, 800},
+	},
+	gpus: []gpu{
+		{"Google Inc. (Intel)", "ANGLE (Intel, Mesa Intel(R) UHD Graphics 620 (KBL GT2), OpenGL 4.6)"},
+		{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon RX 580 Series (radeonsi, polaris10, LLVM 15.0.7), OpenGL 4.6)"},
+		{"Google Inc. (NVIDIA Corporation)", "ANGLE (NVIDIA Corporation, NVIDIA GeForce GTX 1650/PCIe/SSE2, OpenGL 4.5.0)"},
+	},
+}
+
+// templateFor 按操作系统查找模板
+func templateFor(os string) (osTemplate, bool) {
+	if os == OSLinux {
+		return linuxTemplate, true
+	}
+	for _, t := range templates {
+		if t.os == os {
+			return t, true
+		}
+	}
+	return osTemplate{}, false
+}
+
+// HostOS 本机的操作系统，用于本机启动的浏览器
+func HostOS() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return OSMac
+	case "windows":
+		return OSWindows
+	case "linux":
+		return OSLinux
+	}
+	return ""
+}
+
+// OSFromUserAgent 从浏览器真实的 UA 判断操作系统，无法识别时返回空
+func OSFromUserAgent(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return OSWindows
+	case strings.Contains(ua, "Macintosh"):
+		return OSMac
+	case strings.Contains(ua, "Android"):
+		return ""
+	case strings.Contains(ua, "Linux"):
+		return OSLinux
+	}
+	return ""
+}
+
+// OS 指纹声明的操作系统
+func (p Profile) OS() string {
+	switch {
+	case p.Platform == "MacIntel":
+		return OSMac
+	case p.Platform == "Win32":
+		return OSWindows
+	case strings.HasPrefix(p.Platform, "Linux"):
+		return OSLinux
+	}
+	return ""
+}
+
+// ForBrowser 让指纹与实际运行的浏览器一致：UA 与 Client Hints 使用 product（如 "Chrome/126.0.6478.126"）
+// 中的真实版本；os 与指纹的系统不同时换用该系统的模板，视口、缩放与 WebGL 由原指纹确定，同一账号结果固定。
+// product 或 os 为空时保留对应部分。
+func (p Profile) ForBrowser(product, os string) Profile {
+	m := chromeVersionRe.FindStringSubmatch(p.UserAgent)
+	if p.IsZero() || m == nil {
+		return p
+	}
+	version := m[1] + m[2]
+	if v := chromeVersionRe.FindStringSubmatch(product); v != nil && v[2] != "" {
+		version = v[1] + ".0.0.0"
+		p.BrowserVersion = v[1] + v[2]
+	}
+
+	cur := p.OS()
+	if os == "" {
+		os = cur
+	}
+	t, ok := templateFor(os)
+	if !ok {
+		return p
+	}
+	if os != cur {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(p.Signature()))
+		r := rand.New(rand.NewPCG(h.Sum64(), 0x9e3779b97f4a7c15))
+		vp := t.viewports[r.IntN(len(t.viewports))]
+		g := t.gpus[r.IntN(len(t.gpus))]
+		p.Platform = t.platform
+		p.ViewportWidth, p.ViewportHeight = vp[0], vp[1]
+		p.DeviceScaleFactor = t.scales[r.IntN(len(t.scales))]
+		p.WebGLVendor, p.WebGLRenderer = g.vendor, g.renderer
+	}
+	p.UserAgent = fmt.Sprintf(t.uaFormat, version)
+	return p
+}
+
+var languageSets = [][]string{
+	{"zh-CN", "zh"},
+	{"zh-CN", "zh", "en"},
+	{"zh-CN", "zh", "en-US", "en"},
+}
+
+// Generate 根据 seed（通常为账号名）生成指纹，同一 seed 结果固定。
+func Generate(seed string) Profile {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed))
+	r := rand.New(rand.NewPCG(h.Sum64(), 0x9e3779b97f4a7c15))
+
+	t := templates[r.IntN(len(templates))]
+	vp := t.viewports[r.IntN(len(t.viewports))]
+	g := t.gpus[r.IntN(len(t.gpus))]
+	langs := languageSets[r.IntN(len(languageSets))]
+
+	return Profile{
+		UserAgent:         fmt.Sprintf(t.uaFormat, chromeVersions[r.IntN(len(chromeVersions))]),
+		Platform:          t.platform,
+		ViewportWidth:     vp[0],
+		ViewportHeight:    vp[1],
+		DeviceScaleFactor: t.scales[r.IntN(len(t.scales))],
+		Languages:         append([]string(nil), langs...),
+		Timezone:          "Asia/Shanghai",
+		WebGLVendor:       g.vendor,
+		WebGLRenderer:     g.renderer,
+	}
+}
+
+// IsZero 指纹是否未设置。
+func (p Profile) IsZero() bool {
+	return p.UserAgent == ""
+}
+
+// AcceptLanguage 返回与 Languages 一致的 Accept-Language 头，如 "zh-CN,zh;q=0.9,en;q=0.8"。
+func (p Profile) AcceptLanguage() string {
+	parts := make([]string, 0, len(p.Languages))
+	for i, l := range p.Languages {
+		if i == 0 {
+			parts = append(parts, l)
+			continue
+		}
+		q := 1.0 - float64(i)*0.1
+		if q < 0.1 {
+			q = 0.1
+		}
+		parts = append(parts, fmt.Sprintf("%s;q=%.1f", l, q))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Locale 首选语言。
+func (p Profile) Locale() string {
+	if len(p.Languages) == 0 {
+		return ""
+	}
+	return p.Languages[0]
+}
+
+// Signature 指纹摘要，用于判断常驻浏览器是否需要重建。
+func (p Profile) Signature() string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s|%s|%dx%d@%g|%s|%s|%s|%s",
+		p.UserAgent, p.Platform, p.ViewportWidth, p.ViewportHeight, p.DeviceScaleFactor,
+		strings.Join(p.Languages, ","), p.Timezone, p.WebGLVendor, p.WebGLRenderer)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Brand Client Hints 中的品牌与版本
+type Brand struct {
+	Brand   string
+	Version string
+}
+
+// ClientHints 与 UserAgent 一致的 UA Client Hints（navigator.userAgentData 与 Sec-CH-UA 请求头）
+type ClientHints struct {
+	Brands          []Brand
+	FullVersionList []Brand
+	FullVersion     string
+	Platform        string
+	PlatformVersion string
+	Architecture    string
+	Bitness         string
+	Mobile          bool
+}
+
+// ClientHints 由 UserAgent、Platform 与 WebGL 信息推导 Client Hints，UA 不是 Chrome 时返回 nil。
+func (p Profile) ClientHints() *ClientHints {
+	m := chromeVersionRe.FindStringSubmatch(p.UserAgent)
+	if m == nil {
+		return nil
+	}
+	major := m[1]
+	full := chromeFullVersions[major]
+	if strings.HasPrefix(p.BrowserVersion, major+".") {
+		full = p.BrowserVersion
+	}
+	if full == "" {
+		full = major + m[2]
+	}
+
+	platform, version := "Unknown", ""
+	if v, ok := clientHintPlatforms[p.Platform]; ok {
+		platform, version = v[0], v[1]
+	} else if strings.HasPrefix(p.Platform, "Linux") {
+		platform = "Linux"
+	}
+	arch := "x86"
+	if strings.Contains(p.WebGLRenderer, "Apple M") {
+		arch = "arm"
+	}
+
+	return &ClientHints{
+		Brands: []Brand{
+			{Brand: "Google Chrome", Version: major},
+			{Brand: "Chromium", Version: major},
+			{Brand: "Not-A.Brand", Version: "99"},
+		},
+		FullVersionList: []Brand{
+			{Brand: "Google Chrome", Version: full},
+			{Brand: "Chromium", Version: full},
+			{Brand: "Not-A.Brand", Version: "99.0.0.0"},
+		},
+		FullVersion:     full,
+		Platform:        platform,
+		PlatformVersion: version,
+		Architecture:    arch,
+		Bitness:         "64",
+	}
+}
@@ -0,0 +1,79 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_StablePerSeed(t *testing.T) {
+	a1 := Generate("alice")
+	a2 := Generate("alice")
+	require.Equal(t, a1, a2)
+	require.False(t, a1.IsZero())
+	require.NotEmpty(t, a1.WebGLRenderer)
+	require.Equal(t, "Asia/Shanghai", a1.Timezone)
+
+	distinct := map[string]bool{}
+	for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		distinct[Generate(seed).Signature()] = true
+	}
+	require.Greater(t, len(distinct), 1)
+}
+
+func TestProfile_AcceptLanguage(t *testing.T) {
+	p := Profile{Languages: []string{"zh-CN", "zh", "en"}}
+	require.Equal(t, "zh-CN,zh;q=0.9,en;q=0.8", p.AcceptLanguage())
+	require.Equal(t, "zh-CN", p.Locale())
+}
+
+func TestProfile_ClientHintsMatchUserAgent(t *testing.T) {
+	mac := Profile{
+		UserAgent:     "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		Platform:      "MacIntel",
+		WebGLRenderer: "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)",
+	}
+	h := mac.ClientHints()
+	require.NotNil(t, h)
+	require.Equal(t, Brand{Brand: "Google Chrome", Version: "124"}, h.Brands[0])
+	require.Equal(t, "124.0.6367.207", h.FullVersion)
+	require.Equal(t, "macOS", h.Platform)
+	require.Equal(t, "arm", h.Architecture)
+
+	win := Generate("alice")
+	win.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	win.Platform = "Win32"
+	h = win.ClientHints()
+	require.Equal(t, "Windows", h.Platform)
+	require.Equal(t, "126", h.Brands[0].Version)
+
+	require.Nil(t, Profile{UserAgent: "curl/8.0"}.ClientHints())
+}
+
+func TestProfile_ForBrowserMatchesRunningChrome(t *testing.T) {
+	mac := Profile{
+		UserAgent:     "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		Platform:      "MacIntel",
+		Languages:     []string{"zh-CN"},
+		WebGLRenderer: "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)",
+	}
+
+	// 系统一致时只替换版本号
+	p := mac.ForBrowser("HeadlessChrome/131.0.6778.85", OSMac)
+	require.Equal(t, "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36", p.UserAgent)
+	require.Equal(t, "MacIntel", p.Platform)
+	h := p.ClientHints()
+	require.Equal(t, "131", h.Brands[0].Version)
+	require.Equal(t, "131.0.6778.85", h.FullVersion)
+
+	// 实际运行在 Linux 上时换用 Linux 模板，同一指纹结果固定
+	linux := mac.ForBrowser("Chrome/131.0.6778.85", OSFromUserAgent("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/131.0.0.0 Safari/537.36"))
+	require.Equal(t, OSLinux, linux.OS())
+	require.Contains(t, linux.UserAgent, "X11; Linux x86_64")
+	require.Contains(t, linux.WebGLRenderer, "OpenGL")
+	require.Equal(t, "Linux", linux.ClientHints().Platform)
+	require.Equal(t, linux, mac.ForBrowser("Chrome/131.0.6778.85", OSLinux))
+
+	// 版本与系统未知时保持原样
+	require.Equal(t, mac, mac.ForBrowser("", ""))
+}
@@ -0,0 +1,233 @@
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// 输入格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+	FormatBMP  = "bmp"
+	FormatTIFF = "tiff"
+	FormatHEIC = "heic"
+	FormatAVIF = "avif"
+)
+
+// sniffFormat 根据文件头判断图片格式
+func sniffFormat(data []byte) string {
+	switch {
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return FormatJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return FormatGIF
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP
+	case bytes.HasPrefix(data, []byte("BM")):
+		return FormatBMP
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return FormatTIFF
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return isobmffImageFormat(data)
+	}
+	return ""
+}
+
+// isobmffImageFormat 通过 ftyp 的主品牌与兼容品牌区分 HEIC 与 AVIF
+func isobmffImageFormat(data []byte) string {
+	size := int(data[0])<<24 | int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+	if size < 16 || size > len(data) {
+		size = min(len(data), 64)
+	}
+	var brands []string
+	for off := 8; off+4 <= size; off += 4 {
+		if off == 12 {
+			continue // minor_version
+		}
+		brands = append(brands, string(data[off:off+4]))
+	}
+	for _, b := range brands {
+		switch b {
+		case "avif", "avis":
+			return FormatAVIF
+		case "heic", "heix", "hevc", "hevx", "heim", "heis":
+			return FormatHEIC
+		}
+	}
+	return ""
+}
+
+// ErrUnsupportedFormat 无法识别的图片格式，或本机缺少转换 HEIC/AVIF 所需的工具
+var ErrUnsupportedFormat = errors.New("不支持的图片格式")
+
+// decodeConfig 只读取文件头中的宽高，用于在解码前拒绝像素数过大的图片。
+// HEIC/AVIF 从 meta 中的 ispe 属性读取
+func decodeConfig(data []byte, format string) (image.Config, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case FormatJPEG:
+		return jpeg.DecodeConfig(r)
+	case FormatPNG:
+		return png.DecodeConfig(r)
+	case FormatGIF:
+		return gif.DecodeConfig(r)
+	case FormatWebP:
+		return webp.DecodeConfig(r)
+	case FormatBMP:
+		return bmp.DecodeConfig(r)
+	case FormatTIFF:
+		return tiff.DecodeConfig(r)
+	case FormatHEIC, FormatAVIF:
+		props := readHEIFProps(data)
+		if props.width <= 0 || props.height <= 0 {
+			return image.Config{}, errors.New("无法读取图片尺寸")
+		}
+		return image.Config{Width: props.width, Height: props.height}, nil
+	}
+	return image.Config{}, errors.Wrap(ErrUnsupportedFormat, "无法识别的图片格式")
+}
+
+// checkPixels 像素数超过 maxPixels（<=0 不限制）时拒绝解码，避免解压炸弹耗尽内存
+func checkPixels(cfg image.Config, maxPixels int64) error {
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return errors.Errorf("图片像素过多: %dx%d（上限 %d 像素）", cfg.Width, cfg.Height, maxPixels)
+	}
+	return nil
+}
+
+// decode 解码图片；HEIC/AVIF 没有纯 Go 解码器，交给本机的 ImageMagick / ffmpeg 转换。
+// oriented 为 true 表示转换工具已按方向信息旋转过，不需要再校正
+func decode(path string, data []byte, maxPixels int64) (img image.Image, format string, oriented bool, err error) {
+	format = sniffFormat(data)
+	cfg, err := decodeConfig(data, format)
+	if err != nil {
+		return nil, format, false, errors.Wrapf(err, "读取 %s 图片信息失败", format)
+	}
+	if err := checkPixels(cfg, maxPixels); err != nil {
+		return nil, format, false, err
+	}
+
+	r := bytes.NewReader(data)
+	switch format {
+	case FormatJPEG:
+		img, err = jpeg.Decode(r)
+	case FormatPNG:
+		img, err = png.Decode(r)
+	case FormatGIF:
+		img, err = gif.Decode(r) // 动图只取第一帧
+	case FormatWebP:
+		img, err = webp.Decode(r)
+	case FormatBMP:
+		img, err = bmp.Decode(r)
+	case FormatTIFF:
+		img, err = tiff.Decode(r)
+	case FormatHEIC, FormatAVIF:
+		img, oriented, err = decodeExternal(path, format, maxPixels)
+		if errors.Is(err, ErrUnsupportedFormat) {
+			return nil, format, false, err
+		}
+	}
+	if err != nil {
+		return nil, format, false, errors.Wrapf(err, "解码 %s 图片失败", format)
+	}
+	return img, format, oriented, nil
+}
+
+// externalConverter 外部转换命令；args 中的 {in}/{out} 替换为输入/输出路径
+type externalConverter struct {
+	args []string
+	// orients 转换时是否已按 irot/EXIF 旋转输出
+	orients bool
+}
+
+// 外部转换命令，按顺序尝试。ImageMagick 的 -auto-orient 与 libheif 会按 irot/EXIF 校正方向，
+// ffmpeg、sips 输出的是未旋转的原始画面，由调用方按 irot 校正
+var externalConverters = []externalConverter{
+	{args: []string{"magick", "{in}", "-auto-orient", "{out}"}, orients: true},
+	{args: []string{"convert", "{in}", "-auto-orient", "{out}"}, orients: true},
+	{args: []string{"heif-convert", "{in}", "{out}"}, orients: true},
+	{args: []string{"ffmpeg", "-v", "error", "-y", "-noautorotate", "-i", "{in}", "-frames:v", "1", "{out}"}},
+	{args: []string{"sips", "-s", "format", "png", "{in}", "--out", "{out}"}},
+}
+
+// ExternalDecoders 返回本机可用的 HEIC/AVIF 转换工具；为空时 HEIC/AVIF 图片会以 ErrUnsupportedFormat 拒绝
+func ExternalDecoders() []string {
+	var found []string
+	for _, c := range externalConverters {
+		if _, err := exec.LookPath(c.args[0]); err == nil {
+			found = append(found, c.args[0])
+		}
+	}
+	return found
+}
+
+// decodeExternal 用本机工具把 HEIC/AVIF 转成 PNG 后解码
+func decodeExternal(path, format string, maxPixels int64) (image.Image, bool, error) {
+	dir, err := os.MkdirTemp("", "xhs-imageproc-*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out.png")
+
+	var tried []string
+	for _, c := range externalConverters {
+		tmpl := c.args
+		bin, err := exec.LookPath(tmpl[0])
+		if err != nil {
+			continue
+		}
+		tried = append(tried, tmpl[0])
+		args := make([]string, 0, len(tmpl)-1)
+		for _, a := range tmpl[1:] {
+			switch a {
+			case "{in}":
+				a = path
+			case "{out}":
+				a = out
+			}
+			args = append(args, a)
+		}
+		if msg, err := exec.Command(bin, args...).CombinedOutput(); err != nil {
+			logrus.Debugf("%s 转换 %s 失败: %v %s", tmpl[0], path, err, msg)
+			continue
+		}
+		converted, err := os.ReadFile(out)
+		if err != nil {
+			continue
+		}
+		// 转换结果同样要检查像素数，ispe 可能与实际画面不一致
+		cfg, err := png.DecodeConfig(bytes.NewReader(converted))
+		if err != nil {
+			continue
+		}
+		if err := checkPixels(cfg, maxPixels); err != nil {
+			return nil, false, err
+		}
+		img, err := png.Decode(bytes.NewReader(converted))
+		if err == nil {
+			return img, c.orients, nil
+		}
+	}
+	if len(tried) == 0 {
+		return nil, false, errors.Wrapf(ErrUnsupportedFormat, "%s 需要本机安装 ImageMagick、libheif 或 ffmpeg 才能转换，也可以先自行转为 JPEG", format)
+	}
+	return nil, false, errors.Errorf("%s 转换失败（已尝试 %v）", format, tried)
+}
@@ -0,0 +1,238 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// jpegOrientation 读取 JPEG APP1 中 EXIF 的 Orientation（1-8），没有时返回 1
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for off := 2; off+4 <= len(data); {
+		if data[off] != 0xFF {
+			return 1
+		}
+		marker := data[off+1]
+		// SOS 之后是图像数据，EXIF 只会出现在此之前
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[off+2 : off+4]))
+		if size < 2 || off+2+size > len(data) {
+			return 1
+		}
+		seg := data[off+4 : off+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return tiffOrientation(seg[6:])
+		}
+		off += 2 + size
+	}
+	return 1
+}
+
+// sourceOrientation 读取原图的方向（1-8，与 EXIF Orientation 一致），没有时返回 1
+func sourceOrientation(format string, data []byte) int {
+	switch format {
+	case FormatJPEG:
+		return jpegOrientation(data)
+	case FormatTIFF:
+		return tiffOrientation(data)
+	case FormatWebP:
+		return webpOrientation(data)
+	case FormatHEIC, FormatAVIF:
+		// HEIF 以 irot 属性为准，EXIF 中的方向只是参考
+		return readHEIFProps(data).orientation()
+	}
+	return 1
+}
+
+// webpOrientation 读取 WebP 中 EXIF 块的 Orientation
+func webpOrientation(data []byte) int {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 1
+	}
+	for off := 12; off+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		if size < 0 || off+8+size > len(data) {
+			return 1
+		}
+		if string(data[off:off+4]) == "EXIF" {
+			// 部分编码器会保留 JPEG APP1 的 "Exif\0\0" 前缀
+			return tiffOrientation(bytes.TrimPrefix(data[off+8:off+8+size], []byte("Exif\x00\x00")))
+		}
+		off += 8 + size + size%2
+	}
+	return 1
+}
+
+// heifProps 从 HEIF/AVIF 的 meta/iprp/ipco 中读取的图片属性
+type heifProps struct {
+	width, height int
+	// rotation irot 的逆时针旋转角度（90° 的倍数）
+	rotation int
+}
+
+// orientation 把 irot 的逆时针旋转换算成对应的 EXIF Orientation
+func (p heifProps) orientation() int {
+	switch p.rotation {
+	case 1:
+		return 8
+	case 2:
+		return 3
+	case 3:
+		return 6
+	}
+	return 1
+}
+
+// readHEIFProps 解析 HEIF/AVIF 的尺寸与旋转。文件中可能有缩略图、网格分块等多个 ispe，
+// 取面积最大的作为主图尺寸；irot 取第一个
+func readHEIFProps(data []byte) heifProps {
+	var props heifProps
+	rotated := false
+	heifBoxes(data, func(typ string, body []byte) {
+		if typ != "meta" || len(body) < 4 {
+			return
+		}
+		// meta 是 full box，跳过 version/flags
+		heifBoxes(body[4:], func(typ string, body []byte) {
+			if typ != "iprp" {
+				return
+			}
+			heifBoxes(body, func(typ string, body []byte) {
+				if typ != "ipco" {
+					return
+				}
+				heifBoxes(body, func(typ string, body []byte) {
+					switch {
+					case typ == "ispe" && len(body) >= 12:
+						w := int(binary.BigEndian.Uint32(body[4:8]))
+						h := int(binary.BigEndian.Uint32(body[8:12]))
+						if w*h > props.width*props.height {
+							props.width, props.height = w, h
+						}
+					case typ == "irot" && len(body) >= 1 && !rotated:
+						props.rotation = int(body[0] & 3)
+						rotated = true
+					}
+				})
+			})
+		})
+	})
+	return props
+}
+
+// heifBoxes 遍历 data 中的顶层 box
+func heifBoxes(data []byte, fn func(typ string, body []byte)) {
+	for off := 0; off+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[off : off+4]))
+		typ := string(data[off+4 : off+8])
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data) - off)
+		case 1:
+			if off+16 > len(data) {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[off+8 : off+16])
+			hdr = 16
+		}
+		if size < hdr || size > uint64(len(data)-off) {
+			return
+		}
+		fn(typ, data[off+int(hdr):off+int(size)])
+		off += int(size)
+	}
+}
+
+// tiffOrientation 在 TIFF 结构的 IFD0 中查找 0x0112 标签
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+	var bo binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(bo.Uint32(t[4:8]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	n := int(bo.Uint16(t[ifd : ifd+2]))
+	for i := 0; i < n; i++ {
+		e := ifd + 2 + i*12
+		if e+12 > len(t) {
+			return 1
+		}
+		if bo.Uint16(t[e:e+2]) == 0x0112 {
+			v := int(bo.Uint16(t[e+8 : e+10]))
+			if v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation 按 EXIF Orientation 旋转/翻转图片，使其以正确方向显示
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// 5-8 需要交换宽高
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	rgba := toRGBA(src)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			}
+			si := rgba.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], rgba.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// toRGBA 转换为从 (0,0) 开始的 RGBA 图像
+func toRGBA(src image.Image) *image.RGBA {
+	if r, ok := src.(*image.RGBA); ok && r.Rect.Min == (image.Point{}) {
+		return r
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,127 @@
+package imageproc
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 小红书图文支持的画幅
+const (
+	Aspect3x4  = "3:4"
+	Aspect1x1  = "1:1"
+	Aspect4x3  = "4:3"
+	AspectAuto = "auto" // 选择与原图最接近的画幅
+)
+
+// 适配画幅的方式
+const (
+	FitPad  = "pad"  // 补边，保留完整画面
+	FitCrop = "crop" // 按画面细节智能裁剪
+)
+
+const (
+	defaultMaxSide = 4096
+	defaultQuality = 90
+)
+
+var aspectRatios = map[string]float64{
+	Aspect3x4: 3.0 / 4.0,
+	Aspect1x1: 1,
+	Aspect4x3: 4.0 / 3.0,
+}
+
+// Options 上传前的图片处理参数，零值表示只做格式转换、EXIF 方向校正与元数据清除
+type Options struct {
+	Aspect     string `json:"aspect,omitempty" jsonschema:"目标画幅：3:4 / 1:1 / 4:3 / auto（取最接近的画幅），为空保持原比例"`
+	Fit        string `json:"fit,omitempty" jsonschema:"画幅适配方式：pad 补边（默认）/ crop 智能裁剪"`
+	Background string `json:"background,omitempty" jsonschema:"补边及透明区域的颜色，如 #FFFFFF（默认白色）"`
+	MaxWidth   int    `json:"max_width,omitempty" jsonschema:"最大宽度（像素），默认 4096"`
+	MaxHeight  int    `json:"max_height,omitempty" jsonschema:"最大高度（像素），默认 4096"`
+	MaxBytes   int64  `json:"max_bytes,omitempty" jsonschema:"输出文件大小上限（字节），不超过服务端图片大小限制"`
+	Quality    int    `json:"quality,omitempty" jsonschema:"JPEG 初始质量 1-100，默认 90；超出大小上限时逐步降低"`
+}
+
+// Validate 校验参数
+func (o Options) Validate() error {
+	if o.Aspect != "" && o.Aspect != AspectAuto {
+		if _, ok := aspectRatios[o.Aspect]; !ok {
+			return errors.Errorf("不支持的画幅: %s（可选 3:4 / 1:1 / 4:3 / auto）", o.Aspect)
+		}
+	}
+	if o.Fit != "" && o.Fit != FitPad && o.Fit != FitCrop {
+		return errors.Errorf("不支持的适配方式: %s（可选 pad / crop）", o.Fit)
+	}
+	if _, err := parseColor(o.Background); err != nil {
+		return err
+	}
+	if o.MaxWidth < 0 || o.MaxHeight < 0 || o.MaxBytes < 0 {
+		return errors.New("最大尺寸与大小上限不能为负数")
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return errors.Errorf("JPEG 质量超出范围: %d（1-100）", o.Quality)
+	}
+	return nil
+}
+
+// withDefaults 填充默认值，maxBytes 为服务端的图片大小限制
+func (o Options) withDefaults(maxBytes int64) Options {
+	if o.Fit == "" {
+		o.Fit = FitPad
+	}
+	if o.MaxWidth == 0 {
+		o.MaxWidth = defaultMaxSide
+	}
+	if o.MaxHeight == 0 {
+		o.MaxHeight = defaultMaxSide
+	}
+	if maxBytes > 0 && (o.MaxBytes == 0 || o.MaxBytes > maxBytes) {
+		o.MaxBytes = maxBytes
+	}
+	if o.Quality == 0 {
+		o.Quality = defaultQuality
+	}
+	return o
+}
+
+// targetRatio 返回目标宽高比，0 表示保持原比例
+func (o Options) targetRatio(w, h int) float64 {
+	if o.Aspect == AspectAuto {
+		return nearestAspect(float64(w) / float64(h))
+	}
+	return aspectRatios[o.Aspect]
+}
+
+// nearestAspect 按对数距离选择最接近的画幅
+func nearestAspect(ratio float64) float64 {
+	best, bestDist := 0.0, math.Inf(1)
+	for _, name := range []string{Aspect3x4, Aspect1x1, Aspect4x3} {
+		r := aspectRatios[name]
+		if d := math.Abs(math.Log(ratio / r)); d < bestDist {
+			best, bestDist = r, d
+		}
+	}
+	return best
+}
+
+// parseColor 解析 #RGB / #RRGGBB，空值为白色
+func parseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if s == "" {
+		return color.RGBA{255, 255, 255, 255}, nil
+	}
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return color.RGBA{}, errors.Errorf("颜色格式错误: %s（示例 #FFFFFF）", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, errors.Errorf("颜色格式错误: %s（示例 #FFFFFF）", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
@@ -0,0 +1,180 @@
+// Package imageproc 在上传前处理发布用的图片：格式转换、EXIF 方向校正与元数据清除、
+// 画幅适配、尺寸限制以及按大小上限重新压缩。
+//
+// JPEG/PNG/GIF/WebP/BMP/TIFF 使用纯 Go 解码；HEIC/AVIF 依赖本机安装的 ImageMagick（magick/convert）、
+// libheif（heif-convert）、ffmpeg 或 macOS 的 sips 之一，都没有时返回 ErrUnsupportedFormat，
+// 可用 ExternalDecoders 在启动时检查。
+package imageproc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// 压缩时的最低 JPEG 质量，再低则改为缩小尺寸
+const minQuality = 60
+
+// 单张图片按大小上限缩小的最多次数
+const maxShrinkSteps = 8
+
+// Result 处理后的图片
+type Result struct {
+	Path         string `json:"path"`
+	SourceFormat string `json:"source_format"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Bytes        int64  `json:"bytes"`
+	Quality      int    `json:"quality"`
+	// Orientation 原图的方向（1 为正常），JPEG/TIFF/WebP 取自 EXIF，HEIC/AVIF 取自 irot
+	Orientation int `json:"orientation,omitempty"`
+}
+
+// Processor 图片处理器，输出写入 outDir
+type Processor struct {
+	outDir    string
+	maxBytes  int64
+	maxPixels int64
+}
+
+// NewProcessor 创建图片处理器，maxBytes 为服务端允许的图片大小上限，
+// maxPixels 为允许解码的最大像素数（均为 <=0 不限制）
+func NewProcessor(outDir string, maxBytes, maxPixels int64) *Processor {
+	return &Processor{outDir: outDir, maxBytes: maxBytes, maxPixels: maxPixels}
+}
+
+// Process 处理单张本地图片，始终输出不含 EXIF/GPS 等元数据的 JPEG。
+// 输出文件名由原图内容与参数决定，相同输入重复处理时直接复用。
+func (p *Processor) Process(path string, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults(p.maxBytes)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取图片失败: %s", path)
+	}
+
+	outPath, err := p.outputPath(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := reuseOutput(outPath, data); ok {
+		return res, nil
+	}
+
+	img, format, oriented, err := decode(path, data, p.maxPixels)
+	if err != nil {
+		return nil, errors.Wrapf(err, "处理图片失败: %s", path)
+	}
+
+	res := &Result{Path: outPath, SourceFormat: format, Orientation: sourceOrientation(format, data)}
+	orientation := res.Orientation
+	if oriented {
+		orientation = 1
+	}
+
+	bg, _ := parseColor(opts.Background)
+	rgba := flatten(applyOrientation(img, orientation), bg)
+
+	if ratio := opts.targetRatio(rgba.Bounds().Dx(), rgba.Bounds().Dy()); ratio > 0 {
+		if opts.Fit == FitCrop {
+			rgba = cropToRatio(rgba, ratio)
+		} else {
+			rgba = padToRatio(rgba, ratio, bg)
+		}
+	}
+	rgba = fitWithin(rgba, opts.MaxWidth, opts.MaxHeight)
+
+	encoded, quality, err := encodeWithinBudget(rgba, opts.Quality, opts.MaxBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "处理图片失败: %s", path)
+	}
+	// 先写临时文件再改名，避免中断后留下不完整的文件被复用
+	if err := os.WriteFile(outPath+".tmp", encoded.data, 0644); err != nil {
+		return nil, errors.Wrap(err, "保存处理后的图片失败")
+	}
+	if err := os.Rename(outPath+".tmp", outPath); err != nil {
+		return nil, errors.Wrap(err, "保存处理后的图片失败")
+	}
+
+	res.Width, res.Height = encoded.width, encoded.height
+	res.Bytes = int64(len(encoded.data))
+	res.Quality = quality
+	logrus.Infof("图片处理完成: %s -> %s (%s %dx%d %d bytes q=%d)",
+		path, outPath, format, res.Width, res.Height, res.Bytes, quality)
+	return res, nil
+}
+
+// outputPath 以原图内容和处理参数的哈希命名输出文件
+func (p *Processor) outputPath(data []byte, opts Options) (string, error) {
+	if err := os.MkdirAll(p.outDir, 0755); err != nil {
+		return "", errors.Wrap(err, "创建图片目录失败")
+	}
+	optsJSON, _ := json.Marshal(opts)
+	h := sha256.New()
+	h.Write(data)
+	h.Write(optsJSON)
+	name := fmt.Sprintf("proc_%s.jpg", hex.EncodeToString(h.Sum(nil))[:16])
+	return filepath.Join(p.outDir, name), nil
+}
+
+// reuseOutput 已处理过的图片直接复用
+func reuseOutput(outPath string, src []byte) (*Result, bool) {
+	f, err := os.Open(outPath)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	cfg, err := jpeg.DecodeConfig(f)
+	if err != nil {
+		return nil, false
+	}
+	st, err := f.Stat()
+	if err != nil {
+		return nil, false
+	}
+	return &Result{
+		Path:         outPath,
+		SourceFormat: sniffFormat(src),
+		Width:        cfg.Width,
+		Height:       cfg.Height,
+		Bytes:        st.Size(),
+	}, true
+}
+
+type encodedImage struct {
+	data          []byte
+	width, height int
+}
+
+// encodeWithinBudget 编码为 JPEG：先按指定质量编码，超出上限时逐步降低质量（自动降质不低于 minQuality），
+// 仍超出时按 0.85 倍缩小尺寸重试
+func encodeWithinBudget(img *image.RGBA, quality int, maxBytes int64) (*encodedImage, int, error) {
+	for step := 0; step <= maxShrinkSteps; step++ {
+		for q := quality; ; q = max(q-10, minQuality) {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: q}); err != nil {
+				return nil, 0, errors.Wrap(err, "JPEG 编码失败")
+			}
+			if maxBytes <= 0 || int64(buf.Len()) <= maxBytes {
+				return &encodedImage{data: buf.Bytes(), width: img.Bounds().Dx(), height: img.Bounds().Dy()}, q, nil
+			}
+			if q <= minQuality {
+				break
+			}
+		}
+		img = resize(img, 0.85)
+	}
+	return nil, 0, errors.Errorf("无法将图片压缩到 %d 字节以内", maxBytes)
+}
@@ -0,0 +1,262 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withExifOrientation 在 JPEG 的 SOI 之后插入只含 Orientation 的 APP1 段
+func withExifOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00*")
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func solidImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func decodeJPEGFile(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	return img
+}
+
+func TestProcess_AutoRotateAndStripExif(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, solidImage(40, 20, color.RGBA{200, 0, 0, 255}), nil))
+	src := withExifOrientation(t, buf.Bytes(), 6)
+	require.Equal(t, 6, jpegOrientation(src))
+
+	p := NewProcessor(t.TempDir(), 0, 0)
+	res, err := p.Process(writeFile(t, "rotated.jpg", src), Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatJPEG, res.SourceFormat)
+	assert.Equal(t, 6, res.Orientation)
+	assert.Equal(t, 20, res.Width)
+	assert.Equal(t, 40, res.Height)
+
+	out, err := os.ReadFile(res.Path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "Exif")
+	assert.Equal(t, 1, jpegOrientation(out))
+}
+
+func TestProcess_PadAndCropToAspect(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, solidImage(200, 100, color.RGBA{0, 0, 255, 255})))
+	path := writeFile(t, "wide.png", buf.Bytes())
+	p := NewProcessor(t.TempDir(), 0, 0)
+
+	res, err := p.Process(path, Options{Aspect: Aspect3x4})
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.Width)
+	assert.Equal(t, 267, res.Height)
+	// 补边区域为白色，中间为原图
+	img := decodeJPEGFile(t, res.Path)
+	r, g, b, _ := img.At(100, 5).RGBA()
+	assert.Greater(t, r>>8, uint32(240))
+	assert.Greater(t, g>>8, uint32(240))
+	assert.Greater(t, b>>8, uint32(240))
+	r, _, b, _ = img.At(100, 133).RGBA()
+	assert.Less(t, r>>8, uint32(30))
+	assert.Greater(t, b>>8, uint32(220))
+
+	res, err = p.Process(path, Options{Aspect: Aspect1x1, Fit: FitCrop})
+	require.NoError(t, err)
+	assert.Equal(t, 100, res.Width)
+	assert.Equal(t, 100, res.Height)
+
+	res, err = p.Process(path, Options{Aspect: AspectAuto, MaxWidth: 80})
+	require.NoError(t, err)
+	assert.Equal(t, 80, res.Width)
+	assert.Equal(t, 60, res.Height)
+}
+
+func TestProcess_ByteBudget(t *testing.T) {
+	// 噪声图难以压缩，用于触发降质量与缩小尺寸
+	img := image.NewRGBA(image.Rect(0, 0, 400, 400))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = byte(seed >> 24)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	p := NewProcessor(t.TempDir(), 30*1024, 0)
+	res, err := p.Process(writeFile(t, "noise.png", buf.Bytes()), Options{})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, res.Bytes, int64(30*1024))
+	assert.Less(t, res.Width, 400)
+}
+
+func TestEncodeWithinBudget_KeepsRequestedLowQuality(t *testing.T) {
+	img := solidImage(64, 64, color.RGBA{R: 200, G: 80, B: 40, A: 255})
+
+	_, q, err := encodeWithinBudget(img, 30, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 30, q)
+
+	// 预算恰好容纳质量 30 的结果时，不应先被抬到 minQuality 再缩小尺寸
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 30}))
+	enc, q, err := encodeWithinBudget(img, 30, int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, 30, q)
+	assert.Equal(t, 64, enc.width)
+}
+
+func TestSmartCropPrefersDetail(t *testing.T) {
+	// 左侧纯色，右侧棋盘格：裁成正方形时应保留右侧
+	img := solidImage(300, 100, color.White)
+	for y := 0; y < 100; y++ {
+		for x := 200; x < 300; x++ {
+			if (x/5+y/5)%2 == 0 {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	out := cropToRatio(img, 1)
+	assert.Equal(t, 100, out.Bounds().Dx())
+	black := 0
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if r, _, _, _ := out.At(x, y).RGBA(); r == 0 {
+				black++
+			}
+		}
+	}
+	assert.Greater(t, black, 4500)
+}
+
+func TestProcess_RejectsTooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, solidImage(200, 100, color.White)))
+
+	p := NewProcessor(t.TempDir(), 0, 10000)
+	_, err := p.Process(writeFile(t, "big.png", buf.Bytes()), Options{})
+	assert.ErrorContains(t, err, "像素过多")
+
+	_, err = p.Process(writeFile(t, "x.bin", []byte("not an image")), Options{})
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+// heifBox 构造 ISO BMFF box
+func heifBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out, uint32(8+len(body)))
+	copy(out[4:], typ)
+	return append(out, body...)
+}
+
+func ispe(w, h uint32) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b[4:], w)
+	binary.BigEndian.PutUint32(b[8:], h)
+	return heifBox("ispe", b)
+}
+
+func TestReadHEIFProps(t *testing.T) {
+	data := bytes.Join([][]byte{
+		heifBox("ftyp", []byte("heic"), make([]byte, 4), []byte("mif1heic")),
+		heifBox("meta", make([]byte, 4),
+			heifBox("hdlr", make([]byte, 24)),
+			heifBox("iprp", heifBox("ipco", ispe(320, 240), ispe(4032, 3024), heifBox("irot", []byte{3}))),
+		),
+	}, nil)
+
+	props := readHEIFProps(data)
+	assert.Equal(t, 4032, props.width)
+	assert.Equal(t, 3024, props.height)
+	assert.Equal(t, 6, sourceOrientation(FormatHEIC, data))
+
+	cfg, err := decodeConfig(data, FormatHEIC)
+	require.NoError(t, err)
+	assert.Error(t, checkPixels(cfg, 10_000_000))
+	assert.NoError(t, checkPixels(cfg, 0))
+}
+
+func TestWebPOrientation(t *testing.T) {
+	var jpg bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpg, solidImage(4, 4, color.White), nil))
+	// 从 JPEG 的 APP1 中取出 "Exif\0\0" + TIFF 数据作为 WebP 的 EXIF 块
+	app1 := withExifOrientation(t, jpg.Bytes(), 8)
+	exif := app1[6 : 6+int(binary.BigEndian.Uint16(app1[4:6]))-2]
+
+	chunk := func(typ string, payload []byte) []byte {
+		out := append([]byte(typ), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	body := append([]byte("WEBP"), chunk("VP8X", make([]byte, 10))...)
+	body = append(body, chunk("EXIF", exif)...)
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+
+	assert.Equal(t, 8, webpOrientation(data))
+	assert.Equal(t, 8, sourceOrientation(FormatWebP, data))
+	assert.Equal(t, 1, webpOrientation([]byte("RIFF\x00\x00\x00\x00WEBP")))
+}
+
+func TestSniffFormat(t *testing.T) {
+	assert.Equal(t, FormatWebP, sniffFormat([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	assert.Equal(t, FormatHEIC, sniffFormat([]byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")))
+	assert.Equal(t, FormatAVIF, sniffFormat([]byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf")))
+	assert.Equal(t, "", sniffFormat([]byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2")))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{Aspect: "3:4", Fit: "crop", Background: "#000"}.Validate())
+	assert.Error(t, Options{Aspect: "16:9"}.Validate())
+	assert.Error(t, Options{Fit: "stretch"}.Validate())
+	assert.Error(t, Options{Background: "white"}.Validate())
+	assert.Error(t, Options{Quality: 101}.Validate())
+}
@@ -0,0 +1,146 @@
+package imageproc
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// flatten 把透明区域铺上背景色（JPEG 不支持透明）
+func flatten(src image.Image, bg color.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	xdraw.Draw(dst, dst.Bounds(), &image.Uniform{C: bg}, image.Point{}, xdraw.Src)
+	xdraw.Draw(dst, dst.Bounds(), src, b.Min, xdraw.Over)
+	return dst
+}
+
+// padToRatio 居中放置原图并补边到目标宽高比
+func padToRatio(src *image.RGBA, ratio float64, bg color.RGBA) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	tw, th := w, h
+	if float64(w)/float64(h) > ratio {
+		th = int(math.Round(float64(w) / ratio))
+	} else {
+		tw = int(math.Round(float64(h) * ratio))
+	}
+	if tw == w && th == h {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	xdraw.Draw(dst, dst.Bounds(), &image.Uniform{C: bg}, image.Point{}, xdraw.Src)
+	off := image.Pt((tw-w)/2, (th-h)/2)
+	xdraw.Draw(dst, src.Bounds().Add(off), src, image.Point{}, xdraw.Src)
+	return dst
+}
+
+// cropToRatio 裁剪到目标宽高比，裁剪窗口取画面细节（边缘能量）最多的位置
+func cropToRatio(src *image.RGBA, ratio float64) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	var rect image.Rectangle
+	if float64(w)/float64(h) > ratio {
+		cw := int(math.Round(float64(h) * ratio))
+		if cw >= w {
+			return src
+		}
+		x := bestWindow(columnEnergy(src), cw)
+		rect = image.Rect(x, 0, x+cw, h)
+	} else {
+		ch := int(math.Round(float64(w) / ratio))
+		if ch >= h {
+			return src
+		}
+		y := bestWindow(rowEnergy(src), ch)
+		rect = image.Rect(0, y, w, y+ch)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	xdraw.Draw(dst, dst.Bounds(), src, rect.Min, xdraw.Src)
+	return dst
+}
+
+// luma 取像素亮度
+func luma(img *image.RGBA, x, y int) float64 {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+3 : i+3]
+	return 0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])
+}
+
+// columnEnergy 每一列的水平+垂直梯度之和
+func columnEnergy(img *image.RGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	e := make([]float64, w)
+	step := max(1, h/256) // 大图抽样，避免逐像素计算过慢
+	for y := 0; y < h-1; y += step {
+		for x := 0; x < w-1; x++ {
+			l := luma(img, x, y)
+			e[x] += math.Abs(l-luma(img, x+1, y)) + math.Abs(l-luma(img, x, y+1))
+		}
+	}
+	return e
+}
+
+// rowEnergy 每一行的水平+垂直梯度之和
+func rowEnergy(img *image.RGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	e := make([]float64, h)
+	step := max(1, w/256)
+	for y := 0; y < h-1; y++ {
+		for x := 0; x < w-1; x += step {
+			l := luma(img, x, y)
+			e[y] += math.Abs(l-luma(img, x+1, y)) + math.Abs(l-luma(img, x, y+1))
+		}
+	}
+	return e
+}
+
+// bestWindow 返回长度为 size 的窗口中能量之和最大的起点；能量相同时居中
+func bestWindow(energy []float64, size int) int {
+	if size >= len(energy) {
+		return 0
+	}
+	var sum float64
+	for _, v := range energy[:size] {
+		sum += v
+	}
+	center := (len(energy) - size) / 2
+	best, bestSum := center, -1.0
+	for start := 0; ; start++ {
+		if sum > bestSum || (sum == bestSum && abs(start-center) < abs(best-center)) {
+			best, bestSum = start, sum
+		}
+		if start+size >= len(energy) {
+			break
+		}
+		sum += energy[start+size] - energy[start]
+	}
+	return best
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// fitWithin 等比缩小到 maxW×maxH 以内，不放大
+func fitWithin(src *image.RGBA, maxW, maxH int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	scale := math.Min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	if scale >= 1 {
+		return src
+	}
+	return resize(src, scale)
+}
+
+// resize 按比例缩放
+func resize(src *image.RGBA, scale float64) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	nw := max(1, int(math.Round(float64(w)*scale)))
+	nh := max(1, int(math.Round(float64(h)*scale)))
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	return dst
+}
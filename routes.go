@@ -19,6 +19,7 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 	// 添加中间件
 	router.Use(errorHandlingMiddleware())
 	router.Use(corsMiddleware())
+	router.Use(traceMiddleware())
 
 	// 健康检查
 	router.GET("/health", healthHandler)
@@ -52,6 +53,21 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 		api.POST("/feeds/comment/reply", appServer.replyCommentHandler)
 		api.GET("/user/me", appServer.myProfileHandler)
 		api.GET("/batch/tasks/:task_id", appServer.getBatchTaskStatusHandler)
+		api.GET("/browser/pool", appServer.browserPoolStatsHandler)
+		api.GET("/notes", appServer.listMyNotesHandler)
+		api.GET("/notes/:id", appServer.getMyNoteHandler)
+		api.PUT("/notes/:id", appServer.editNoteHandler)
+		api.DELETE("/notes/:id", appServer.deleteMyNoteHandler)
+		api.GET("/topics/search", appServer.searchTopicsHandler)
+		api.GET("/drafts", appServer.listDraftsHandler)
+		api.POST("/drafts/:id/publish", appServer.publishDraftHandler)
+		api.DELETE("/drafts/:id", appServer.deleteDraftHandler)
+		api.GET("/artifacts", appServer.listArtifactsHandler)
+		api.GET("/artifacts/:id", appServer.getArtifactHandler)
+		api.GET("/artifacts/:id/:file", appServer.getArtifactFileHandler)
+		api.POST("/media", appServer.uploadMediaHandler)
+		api.GET("/media/cache", appServer.mediaCacheHandler)
+		api.POST("/media/cache/cleanup", appServer.cleanupMediaCacheHandler)
 	}
 
 	return router
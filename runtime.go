@@ -15,9 +15,15 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/artifacts"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/browserpool"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/cookiestore"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/ippool"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/profilestore"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
 )
 
 func shortenOneLine(s string, max int) string {
@@ -40,7 +46,14 @@ type Runtime struct {
 	UserPool    *userpool.Manager
 	IPPool      *ippool.Pool
 	CookieStore *cookiestore.Store
+	Profiles    *profilestore.Store
 	BatchTasks  *BatchTaskStore
+	// BrowserPool 按账号复用的常驻浏览器，未启用时为 nil
+	BrowserPool *browserpool.Pool
+	// Artifacts 失败现场快照，未启用时为 nil
+	Artifacts *artifacts.Store
+	// MediaCache 远程图片/视频的下载缓存
+	MediaCache *downloader.Cache
 
 	browserTokens chan struct{}
 	accountLocks  sync.Map
@@ -68,7 +81,11 @@ func NewRuntime(dataDir string, browserPoolSize int) (*Runtime, error) {
 			return nil, err
 		}
 	}
-	cs := cookiestore.NewStore(dataDir)
+	backend, err := cookies.OpenBackend(cookieBackendConfig())
+	if err != nil {
+		return nil, err
+	}
+	cs := cookiestore.NewStoreWithBackend(dataDir, backend)
 
 	r := &Runtime{
 		DataDir:         dataDir,
@@ -76,15 +93,61 @@ func NewRuntime(dataDir string, browserPoolSize int) (*Runtime, error) {
 		UserPool:        up,
 		IPPool:          ip,
 		CookieStore:     cs,
+		Profiles:        profilestore.NewStore(dataDir),
 		BatchTasks:      NewBatchTaskStore(5),
+		MediaCache:      downloader.DataDirCache(dataDir),
 		browserTokens:   make(chan struct{}, browserPoolSize),
 	}
 	for i := 0; i < browserPoolSize; i++ {
 		r.browserTokens <- struct{}{}
 	}
+	if configs.IsArtifactsEnabled() {
+		r.Artifacts = artifacts.NewStore(dataDir, artifacts.Retention{
+			MaxAge:   configs.GetArtifactsMaxAge(),
+			MaxCount: configs.GetArtifactsMaxCount(),
+		})
+	}
+	go func() {
+		res, err := r.MediaCache.Prune()
+		if err != nil {
+			logrus.Warnf("清理下载缓存失败: %v", err)
+			return
+		}
+		if n := len(res.Removed) + len(res.Files); n > 0 {
+			logrus.Infof("已清理下载缓存 %d 个文件，共 %d bytes", n, res.RemovedBytes+res.FileBytes)
+		}
+	}()
+	if configs.IsWarmPool() {
+		r.BrowserPool = browserpool.New(browserpool.Config{
+			MaxSize: configs.GetWarmPoolSize(),
+			IdleTTL: configs.GetWarmPoolIdleTTL(),
+		})
+	}
 	return r, nil
 }
 
+// Close 释放运行时持有的资源。
+func (r *Runtime) Close() {
+	if r.BrowserPool != nil {
+		r.BrowserPool.Close()
+	}
+	if r.CookieStore != nil {
+		if err := r.CookieStore.Close(); err != nil {
+			logrus.Warnf("关闭 cookies 存储失败: %v", err)
+		}
+	}
+}
+
+func cookieBackendConfig() cookies.BackendConfig {
+	return cookies.BackendConfig{
+		Name:         configs.GetCookieBackend(),
+		BoltPath:     configs.GetCookieBoltPath(),
+		BoltVersions: configs.GetCookieBoltVersions(),
+		KVURL:        configs.GetCookieKVURL(),
+		KVToken:      configs.GetCookieKVToken(),
+	}
+}
+
 func (r *Runtime) AcquireBrowser(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
@@ -129,6 +192,15 @@ type BatchPost struct {
 	Tags       []string `json:"tags,omitempty"`
 	Location   string   `json:"location,omitempty"`
 	ScheduleAt string   `json:"schedule_at,omitempty"`
+
+	// release 释放对缓存中图片的占用，帖子发布结束或任务被丢弃时调用
+	release func()
+}
+
+func (p BatchPost) releaseMedia() {
+	if p.release != nil {
+		p.release()
+	}
 }
 
 type BatchTaskRunConfig struct {
@@ -189,6 +261,12 @@ func (s *BatchTaskStore) Create() *BatchTask {
 
 	if len(s.order) >= s.cap {
 		oldest := s.order[0]
+		// 未开始运行的任务被丢弃时释放其图片；运行中的任务由 run 在发布结束后释放
+		if t := s.tasks[oldest]; t != nil && t.Status == BatchTaskStatusDraft {
+			for _, it := range t.Items {
+				it.releaseMedia()
+			}
+		}
 		delete(s.tasks, oldest)
 		s.order = s.order[1:]
 	}
@@ -412,16 +490,20 @@ func (s *BatchTaskStore) run(runtime *Runtime, publisher BatchPublisher, taskID
 				"targets":       summarizeTargets(cfg.Targets),
 				"userpool_file": userPoolFilePath(runtime),
 			}).Info("batch: publish begin")
-			var err error
+			var (
+				resp *PublishResponse
+				err  error
+			)
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
 						err = fmt.Errorf("panic: %v", r)
 					}
 				}()
-				_, err = publisher.PublishContentForAccount(ctx, account, req)
+				resp, err = publisher.PublishContentForAccount(ctx, account, req)
 			}()
 			cancel()
+			j.post.releaseMedia()
 			durationMs := int(time.Since(startedAt) / time.Millisecond)
 			s.mu.Lock()
 			t, ok := s.tasks[taskID]
@@ -449,11 +531,16 @@ func (s *BatchTaskStore) run(runtime *Runtime, publisher BatchPublisher, taskID
 					"error_message": err.Error(),
 				}).Warn("batch: publish failed")
 			} else {
+				postID := ""
+				if resp != nil {
+					postID = resp.PostID
+				}
 				logrus.WithFields(logrus.Fields{
 					"task_id":     taskID,
 					"idx":         j.idx,
 					"account":     account,
 					"duration_ms": durationMs,
+					"post_id":     postID,
 				}).Info("batch: publish success")
 			}
 			s.sendCallback(cfg.CallbackURL, taskID)
@@ -3,8 +3,9 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,9 +14,14 @@ import (
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/artifacts"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/browserpool"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/cookiestore"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/profilestore"
 	"github.com/xpzouying/xiaohongshu-mcp/modules/userpool"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/fingerprint"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/imageproc"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/xhsutil"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
@@ -28,10 +34,21 @@ type XiaohongshuService struct {
 
 // NewXiaohongshuService 创建小红书服务实例
 func NewXiaohongshuService(runtime *Runtime) *XiaohongshuService {
+	cookieStore := cookiestore.NewStore(configs.GetDataDir())
+	if runtime != nil && runtime.CookieStore != nil {
+		cookieStore = runtime.CookieStore
+	}
 	return &XiaohongshuService{
 		runtime:     runtime,
-		cookieStore: cookiestore.NewStore(configs.GetDataDir()),
+		cookieStore: cookieStore,
+	}
+}
+
+func (s *XiaohongshuService) store() *cookiestore.Store {
+	if s.cookieStore == nil {
+		return cookiestore.NewStore(configs.GetDataDir())
 	}
+	return s.cookieStore
 }
 
 func (s *XiaohongshuService) normalizeAccount(account string) string {
@@ -71,45 +88,187 @@ func (s *XiaohongshuService) resolveUser(account string) userpool.User {
 	return u
 }
 
-func (s *XiaohongshuService) resolveCookieAndProxy(account string) (string, string, error) {
+func (s *XiaohongshuService) resolveCookieAndProxy(account string) (cookies.Cookier, string, error) {
 	account = s.effectiveAccount(account)
 	u := s.resolveUser(account)
 
-	cookieStore := s.cookieStore
-	if cookieStore == nil {
-		cookieStore = cookiestore.NewStore(configs.GetDataDir())
-	}
-	cookiePath, _ := cookieStore.CookiePathFor(account, u.CookieFile)
+	cookier, _ := s.store().CookierFor(account, u.CookieFile)
 
 	if s.runtime == nil || s.runtime.IPPool == nil {
-		return cookiePath, "", nil
+		return cookier, "", nil
 	}
 	ips := s.runtime.IPPool.All()
 	if len(ips) == 0 {
-		return cookiePath, "", nil
+		return cookier, "", nil
 	}
 
 	if proxy, ok := s.runtime.IPPool.Resolve(u.IPRef); ok {
-		return cookiePath, proxy, nil
+		return cookier, proxy, nil
 	}
 
 	if s.runtime.UserPool != nil {
 		if idx, ok := s.runtime.UserPool.IndexOfAccount(account); ok {
 			if idx < 0 || idx >= len(ips) {
-				return cookiePath, "", nil
+				return cookier, "", nil
 			}
 			proxy := ips[idx]
 			_, _ = s.runtime.UserPool.UpsertIPRef(account, idx)
-			return cookiePath, proxy, nil
+			return cookier, proxy, nil
+		}
+	}
+
+	return cookier, "", nil
+}
+
+func (s *XiaohongshuService) profiles() *profilestore.Store {
+	if s.runtime != nil && s.runtime.Profiles != nil {
+		return s.runtime.Profiles
+	}
+	return profilestore.NewStore(configs.GetDataDir())
+}
+
+// browserLaunch 账号的浏览器启动参数
+type browserLaunch struct {
+	opts    []browser.Option
+	cookier cookies.Cookier
+	// signature 启动参数摘要，变化时常驻浏览器需要重建
+	signature string
+}
+
+// browserLaunchFor 组装账号的浏览器启动参数，同时返回账号的 cookies 存储
+func (s *XiaohongshuService) browserLaunchFor(account string) (*browserLaunch, error) {
+	cookier, proxyURL, err := s.resolveCookieAndProxy(account)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint, shared := s.remoteCDPFor(account); endpoint != "" {
+		// 远程浏览器自带指纹与代理，只需要 cookies；全局远程浏览器由多个账号共用，每个账号使用独立上下文
+		opts := []browser.Option{browser.WithCookier(cookier), browser.WithRemoteURL(endpoint)}
+		if shared {
+			opts = append(opts, browser.WithRemoteIsolation())
+		}
+		return &browserLaunch{
+			opts:      opts,
+			cookier:   cookier,
+			signature: "remote=" + endpoint + ";shared=" + strconv.FormatBool(shared),
+		}, nil
+	}
+	opts := []browser.Option{
+		browser.WithBinPath(configs.GetBinPath()),
+		browser.WithCookier(cookier),
+		browser.WithProxyURL(proxyURL),
+	}
+	sig := []string{
+		"headless=" + strconv.FormatBool(configs.IsHeadless()),
+		"bin=" + configs.GetBinPath(),
+		"proxy=" + proxyURL,
+	}
+	if configs.IsPersistentProfile() {
+		dir, err := s.profiles().Prepare(account)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, browser.WithUserDataDir(dir))
+		sig = append(sig, "profile="+dir)
+	}
+	if fp := s.fingerprintFor(account); fp != nil {
+		opts = append(opts, browser.WithFingerprint(fp))
+		sig = append(sig, "fp="+fp.Signature())
+	}
+	return &browserLaunch{opts: opts, cookier: cookier, signature: strings.Join(sig, ";")}, nil
+}
+
+// remoteCDPFor 账号使用的远程浏览器端点，账号配置优先于全局配置；shared 表示使用的是所有账号共用的全局端点
+func (s *XiaohongshuService) remoteCDPFor(account string) (endpoint string, shared bool) {
+	if v := strings.TrimSpace(s.resolveUser(account).RemoteCDP); v != "" {
+		return v, false
+	}
+	v := configs.GetRemoteCDP()
+	return v, v != ""
+}
+
+// fingerprintFor 返回账号固定的浏览器指纹，首次使用时生成并写入 users.json
+func (s *XiaohongshuService) fingerprintFor(account string) *fingerprint.Profile {
+	gen := func() fingerprint.Profile { return fingerprint.Generate(account) }
+	if s.runtime == nil || s.runtime.UserPool == nil {
+		fp := gen()
+		return &fp
+	}
+	fp, err := s.runtime.UserPool.EnsureFingerprint(account, gen)
+	if err != nil {
+		logrus.Warnf("failed to persist fingerprint for %s: %v", account, err)
+	}
+	return &fp
+}
+
+// browserLease 一次操作持有的浏览器，release 负责归还常驻池或直接关闭
+type browserLease struct {
+	browser *browser.Browser
+	cookier cookies.Cookier
+	release func(broken bool)
+}
+
+func (s *XiaohongshuService) warmPool() *browserpool.Pool {
+	if s.runtime == nil {
+		return nil
+	}
+	return s.runtime.BrowserPool
+}
+
+// invalidateWarmBrowser cookies 被外部替换后，丢弃账号的常驻浏览器
+func (s *XiaohongshuService) invalidateWarmBrowser(account string) {
+	if pool := s.warmPool(); pool != nil {
+		pool.Invalidate(account)
+	}
+}
+
+// leaseBrowser 获取账号的浏览器：启用常驻池时优先复用，否则启动新浏览器
+func (s *XiaohongshuService) leaseBrowser(account string) (*browserLease, error) {
+	launch, err := s.browserLaunchFor(account)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := s.warmPool()
+	if pool == nil {
+		b, err := browser.NewBrowser(configs.IsHeadless(), launch.opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &browserLease{browser: b, cookier: launch.cookier, release: func(bool) { b.Close() }}, nil
+	}
+
+	inst, hit, err := pool.Acquire(account, launch.signature, func() (browserpool.Instance, error) {
+		b, err := browser.NewBrowser(configs.IsHeadless(), launch.opts...)
+		if err != nil {
+			return nil, err
 		}
+		return b, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	logrus.WithFields(logrus.Fields{"account": account, "hit": hit}).Debug("warm pool acquire")
 
-	return cookiePath, "", nil
+	return &browserLease{
+		browser: inst.(*browser.Browser),
+		cookier: launch.cookier,
+		release: func(broken bool) { pool.Release(account, inst, broken) },
+	}, nil
 }
 
-func (s *XiaohongshuService) withBrowserPageForAccount(ctx context.Context, account string, fn func(*rod.Page) error) (err error) {
+// withBrowserPageForAccount 在账号的浏览器页面中执行 fn，action 用于日志与失败现场快照
+func (s *XiaohongshuService) withBrowserPageForAccount(ctx context.Context, account, action string, fn func(*rod.Page) error) (err error) {
 	account = s.effectiveAccount(account)
 
+	if s.artifacts() == nil && s.traceEnabled(ctx, account) {
+		// 网络追踪保存在快照中，关闭快照后无处保存
+		if traceRequested(ctx) {
+			return fmt.Errorf("网络追踪需要开启快照（XHS_MCP_ARTIFACTS），当前已关闭")
+		}
+		logrus.WithFields(logrus.Fields{"account": account, "action": action}).Warn("trace is enabled for this account but artifacts are disabled, nothing will be recorded")
+	}
+
 	if s.runtime != nil {
 		if err := s.runtime.AcquireBrowser(ctx); err != nil {
 			return err
@@ -126,32 +285,51 @@ func (s *XiaohongshuService) withBrowserPageForAccount(ctx context.Context, acco
 
 	var lastErr error
 	for attempt := range 2 {
-		cookiePath, proxyURL, err := s.resolveCookieAndProxy(account)
-		if err != nil {
-			return err
-		}
-		b, err := browser.NewBrowser(
-			configs.IsHeadless(),
-			browser.WithBinPath(configs.GetBinPath()),
-			browser.WithCookiesPath(cookiePath),
-			browser.WithProxyURL(proxyURL),
-		)
+		lease, err := s.leaseBrowser(account)
 		if err != nil {
 			return err
 		}
 
-		page := b.NewPage()
-		lastErr = func() (err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = fmt.Errorf("panic: %v", r)
+		page, err := lease.browser.Page()
+		if err != nil {
+			lease.release(true)
+			lastErr = err
+		} else {
+			unblock := s.blockResources(page, account, action)
+			var (
+				recorder *browser.Recorder
+				har      *browser.HARRecorder
+			)
+			if s.artifacts() != nil {
+				recorder = browser.NewRecorder(page)
+				if s.traceEnabled(ctx, account) {
+					har = browser.NewHARRecorder(page, configs.GetTraceMaxBytes(), configs.IsTraceBodiesEnabled())
 				}
+			}
+			lastErr = func() (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("panic: %v", r)
+					}
+				}()
+				return fn(page)
 			}()
-			return fn(page)
-		}()
+			recorder.Stop()
+			har.Stop()
+			unblock()
+
+			if lastErr == nil {
+				syncSessionCookies(account, page, lease.cookier)
+				if har != nil {
+					s.saveTrace(ctx, account, action, har)
+				}
+			} else if !isRodSessionNotFound(lastErr) {
+				lastErr = s.captureFailure(ctx, account, action, page, recorder, har, lastErr)
+			}
 
-		_ = page.Close()
-		b.Close()
+			_ = page.Close()
+			lease.release(isRodSessionNotFound(lastErr))
+		}
 
 		if lastErr == nil {
 			return nil
@@ -166,6 +344,107 @@ func (s *XiaohongshuService) withBrowserPageForAccount(ctx context.Context, acco
 	return lastErr
 }
 
+func (s *XiaohongshuService) artifacts() *artifacts.Store {
+	if s.runtime == nil {
+		return nil
+	}
+	return s.runtime.Artifacts
+}
+
+// captureFailure 保存失败现场（截图、HTML、URL、控制台与网络错误），返回带 artifact_id 的错误
+func (s *XiaohongshuService) captureFailure(ctx context.Context, account, action string, page *rod.Page, recorder *browser.Recorder, har *browser.HARRecorder, cause error) error {
+	store := s.artifacts()
+	if store == nil {
+		return cause
+	}
+	art, err := store.Create(account, action)
+	if err != nil {
+		logrus.Warnf("failed to create artifact: %v", err)
+		return cause
+	}
+
+	meta := art.Meta()
+	meta.Error = cause.Error()
+	if recorder != nil {
+		meta.ConsoleErrors = recorder.ConsoleErrors()
+		meta.NetworkErrors = recorder.NetworkErrors()
+	}
+	snap, errs := browser.CaptureSnapshot(page, 10*time.Second)
+	for _, e := range errs {
+		logrus.Debugf("artifact %s: %v", art.ID(), e)
+	}
+	meta.URL = snap.URL
+	if snap.HTML != "" {
+		_ = art.WriteFile("page.html", []byte(snap.HTML))
+	}
+	if len(snap.Screenshot) > 0 {
+		_ = art.WriteFile("screenshot.png", snap.Screenshot)
+	}
+	if har != nil {
+		writeTrace(art, har)
+	}
+	if err := art.Finish(); err != nil {
+		logrus.Warnf("failed to write artifact %s: %v", art.ID(), err)
+		return cause
+	}
+	recordArtifact(ctx, art.ID())
+
+	logrus.WithFields(logrus.Fields{"account": account, "action": action, "artifact_id": art.ID()}).Warn("saved failure artifact")
+	return &artifacts.Error{Err: cause, ArtifactID: art.ID()}
+}
+
+// blockResources 按操作类型拦截图片、视频等资源，返回停止拦截的函数
+func (s *XiaohongshuService) blockResources(page *rod.Page, account, action string) func() {
+	profile, err := browser.ParseBlockProfile(configs.GetBlockProfile(action))
+	if err != nil {
+		logrus.Warnf("invalid block profile for %s: %v", action, err)
+		return func() {}
+	}
+	stop, err := browser.BlockResources(page, profile)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"account": account, "action": action}).Warnf("failed to enable resource blocking: %v", err)
+		return func() {}
+	}
+	return stop
+}
+
+// traceEnabled 请求头开启追踪，或账号在 users.json 中配置了 trace
+func (s *XiaohongshuService) traceEnabled(ctx context.Context, account string) bool {
+	return traceRequested(ctx) || s.resolveUser(account).Trace
+}
+
+// saveTrace 操作成功时单独保存网络追踪
+func (s *XiaohongshuService) saveTrace(ctx context.Context, account, action string, har *browser.HARRecorder) {
+	art, err := s.artifacts().Create(account, action)
+	if err != nil {
+		logrus.Warnf("failed to create trace artifact: %v", err)
+		return
+	}
+	writeTrace(art, har)
+	if err := art.Finish(); err != nil {
+		logrus.Warnf("failed to write trace artifact %s: %v", art.ID(), err)
+		return
+	}
+	recordArtifact(ctx, art.ID())
+	logrus.WithFields(logrus.Fields{"account": account, "action": action, "artifact_id": art.ID()}).Info("saved network trace")
+}
+
+func writeTrace(art *artifacts.Artifact, har *browser.HARRecorder) {
+	data, err := har.HAR()
+	if err != nil {
+		logrus.Warnf("failed to export har: %v", err)
+		return
+	}
+	if err := art.WriteFile("trace.har", data); err != nil {
+		logrus.Warnf("failed to write har: %v", err)
+		return
+	}
+	art.Meta().Trace = true
+	if har.Truncated() {
+		logrus.Warnf("trace %s truncated by size limit", art.ID())
+	}
+}
+
 func isRodSessionNotFound(err error) bool {
 	if err == nil {
 		return false
@@ -180,8 +459,15 @@ type PublishRequest struct {
 	Content    string   `json:"content" binding:"required"`
 	Images     []string `json:"images" binding:"required,min=1"`
 	Tags       []string `json:"tags,omitempty"`
+	Mentions   []string `json:"mentions,omitempty"`   // 正文末尾 @ 的用户昵称，未能解析时按普通文本输入
+	ExactTags  bool     `json:"exact_tags,omitempty"` // 只关联名称与标签完全一致的话题，否则按普通文本输入
 	Location   string   `json:"location,omitempty"`
 	ScheduleAt string   `json:"schedule_at,omitempty"` // 定时发布时间，ISO8601格式，为空则立即发布
+	Draft      bool     `json:"draft,omitempty"`       // 存入草稿箱而不发布
+	// 可见范围、原创声明、内容类型声明等发布设置
+	xiaohongshu.PublishOptions
+	// ImageProcessing 上传前的图片处理（格式转换、去除 EXIF/GPS、画幅适配、压缩），为空时原图上传
+	ImageProcessing *imageproc.Options `json:"image_processing,omitempty"`
 }
 
 // LoginStatusResponse 登录状态响应
@@ -204,16 +490,31 @@ type PublishResponse struct {
 	Images  int    `json:"images"`
 	Status  string `json:"status"`
 	PostID  string `json:"post_id,omitempty"`
+	// NoteURL 笔记链接，XsecToken 访问笔记详情/评论时需要
+	NoteURL   string `json:"note_url,omitempty"`
+	XsecToken string `json:"xsec_token,omitempty"`
+	// DraftID 存入草稿箱时的草稿 ID
+	DraftID string `json:"draft_id,omitempty"`
+	// Topics 实际关联的话题，Warnings 未影响发布的问题，如 @ 用户或标签未能解析
+	Topics   []string `json:"topics,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
-// PublishVideoRequest 发布视频请求（仅支持本地单个视频文件）
+// PublishVideoRequest 发布视频请求（单个视频文件，本地路径或 URL）
 type PublishVideoRequest struct {
 	Title      string   `json:"title" binding:"required"`
 	Content    string   `json:"content" binding:"required"`
 	Video      string   `json:"video" binding:"required"`
 	Tags       []string `json:"tags,omitempty"`
+	Mentions   []string `json:"mentions,omitempty"`   // 正文末尾 @ 的用户昵称，未能解析时按普通文本输入
+	ExactTags  bool     `json:"exact_tags,omitempty"` // 只关联名称与标签完全一致的话题，否则按普通文本输入
 	Location   string   `json:"location,omitempty"`
 	ScheduleAt string   `json:"schedule_at,omitempty"` // 定时发布时间，ISO8601格式，为空则立即发布
+	Draft      bool     `json:"draft,omitempty"`       // 存入草稿箱而不发布
+	Cover      string   `json:"cover,omitempty"`       // 封面图片，本地路径或 URL
+	CoverAt    string   `json:"cover_at,omitempty"`    // 从视频截帧作为封面的时间点，如 3.5 / 00:03
+	// 可见范围、原创声明、内容类型声明等发布设置
+	xiaohongshu.PublishOptions
 }
 
 // PublishVideoResponse 发布视频响应
@@ -222,13 +523,27 @@ type PublishVideoResponse struct {
 	Content string `json:"content"`
 	Video   string `json:"video"`
 	Status  string `json:"status"`
-	PostID  string `json:"post_id,omitempty"`
+	// VideoInfo 上传前解析的视频信息，VideoSHA256 仅远程视频下载时计算
+	VideoInfo   *downloader.VideoInfo `json:"video_info,omitempty"`
+	VideoSHA256 string                `json:"video_sha256,omitempty"`
+	PostID      string                `json:"post_id,omitempty"`
+	// NoteURL 笔记链接，XsecToken 访问笔记详情/评论时需要
+	NoteURL   string `json:"note_url,omitempty"`
+	XsecToken string `json:"xsec_token,omitempty"`
+	// DraftID 存入草稿箱时的草稿 ID
+	DraftID string `json:"draft_id,omitempty"`
+	// Topics 实际关联的话题，Warnings 未影响发布的问题，如 @ 用户或标签未能解析
+	Topics   []string `json:"topics,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // FeedsListResponse Feeds列表响应
 type FeedsListResponse struct {
-	Feeds []xiaohongshu.Feed `json:"feeds"`
-	Count int                `json:"count"`
+	Feeds      []xiaohongshu.Feed `json:"feeds"`
+	Count      int                `json:"count"`
+	Channel    string             `json:"channel,omitempty"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	HasMore    bool               `json:"has_more,omitempty"`
 }
 
 // UserProfileResponse 用户主页响应
@@ -246,13 +561,61 @@ func (s *XiaohongshuService) DeleteCookiesForAccount(ctx context.Context, accoun
 	account = s.effectiveAccount(account)
 	u := s.resolveUser(account)
 
-	cookieStore := s.cookieStore
-	if cookieStore == nil {
-		cookieStore = cookiestore.NewStore(configs.GetDataDir())
+	cookieLoader, _ := s.store().CookierFor(account, u.CookieFile)
+	if err := cookieLoader.DeleteCookies(); err != nil {
+		return err
 	}
-	cookiePath, _ := cookieStore.CookiePathFor(account, u.CookieFile)
-	cookieLoader := cookies.NewLoadCookie(cookiePath)
-	return cookieLoader.DeleteCookies()
+	s.invalidateWarmBrowser(account)
+	return nil
+}
+
+// ImportCookiesResponse 导入 cookies 响应
+type ImportCookiesResponse struct {
+	Account  string   `json:"account"`
+	Format   string   `json:"format"`
+	Count    int      `json:"count"`
+	Dropped  int      `json:"dropped"`
+	Location string   `json:"location"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ImportCookiesForAccount 导入浏览器导出的 cookies（cookies.txt / JSON / Cookie 头）并绑定到账号
+func (s *XiaohongshuService) ImportCookiesForAccount(ctx context.Context, account, content, format string) (*ImportCookiesResponse, error) {
+	_ = ctx
+	account = s.effectiveAccount(account)
+
+	res, err := cookies.ParseImport([]byte(content), format)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.runtime != nil {
+		if mu, err := s.runtime.AccountLock(account); err == nil {
+			mu.Lock()
+			defer mu.Unlock()
+		}
+	}
+
+	u := s.resolveUser(account)
+	ref, err := s.store().Import(account, u.CookieFile, res.Cookies)
+	if err != nil {
+		return nil, err
+	}
+	if s.runtime != nil && s.runtime.UserPool != nil {
+		if _, err := s.runtime.UserPool.UpsertCookie(account, ref); err != nil {
+			return nil, err
+		}
+	}
+	s.invalidateWarmBrowser(account)
+
+	return &ImportCookiesResponse{
+		Account:  account,
+		Format:   res.Format,
+		Count:    res.Count,
+		Dropped:  res.Dropped,
+		Location: s.store().Location(account, ref),
+		Warnings: res.Warnings,
+	}, nil
 }
 
 func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatusResponse, error) {
@@ -261,7 +624,7 @@ func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatus
 
 func (s *XiaohongshuService) CheckLoginStatusForAccount(ctx context.Context, account string) (*LoginStatusResponse, error) {
 	var isLoggedIn bool
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "check_login", func(page *rod.Page) error {
 		loginAction := xiaohongshu.NewLogin(page)
 		v, err := loginAction.CheckLoginStatus(ctx)
 		if err != nil {
@@ -288,34 +651,34 @@ func (s *XiaohongshuService) GetLoginQrcodeForAccount(ctx context.Context, accou
 			return nil, err
 		}
 	}
+	// 账号锁一直持有到扫码等待结束、浏览器关闭，期间其他操作不会用同一个 profile 再启动浏览器
+	unlock := func() {}
 	if s.runtime != nil {
 		if mu, err := s.runtime.AccountLock(account); err == nil {
 			mu.Lock()
-			defer mu.Unlock()
+			unlock = mu.Unlock
 		}
 	}
-
-	cookiePath, proxyURL, err := s.resolveCookieAndProxy(account)
-	if err != nil {
+	releaseAll := func() {
+		unlock()
 		if s.runtime != nil {
 			s.runtime.ReleaseBrowser()
 		}
-		return nil, err
 	}
-	if s.cookieStore != nil {
-		_ = s.cookieStore.EnsureDir(cookiePath)
+
+	// 登录会替换 cookies，常驻浏览器需要重建
+	s.invalidateWarmBrowser(account)
+
+	launch, err := s.browserLaunchFor(account)
+	if err != nil {
+		releaseAll()
+		return nil, err
 	}
+	cookier := launch.cookier
 
-	b, err := browser.NewBrowser(
-		configs.IsHeadless(),
-		browser.WithBinPath(configs.GetBinPath()),
-		browser.WithCookiesPath(cookiePath),
-		browser.WithProxyURL(proxyURL),
-	)
+	b, err := browser.NewBrowser(configs.IsHeadless(), launch.opts...)
 	if err != nil {
-		if s.runtime != nil {
-			s.runtime.ReleaseBrowser()
-		}
+		releaseAll()
 		return nil, err
 	}
 	page := b.NewPage()
@@ -323,9 +686,7 @@ func (s *XiaohongshuService) GetLoginQrcodeForAccount(ctx context.Context, accou
 	deferFunc := func() {
 		_ = page.Close()
 		b.Close()
-		if s.runtime != nil {
-			s.runtime.ReleaseBrowser()
-		}
+		releaseAll()
 	}
 
 	loginAction := xiaohongshu.NewLogin(page)
@@ -347,18 +708,15 @@ func (s *XiaohongshuService) GetLoginQrcodeForAccount(ctx context.Context, accou
 			defer deferFunc()
 
 			if loginAction.WaitForLogin(ctxTimeout) {
-				if er := saveCookies(page, cookiePath); er != nil {
+				if er := saveCookies(page, cookier); er != nil {
 					logrus.Errorf("failed to save cookies: %v", er)
 					return
 				}
 				if s.runtime != nil && s.runtime.UserPool != nil {
-					cookieStore := s.cookieStore
-					if cookieStore == nil {
-						cookieStore = cookiestore.NewStore(configs.GetDataDir())
-					}
-					_, rel := cookieStore.CookiePathFor(account, "")
-					_, _ = s.runtime.UserPool.UpsertCookie(account, rel)
+					_, ref := s.store().CookierFor(account, "")
+					_, _ = s.runtime.UserPool.UpsertCookie(account, ref)
 				}
+				s.invalidateWarmBrowser(account)
 			}
 		}()
 	}
@@ -385,12 +743,27 @@ func (s *XiaohongshuService) PublishContentForAccount(ctx context.Context, accou
 	if xhsutil.CalcTitleLength(req.Title) > 20 {
 		return nil, fmt.Errorf("标题长度超过限制")
 	}
+	if err := req.PublishOptions.Validate(); err != nil {
+		return nil, err
+	}
+	if req.ImageProcessing != nil {
+		if err := req.ImageProcessing.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if req.Draft {
+		if err := s.checkDraftSupported(account, req.ScheduleAt); err != nil {
+			return nil, err
+		}
+	}
 
-	// 处理图片：下载URL图片或使用本地路径
-	imagePaths, err := s.processImages(req.Images)
+	// 处理图片：下载URL图片或使用本地路径，按需转换与压缩
+	imagePaths, err := s.processImages(req.Images, req.ImageProcessing)
 	if err != nil {
 		return nil, err
 	}
+	// 发布结束前缓存中的图片不能被淘汰
+	defer s.mediaCache().Pin(imagePaths...)()
 
 	// 解析定时发布时间
 	var scheduleTime *time.Time
@@ -432,44 +805,72 @@ func (s *XiaohongshuService) PublishContentForAccount(ctx context.Context, accou
 		Title:        req.Title,
 		Content:      req.Content,
 		Tags:         req.Tags,
+		Mentions:     req.Mentions,
+		ExactTags:    req.ExactTags,
 		ImagePaths:   imagePaths,
 		Location:     req.Location,
 		ScheduleTime: scheduleTime,
+		Draft:        req.Draft,
+		Options:      req.PublishOptions,
 	}
 
 	// 执行发布
-	if err := s.publishContentForAccount(ctx, account, content); err != nil {
+	result, err := s.publishContentForAccount(ctx, account, content)
+	if err != nil {
 		logrus.Errorf("发布内容失败: title=%s %v", content.Title, err)
 		return nil, err
 	}
 
 	response := &PublishResponse{
-		Title:   req.Title,
-		Content: req.Content,
-		Images:  len(imagePaths),
-		Status:  "发布完成",
+		Title:     req.Title,
+		Content:   req.Content,
+		Images:    len(imagePaths),
+		Status:    "发布完成",
+		PostID:    result.NoteID,
+		NoteURL:   result.URL,
+		XsecToken: result.XsecToken,
+		DraftID:   result.DraftID,
+		Topics:    result.Topics,
+		Warnings:  result.Warnings,
+	}
+	if req.Draft {
+		response.Status = "已存入草稿箱"
 	}
 
 	return response, nil
 }
 
-// processImages 处理图片列表，支持URL下载和本地路径
-func (s *XiaohongshuService) processImages(images []string) ([]string, error) {
-	processor := downloader.NewImageProcessor()
-	return processor.ProcessImages(images)
+// processImages 处理图片列表，支持URL下载和本地路径；opts 不为空时上传前转换与压缩
+func (s *XiaohongshuService) processImages(images []string, opts *imageproc.Options) ([]string, error) {
+	processor := downloader.NewImageProcessorWithCache(s.mediaCache())
+	return processor.ProcessImagesWithOptions(images, opts)
+}
+
+// mediaCache 运行时数据目录下的下载缓存
+func (s *XiaohongshuService) mediaCache() *downloader.Cache {
+	if s.runtime != nil && s.runtime.MediaCache != nil {
+		return s.runtime.MediaCache
+	}
+	return downloader.DefaultCache()
 }
 
-func (s *XiaohongshuService) publishContentForAccount(ctx context.Context, account string, content xiaohongshu.PublishImageContent) error {
-	return s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+func (s *XiaohongshuService) publishContentForAccount(ctx context.Context, account string, content xiaohongshu.PublishImageContent) (*xiaohongshu.PublishResult, error) {
+	var result *xiaohongshu.PublishResult
+	err := s.withBrowserPageForAccount(ctx, account, "publish", func(page *rod.Page) error {
 		action, err := xiaohongshu.NewPublishImageAction(page)
 		if err != nil {
 			return err
 		}
-		return action.Publish(ctx, content)
+		result, err = action.Publish(ctx, content)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-// PublishVideo 发布视频（本地文件）
+// PublishVideo 发布视频（本地文件或 URL）
 func (s *XiaohongshuService) PublishVideo(ctx context.Context, req *PublishVideoRequest) (*PublishVideoResponse, error) {
 	return s.PublishVideoForAccount(ctx, "", req)
 }
@@ -480,12 +881,52 @@ func (s *XiaohongshuService) PublishVideoForAccount(ctx context.Context, account
 		return nil, fmt.Errorf("标题长度超过限制")
 	}
 
-	// 本地视频文件校验
+	if err := req.PublishOptions.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Draft {
+		if err := s.checkDraftSupported(account, req.ScheduleAt); err != nil {
+			return nil, err
+		}
+	}
+
+	// 视频：URL 先下载，再解析文件头校验格式、编码、时长与分辨率
 	if req.Video == "" {
-		return nil, fmt.Errorf("必须提供本地视频文件")
+		return nil, fmt.Errorf("必须提供视频文件")
+	}
+	// 封面：图片与截帧二选一，参数在下载视频之前校验
+	var coverPath string
+	var coverOffset *time.Duration
+	if req.Cover != "" && req.CoverAt != "" {
+		return nil, fmt.Errorf("cover 与 cover_at 只能二选一")
+	}
+	if req.CoverAt != "" {
+		d, err := xiaohongshu.ParseCoverOffset(req.CoverAt)
+		if err != nil {
+			return nil, err
+		}
+		coverOffset = &d
 	}
-	if _, err := os.Stat(req.Video); err != nil {
-		return nil, fmt.Errorf("视频文件不存在或不可访问: %v", err)
+
+	video, err := downloader.NewVideoProcessorWithCache(s.mediaCache()).ProcessVideo(req.Video)
+	if err != nil {
+		return nil, err
+	}
+	defer s.mediaCache().Pin(video.Path)()
+
+	if coverOffset != nil && video.Info != nil {
+		if err := xiaohongshu.CheckCoverOffset(*coverOffset, video.Info.Duration); err != nil {
+			return nil, err
+		}
+	}
+	// 封面图片走与图文相同的下载流程
+	if req.Cover != "" {
+		paths, err := s.processImages([]string{req.Cover}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("处理封面图片失败: %v", err)
+		}
+		coverPath = paths[0]
+		defer s.mediaCache().Pin(coverPath)()
 	}
 
 	// 解析定时发布时间
@@ -519,56 +960,92 @@ func (s *XiaohongshuService) PublishVideoForAccount(ctx context.Context, account
 		Title:        req.Title,
 		Content:      req.Content,
 		Tags:         req.Tags,
-		VideoPath:    req.Video,
+		Mentions:     req.Mentions,
+		ExactTags:    req.ExactTags,
+		VideoPath:    video.Path,
 		Location:     req.Location,
 		ScheduleTime: scheduleTime,
+		Draft:        req.Draft,
+		Options:      req.PublishOptions,
+		CoverPath:    coverPath,
+		CoverOffset:  coverOffset,
 	}
 
 	// 执行发布
-	if err := s.publishVideoForAccount(ctx, account, content); err != nil {
+	result, err := s.publishVideoForAccount(ctx, account, content)
+	if err != nil {
 		return nil, err
 	}
 
 	resp := &PublishVideoResponse{
-		Title:   req.Title,
-		Content: req.Content,
-		Video:   req.Video,
-		Status:  "发布完成",
+		Title:       req.Title,
+		Content:     req.Content,
+		Video:       req.Video,
+		Status:      "发布完成",
+		VideoInfo:   video.Info,
+		VideoSHA256: video.SHA256,
+		PostID:      result.NoteID,
+		NoteURL:     result.URL,
+		XsecToken:   result.XsecToken,
+		DraftID:     result.DraftID,
+		Topics:      result.Topics,
+		Warnings:    result.Warnings,
+	}
+	if req.Draft {
+		resp.Status = "已存入草稿箱"
 	}
 	return resp, nil
 }
 
-func (s *XiaohongshuService) publishVideoForAccount(ctx context.Context, account string, content xiaohongshu.PublishVideoContent) error {
-	return s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+func (s *XiaohongshuService) publishVideoForAccount(ctx context.Context, account string, content xiaohongshu.PublishVideoContent) (*xiaohongshu.PublishResult, error) {
+	var result *xiaohongshu.PublishResult
+	err := s.withBrowserPageForAccount(ctx, account, "publish_video", func(page *rod.Page) error {
 		action, err := xiaohongshu.NewPublishVideoAction(page)
 		if err != nil {
 			return err
 		}
-		return action.PublishVideo(ctx, content)
+		result, err = action.PublishVideo(ctx, content)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // ListFeeds 获取Feeds列表
-func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse, error) {
-	return s.ListFeedsForAccount(ctx, "")
+func (s *XiaohongshuService) ListFeeds(ctx context.Context, opts ...xiaohongshu.FeedsListOptions) (*FeedsListResponse, error) {
+	return s.ListFeedsForAccount(ctx, "", opts...)
 }
 
-func (s *XiaohongshuService) ListFeedsForAccount(ctx context.Context, account string) (*FeedsListResponse, error) {
-	var feeds []xiaohongshu.Feed
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+func (s *XiaohongshuService) ListFeedsForAccount(ctx context.Context, account string, opts ...xiaohongshu.FeedsListOptions) (*FeedsListResponse, error) {
+	var opt xiaohongshu.FeedsListOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.Account = s.effectiveAccount(account)
+
+	var result *xiaohongshu.FeedsPage
+	err := s.withBrowserPageForAccount(ctx, account, "list_feeds", func(page *rod.Page) error {
 		action := xiaohongshu.NewFeedsListAction(page)
-		v, err := action.GetFeedsList(ctx)
+		v, err := action.ListFeeds(ctx, opt)
 		if err != nil {
 			logrus.Errorf("获取 Feeds 列表失败: %v", err)
 			return err
 		}
-		feeds = v
+		result = v
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &FeedsListResponse{Feeds: feeds, Count: len(feeds)}, nil
+	return &FeedsListResponse{
+		Feeds:      result.Feeds,
+		Count:      len(result.Feeds),
+		Channel:    result.Channel,
+		NextCursor: result.NextCursor,
+		HasMore:    result.HasMore,
+	}, nil
 }
 
 func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string, filters ...xiaohongshu.FilterOption) (*FeedsListResponse, error) {
@@ -577,7 +1054,7 @@ func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string, fi
 
 func (s *XiaohongshuService) SearchFeedsForAccount(ctx context.Context, account string, keyword string, filters ...xiaohongshu.FilterOption) (*FeedsListResponse, error) {
 	var feeds []xiaohongshu.Feed
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "search_feeds", func(page *rod.Page) error {
 		action := xiaohongshu.NewSearchAction(page)
 		v, err := action.Search(ctx, keyword, filters...)
 		if err != nil {
@@ -604,7 +1081,7 @@ func (s *XiaohongshuService) GetFeedDetailWithConfig(ctx context.Context, feedID
 
 func (s *XiaohongshuService) GetFeedDetailWithConfigForAccount(ctx context.Context, account string, feedID, xsecToken string, loadAllComments bool, config xiaohongshu.CommentLoadConfig) (*FeedDetailResponse, error) {
 	var result *xiaohongshu.FeedDetailResponse
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "feed_detail", func(page *rod.Page) error {
 		action := xiaohongshu.NewFeedDetailAction(page)
 		v, err := action.GetFeedDetailWithConfig(ctx, feedID, xsecToken, loadAllComments, config)
 		if err != nil {
@@ -627,7 +1104,7 @@ func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken
 
 func (s *XiaohongshuService) UserProfileForAccount(ctx context.Context, account string, userID, xsecToken string) (*UserProfileResponse, error) {
 	var result *xiaohongshu.UserProfileResponse
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "user_profile", func(page *rod.Page) error {
 		action := xiaohongshu.NewUserProfileAction(page)
 		v, err := action.UserProfile(ctx, userID, xsecToken)
 		if err != nil {
@@ -643,19 +1120,22 @@ func (s *XiaohongshuService) UserProfileForAccount(ctx context.Context, account
 }
 
 // PostCommentToFeed 发表评论到Feed
-func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string) (*PostCommentResponse, error) {
-	return s.PostCommentToFeedForAccount(ctx, "", feedID, xsecToken, content)
+func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string, mentions []string) (*PostCommentResponse, error) {
+	return s.PostCommentToFeedForAccount(ctx, "", feedID, xsecToken, content, mentions)
 }
 
-func (s *XiaohongshuService) PostCommentToFeedForAccount(ctx context.Context, account string, feedID, xsecToken, content string) (*PostCommentResponse, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+func (s *XiaohongshuService) PostCommentToFeedForAccount(ctx context.Context, account string, feedID, xsecToken, content string, mentions []string) (*PostCommentResponse, error) {
+	var warnings []string
+	err := s.withBrowserPageForAccount(ctx, account, "post_comment", func(page *rod.Page) error {
 		action := xiaohongshu.NewCommentFeedAction(page)
-		return action.PostComment(ctx, feedID, xsecToken, content)
+		w, err := action.PostComment(ctx, feedID, xsecToken, content, mentions)
+		warnings = w
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &PostCommentResponse{FeedID: feedID, Success: true, Message: "评论发表成功"}, nil
+	return &PostCommentResponse{FeedID: feedID, Success: true, Message: "评论发表成功", Warnings: warnings}, nil
 }
 
 // LikeFeed 点赞笔记
@@ -664,7 +1144,7 @@ func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken str
 }
 
 func (s *XiaohongshuService) LikeFeedForAccount(ctx context.Context, account string, feedID, xsecToken string) (*ActionResult, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "like_feed", func(page *rod.Page) error {
 		action := xiaohongshu.NewLikeAction(page)
 		return action.Like(ctx, feedID, xsecToken)
 	})
@@ -680,7 +1160,7 @@ func (s *XiaohongshuService) UnlikeFeed(ctx context.Context, feedID, xsecToken s
 }
 
 func (s *XiaohongshuService) UnlikeFeedForAccount(ctx context.Context, account string, feedID, xsecToken string) (*ActionResult, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "unlike_feed", func(page *rod.Page) error {
 		action := xiaohongshu.NewLikeAction(page)
 		return action.Unlike(ctx, feedID, xsecToken)
 	})
@@ -696,7 +1176,7 @@ func (s *XiaohongshuService) FavoriteFeed(ctx context.Context, feedID, xsecToken
 }
 
 func (s *XiaohongshuService) FavoriteFeedForAccount(ctx context.Context, account string, feedID, xsecToken string) (*ActionResult, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "favorite_feed", func(page *rod.Page) error {
 		action := xiaohongshu.NewFavoriteAction(page)
 		return action.Favorite(ctx, feedID, xsecToken)
 	})
@@ -712,7 +1192,7 @@ func (s *XiaohongshuService) UnfavoriteFeed(ctx context.Context, feedID, xsecTok
 }
 
 func (s *XiaohongshuService) UnfavoriteFeedForAccount(ctx context.Context, account string, feedID, xsecToken string) (*ActionResult, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err := s.withBrowserPageForAccount(ctx, account, "unfavorite_feed", func(page *rod.Page) error {
 		action := xiaohongshu.NewFavoriteAction(page)
 		return action.Unfavorite(ctx, feedID, xsecToken)
 	})
@@ -723,26 +1203,29 @@ func (s *XiaohongshuService) UnfavoriteFeedForAccount(ctx context.Context, accou
 }
 
 // ReplyCommentToFeed 回复指定评论
-func (s *XiaohongshuService) ReplyCommentToFeed(ctx context.Context, feedID, xsecToken, commentID, userID, content string) (*ReplyCommentResponse, error) {
-	return s.ReplyCommentToFeedForAccount(ctx, "", feedID, xsecToken, commentID, userID, content)
+func (s *XiaohongshuService) ReplyCommentToFeed(ctx context.Context, feedID, xsecToken, commentID, userID, content string, mentions []string) (*ReplyCommentResponse, error) {
+	return s.ReplyCommentToFeedForAccount(ctx, "", feedID, xsecToken, commentID, userID, content, mentions)
 }
 
-func (s *XiaohongshuService) ReplyCommentToFeedForAccount(ctx context.Context, account string, feedID, xsecToken, commentID, userID, content string) (*ReplyCommentResponse, error) {
-	err := s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+func (s *XiaohongshuService) ReplyCommentToFeedForAccount(ctx context.Context, account string, feedID, xsecToken, commentID, userID, content string, mentions []string) (*ReplyCommentResponse, error) {
+	var warnings []string
+	err := s.withBrowserPageForAccount(ctx, account, "reply_comment", func(page *rod.Page) error {
 		action := xiaohongshu.NewCommentFeedAction(page)
-		return action.ReplyToComment(ctx, feedID, xsecToken, commentID, userID, content)
+		w, err := action.ReplyToComment(ctx, feedID, xsecToken, commentID, userID, content, mentions)
+		warnings = w
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &ReplyCommentResponse{FeedID: feedID, TargetCommentID: commentID, TargetUserID: userID, Success: true, Message: "评论回复成功"}, nil
+	return &ReplyCommentResponse{FeedID: feedID, TargetCommentID: commentID, TargetUserID: userID, Success: true, Message: "评论回复成功", Warnings: warnings}, nil
 }
 
 func newBrowser() (*browser.Browser, error) {
 	return browser.NewBrowser(configs.IsHeadless(), browser.WithBinPath(configs.GetBinPath()))
 }
 
-func saveCookies(page *rod.Page, cookiePath string) error {
+func saveCookies(page *rod.Page, cookieLoader cookies.Cookier) error {
 	cks, err := page.Browser().GetCookies()
 	if err != nil {
 		return err
@@ -753,10 +1236,26 @@ func saveCookies(page *rod.Page, cookiePath string) error {
 		return err
 	}
 
-	cookieLoader := cookies.NewLoadCookie(cookiePath)
 	return cookieLoader.SaveCookies(data)
 }
 
+// syncSessionCookies 将浏览器会话中刷新过的 cookies 合并写回账号的 cookies 存储。
+// 写回失败不影响本次操作结果，只记录日志。
+func syncSessionCookies(account string, page *rod.Page, cookieLoader cookies.Cookier) {
+	cks, err := page.Browser().GetCookies()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"account": account}).Warnf("读取浏览器 cookies 失败: %v", err)
+		return
+	}
+	if err := cookies.SyncCookies(cookieLoader, cks); err != nil {
+		if errors.Is(err, cookies.ErrNoLoginSession) {
+			logrus.WithFields(logrus.Fields{"account": account}).Warn("浏览器未处于登录态，跳过 cookies 写回")
+			return
+		}
+		logrus.WithFields(logrus.Fields{"account": account}).Warnf("写回 cookies 失败: %v", err)
+	}
+}
+
 // withBrowserPage 执行需要浏览器页面的操作的通用函数
 func withBrowserPage(fn func(*rod.Page) error) error {
 	b, err := newBrowser()
@@ -781,7 +1280,7 @@ func (s *XiaohongshuService) GetMyProfile(ctx context.Context) (*UserProfileResp
 func (s *XiaohongshuService) GetMyProfileForAccount(ctx context.Context, account string) (*UserProfileResponse, error) {
 	var result *xiaohongshu.UserProfileResponse
 	var err error
-	err = s.withBrowserPageForAccount(ctx, account, func(page *rod.Page) error {
+	err = s.withBrowserPageForAccount(ctx, account, "my_profile", func(page *rod.Page) error {
 		action := xiaohongshu.NewUserProfileAction(page)
 		result, err = action.GetMyProfileViaSidebar(ctx)
 		return err
@@ -799,3 +1298,276 @@ func (s *XiaohongshuService) GetMyProfileForAccount(ctx context.Context, account
 
 	return response, nil
 }
+
+// MyNotesResponse 我的笔记列表
+type MyNotesResponse struct {
+	Notes []xiaohongshu.ManagedNote `json:"notes"`
+	Count int                       `json:"count"`
+}
+
+// DeleteNoteResponse 删除笔记结果
+type DeleteNoteResponse struct {
+	NoteID  string `json:"note_id"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ListMyNotesForAccount 列出账号在创作者中心的笔记，status 为空时返回全部
+func (s *XiaohongshuService) ListMyNotesForAccount(ctx context.Context, account, status string, limit int) (*MyNotesResponse, error) {
+	var notes []xiaohongshu.ManagedNote
+	err := s.withBrowserPageForAccount(ctx, account, "list_notes", func(page *rod.Page) error {
+		var err error
+		notes, err = xiaohongshu.NewNoteManagerAction(page).List(ctx, status, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MyNotesResponse{Notes: notes, Count: len(notes)}, nil
+}
+
+// GetMyNoteForAccount 查看账号的单条笔记（状态、可见范围、互动数据）
+func (s *XiaohongshuService) GetMyNoteForAccount(ctx context.Context, account, noteID string) (*xiaohongshu.ManagedNote, error) {
+	var note *xiaohongshu.ManagedNote
+	err := s.withBrowserPageForAccount(ctx, account, "get_note", func(page *rod.Page) error {
+		var err error
+		note, err = xiaohongshu.NewNoteManagerAction(page).Get(ctx, noteID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// DraftsResponse 草稿箱列表
+type DraftsResponse struct {
+	Drafts []xiaohongshu.Draft `json:"drafts"`
+	Count  int                 `json:"count"`
+}
+
+// DeleteDraftResponse 删除草稿结果
+type DeleteDraftResponse struct {
+	DraftID string `json:"draft_id"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// checkDraftSupported 草稿保存在浏览器本地，临时 profile 关闭后即丢失，
+// 因此要求启用持久化 profile 或使用账号专属的远程浏览器（共用的远程浏览器使用独立上下文，断开后同样丢失）；
+// 草稿不支持定时发布。
+func (s *XiaohongshuService) checkDraftSupported(account, scheduleAt string) error {
+	if strings.TrimSpace(scheduleAt) != "" {
+		return fmt.Errorf("草稿模式不支持定时发布，请在发布草稿时再选择发布时间")
+	}
+	if configs.IsPersistentProfile() {
+		return nil
+	}
+	if endpoint, shared := s.remoteCDPFor(account); endpoint != "" && !shared {
+		return nil
+	}
+	return fmt.Errorf("草稿保存在浏览器本地，需要启用 -persistent_profile 或为账号配置专属的远程浏览器（users.json remote_cdp）")
+}
+
+// ListDraftsForAccount 列出账号草稿箱中的草稿
+func (s *XiaohongshuService) ListDraftsForAccount(ctx context.Context, account string) (*DraftsResponse, error) {
+	if err := s.checkDraftSupported(account, ""); err != nil {
+		return nil, err
+	}
+	var drafts []xiaohongshu.Draft
+	err := s.withBrowserPageForAccount(ctx, account, "list_drafts", func(page *rod.Page) error {
+		var err error
+		drafts, err = xiaohongshu.NewDraftAction(page).List(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DraftsResponse{Drafts: drafts, Count: len(drafts)}, nil
+}
+
+// TopicSearchResponse 话题搜索结果
+type TopicSearchResponse struct {
+	Keyword string              `json:"keyword"`
+	Topics  []xiaohongshu.Topic `json:"topics"`
+	Count   int                 `json:"count"`
+}
+
+// SearchTopicsForAccount 按关键词查询话题联想列表，用于发布前确认标签会关联到哪个话题
+func (s *XiaohongshuService) SearchTopicsForAccount(ctx context.Context, account, keyword string) (*TopicSearchResponse, error) {
+	keyword = strings.TrimSpace(strings.Trim(strings.TrimSpace(keyword), "#"))
+	if keyword == "" {
+		return nil, fmt.Errorf("关键词不能为空")
+	}
+	var topics []xiaohongshu.Topic
+	err := s.withBrowserPageForAccount(ctx, account, "search_topics", func(page *rod.Page) error {
+		action, err := xiaohongshu.NewTopicSearchAction(page)
+		if err != nil {
+			return err
+		}
+		topics, err = action.Search(ctx, keyword)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TopicSearchResponse{Keyword: keyword, Topics: topics, Count: len(topics)}, nil
+}
+
+// PublishDraftForAccount 立即发布草稿箱中的草稿
+func (s *XiaohongshuService) PublishDraftForAccount(ctx context.Context, account, draftID string) (*PublishResponse, error) {
+	if err := s.checkDraftSupported(account, ""); err != nil {
+		return nil, err
+	}
+	var result *xiaohongshu.PublishResult
+	err := s.withBrowserPageForAccount(ctx, account, "publish_draft", func(page *rod.Page) error {
+		var err error
+		result, err = xiaohongshu.NewDraftAction(page).Publish(ctx, draftID)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("发布草稿失败: draft_id=%s %v", draftID, err)
+		return nil, err
+	}
+	return &PublishResponse{
+		Status:    "发布完成",
+		PostID:    result.NoteID,
+		NoteURL:   result.URL,
+		XsecToken: result.XsecToken,
+		DraftID:   draftID,
+	}, nil
+}
+
+// DeleteDraftForAccount 删除草稿箱中的草稿
+func (s *XiaohongshuService) DeleteDraftForAccount(ctx context.Context, account, draftID string) (*DeleteDraftResponse, error) {
+	if err := s.checkDraftSupported(account, ""); err != nil {
+		return nil, err
+	}
+	err := s.withBrowserPageForAccount(ctx, account, "delete_draft", func(page *rod.Page) error {
+		return xiaohongshu.NewDraftAction(page).Delete(ctx, draftID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteDraftResponse{DraftID: draftID, Success: true, Message: "草稿已删除"}, nil
+}
+
+// EditNoteRequest 编辑已发布笔记的请求，未提供的字段保持不变
+type EditNoteRequest struct {
+	Title    *string  `json:"title,omitempty"`
+	Content  *string  `json:"content,omitempty"`
+	Tags     []string `json:"tags,omitempty"` // 标签写在正文末尾，修改标签需同时提供 content
+	Location *string  `json:"location,omitempty"`
+	// ImageOrder 新的图片顺序，元素为原图片下标（从 0 开始）
+	ImageOrder []int `json:"image_order,omitempty"`
+	// ExactTags 只关联名称与标签完全一致的话题
+	ExactTags bool `json:"exact_tags,omitempty"`
+}
+
+// EditNoteResponse 编辑笔记结果
+type EditNoteResponse struct {
+	NoteID    string `json:"note_id"`
+	Status    string `json:"status"`
+	NoteURL   string `json:"note_url,omitempty"`
+	XsecToken string `json:"xsec_token,omitempty"`
+	// Topics 实际关联的话题（修改标签时返回）
+	Topics   []string `json:"topics,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// checkNoteTitle 校验标题长度（小红书限制：最大20个字）
+func checkNoteTitle(title string) error {
+	if xhsutil.CalcTitleLength(title) > 20 {
+		return fmt.Errorf("标题长度超过限制")
+	}
+	return nil
+}
+
+// checkNoteContent 校验正文长度（XHS_MCP_CONTENT_MAX_RUNES）
+func checkNoteContent(content string) error {
+	if maxRunes := configs.GetContentMaxRunes(); maxRunes > 0 {
+		if n := len([]rune(content)); n > maxRunes {
+			return fmt.Errorf("正文长度超过限制: %d/%d", n, maxRunes)
+		}
+	}
+	return nil
+}
+
+// validateEditNoteRequest 编辑前的预检，规则与发布一致
+func validateEditNoteRequest(req *EditNoteRequest) error {
+	if req.Title == nil && req.Content == nil && len(req.Tags) == 0 && req.Location == nil && len(req.ImageOrder) == 0 {
+		return fmt.Errorf("没有需要修改的内容")
+	}
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return fmt.Errorf("标题不能为空")
+		}
+		if err := checkNoteTitle(*req.Title); err != nil {
+			return err
+		}
+	}
+	if req.Content != nil {
+		if strings.TrimSpace(*req.Content) == "" {
+			return fmt.Errorf("正文不能为空")
+		}
+		if err := checkNoteContent(*req.Content); err != nil {
+			return err
+		}
+	}
+	if len(req.Tags) > 0 && req.Content == nil {
+		return fmt.Errorf("修改标签时需要同时提供正文")
+	}
+	return nil
+}
+
+// EditNoteForAccount 编辑账号已发布的笔记，保留笔记的互动数据
+func (s *XiaohongshuService) EditNoteForAccount(ctx context.Context, account, noteID string, req *EditNoteRequest) (*EditNoteResponse, error) {
+	noteID = strings.TrimSpace(noteID)
+	if noteID == "" {
+		return nil, fmt.Errorf("缺少笔记ID")
+	}
+	if err := validateEditNoteRequest(req); err != nil {
+		return nil, err
+	}
+
+	content := xiaohongshu.EditNoteContent{
+		NoteID:     noteID,
+		Title:      req.Title,
+		Content:    req.Content,
+		Tags:       req.Tags,
+		Location:   req.Location,
+		ImageOrder: req.ImageOrder,
+		ExactTags:  req.ExactTags,
+	}
+
+	var result *xiaohongshu.PublishResult
+	err := s.withBrowserPageForAccount(ctx, account, "edit_note", func(page *rod.Page) error {
+		var err error
+		result, err = xiaohongshu.NewEditNoteAction(page).Edit(ctx, content)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("编辑笔记失败: note_id=%s %v", noteID, err)
+		return nil, err
+	}
+
+	return &EditNoteResponse{
+		NoteID:    result.NoteID,
+		Status:    "编辑成功",
+		NoteURL:   result.URL,
+		XsecToken: result.XsecToken,
+		Topics:    result.Topics,
+		Warnings:  result.Warnings,
+	}, nil
+}
+
+// DeleteMyNoteForAccount 删除账号的笔记
+func (s *XiaohongshuService) DeleteMyNoteForAccount(ctx context.Context, account, noteID string) (*DeleteNoteResponse, error) {
+	err := s.withBrowserPageForAccount(ctx, account, "delete_note", func(page *rod.Page) error {
+		return xiaohongshu.NewNoteManagerAction(page).Delete(ctx, noteID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteNoteResponse{NoteID: noteID, Success: true, Message: "笔记已删除"}, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/modules/cookiestore"
+)
+
+func TestXiaohongshuService_ImportCookiesForAccount_AttachesCookieFile(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewRuntime(tempDir, 1)
+	require.NoError(t, err)
+	s := &XiaohongshuService{runtime: rt, cookieStore: cookiestore.NewStore(tempDir)}
+
+	res, err := s.ImportCookiesForAccount(context.Background(), "alice", "web_session=abc; a1=x", "")
+	require.NoError(t, err)
+	require.Equal(t, "header", res.Format)
+	require.Equal(t, 2, res.Count)
+	require.Empty(t, res.Warnings)
+
+	u, err := rt.UserPool.Resolve("alice", nil)
+	require.NoError(t, err)
+	require.Equal(t, "cookies/alice.json", u.CookieFile)
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "cookies", "alice.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"web_session"`)
+}
+
+func TestXiaohongshuService_ImportCookiesForAccount_RejectsForeignDomain(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewRuntime(tempDir, 1)
+	require.NoError(t, err)
+	s := &XiaohongshuService{runtime: rt, cookieStore: cookiestore.NewStore(tempDir)}
+
+	_, err = s.ImportCookiesForAccount(context.Background(), "bob", `[{"domain":".example.com","name":"sid","value":"1"}]`, "json")
+	require.Error(t, err)
+
+	_, err = rt.UserPool.Resolve("bob", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TraceHeader 请求头，值为 true/1 时记录本次请求的网络流量（HAR）
+	TraceHeader = "X-XHS-Trace"
+	// ArtifactHeader 响应头，返回本次请求产生的快照 ID（多个以逗号分隔）
+	ArtifactHeader = "X-XHS-Artifact-ID"
+)
+
+type requestStateKey struct{}
+
+// requestState 单次请求的追踪开关与产生的快照 ID
+type requestState struct {
+	trace bool
+
+	mu  sync.Mutex
+	ids []string
+}
+
+// withRequestState 为请求挂载追踪状态，trace 为 true 时记录 HAR
+func withRequestState(ctx context.Context, trace bool) context.Context {
+	return context.WithValue(ctx, requestStateKey{}, &requestState{trace: trace})
+}
+
+func requestStateFrom(ctx context.Context) *requestState {
+	if ctx == nil {
+		return nil
+	}
+	st, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return st
+}
+
+// traceRequested 当前请求是否开启了网络追踪
+func traceRequested(ctx context.Context) bool {
+	st := requestStateFrom(ctx)
+	return st != nil && st.trace
+}
+
+// recordArtifact 记录请求产生的快照 ID
+func recordArtifact(ctx context.Context, id string) {
+	if st := requestStateFrom(ctx); st != nil && id != "" {
+		st.mu.Lock()
+		st.ids = append(st.ids, id)
+		st.mu.Unlock()
+	}
+}
+
+// requestArtifacts 返回请求产生的快照 ID
+func requestArtifacts(ctx context.Context) []string {
+	st := requestStateFrom(ctx)
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]string(nil), st.ids...)
+}
+
+func isTruthy(v string) bool {
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	return err == nil && b
+}
+
+// traceMiddleware 读取 X-XHS-Trace 请求头或 trace 查询参数
+func traceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		trace := isTruthy(c.GetHeader(TraceHeader)) || isTruthy(c.Query("trace"))
+		c.Request = c.Request.WithContext(withRequestState(c.Request.Context(), trace))
+		c.Next()
+	}
+}
+
+// setArtifactHeader 在响应头中返回本次请求产生的快照 ID
+func setArtifactHeader(c *gin.Context) {
+	if ids := requestArtifacts(c.Request.Context()); len(ids) > 0 {
+		c.Header(ArtifactHeader, strings.Join(ids, ","))
+	}
+}
@@ -67,34 +67,38 @@ type FeedDetailResponse struct {
 
 // PostCommentRequest 发表评论请求
 type PostCommentRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
-	Content   string `json:"content" binding:"required"`
+	FeedID    string   `json:"feed_id" binding:"required"`
+	XsecToken string   `json:"xsec_token" binding:"required"`
+	Content   string   `json:"content" binding:"required"`
+	Mentions  []string `json:"mentions,omitempty"` // 评论末尾 @ 的用户昵称
 }
 
 // PostCommentResponse 发表评论响应
 type PostCommentResponse struct {
-	FeedID  string `json:"feed_id"`
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	FeedID   string   `json:"feed_id"`
+	Success  bool     `json:"success"`
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ReplyCommentRequest 回复评论请求
 type ReplyCommentRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
-	CommentID string `json:"comment_id" binding:"required_without=UserID"`
-	UserID    string `json:"user_id" binding:"required_without=CommentID"`
-	Content   string `json:"content" binding:"required"`
+	FeedID    string   `json:"feed_id" binding:"required"`
+	XsecToken string   `json:"xsec_token" binding:"required"`
+	CommentID string   `json:"comment_id" binding:"required_without=UserID"`
+	UserID    string   `json:"user_id" binding:"required_without=CommentID"`
+	Content   string   `json:"content" binding:"required"`
+	Mentions  []string `json:"mentions,omitempty"` // 回复末尾 @ 的用户昵称
 }
 
 // ReplyCommentResponse 回复评论响应
 type ReplyCommentResponse struct {
-	FeedID          string `json:"feed_id"`
-	TargetCommentID string `json:"target_comment_id,omitempty"`
-	TargetUserID    string `json:"target_user_id,omitempty"`
-	Success         bool   `json:"success"`
-	Message         string `json:"message"`
+	FeedID          string   `json:"feed_id"`
+	TargetCommentID string   `json:"target_comment_id,omitempty"`
+	TargetUserID    string   `json:"target_user_id,omitempty"`
+	Success         bool     `json:"success"`
+	Message         string   `json:"message"`
+	Warnings        []string `json:"warnings,omitempty"`
 }
 
 // UserProfileRequest 用户主页请求
@@ -109,3 +113,11 @@ type ActionResult struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// MediaCacheCleanupRequest 清理下载缓存请求，字段为空时使用缓存的默认策略
+type MediaCacheCleanupRequest struct {
+	OlderThan string `json:"older_than,omitempty"` // 如 "24h"
+	MaxBytes  int64  `json:"max_bytes,omitempty"`
+	All       bool   `json:"all,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+}
@@ -20,8 +20,8 @@ func NewCommentFeedAction(page *rod.Page) *CommentFeedAction {
 	return &CommentFeedAction{page: page}
 }
 
-// PostComment 发表评论到 Feed
-func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string) error {
+// PostComment 发表评论到 Feed，mentions 为评论末尾 @ 的用户昵称；返回未能解析的 @ 等提示
+func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string, mentions []string) ([]string, error) {
 	// 不使用 Context(ctx)，避免继承外部 context 的超时
 	page := f.page.Timeout(60 * time.Second)
 
@@ -35,29 +35,34 @@ func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken,
 
 	// 检测页面是否可访问
 	if err := checkPageAccessible(page); err != nil {
-		return err
+		return nil, err
 	}
 
 	elem, err := page.Element("div.input-box div.content-edit span")
 	if err != nil {
 		logrus.Warnf("Failed to find comment input box: %v", err)
-		return fmt.Errorf("未找到评论输入框，该帖子可能不支持评论或网页端不可访问: %w", err)
+		return nil, fmt.Errorf("未找到评论输入框，该帖子可能不支持评论或网页端不可访问: %w", err)
 	}
 
 	if err := elem.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		logrus.Warnf("Failed to click comment input box: %v", err)
-		return fmt.Errorf("无法点击评论输入框: %w", err)
+		return nil, fmt.Errorf("无法点击评论输入框: %w", err)
 	}
 
 	elem2, err := page.Element("div.input-box div.content-edit p.content-input")
 	if err != nil {
 		logrus.Warnf("Failed to find comment input field: %v", err)
-		return fmt.Errorf("未找到评论输入区域: %w", err)
+		return nil, fmt.Errorf("未找到评论输入区域: %w", err)
 	}
 
 	if err := elem2.Input(content); err != nil {
 		logrus.Warnf("Failed to input comment content: %v", err)
-		return fmt.Errorf("无法输入评论内容: %w", err)
+		return nil, fmt.Errorf("无法输入评论内容: %w", err)
+	}
+
+	warnings, err := inputMentions(elem2, mentions)
+	if err != nil {
+		return nil, fmt.Errorf("无法输入@用户: %w", err)
 	}
 
 	time.Sleep(1 * time.Second)
@@ -65,22 +70,22 @@ func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken,
 	submitButton, err := page.Element("div.bottom button.submit")
 	if err != nil {
 		logrus.Warnf("Failed to find submit button: %v", err)
-		return fmt.Errorf("未找到提交按钮: %w", err)
+		return nil, fmt.Errorf("未找到提交按钮: %w", err)
 	}
 
 	if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		logrus.Warnf("Failed to click submit button: %v", err)
-		return fmt.Errorf("无法点击提交按钮: %w", err)
+		return nil, fmt.Errorf("无法点击提交按钮: %w", err)
 	}
 
 	time.Sleep(1 * time.Second)
 
 	logrus.Infof("Comment posted successfully to feed: %s", feedID)
-	return nil
+	return warnings, nil
 }
 
-// ReplyToComment 回复指定评论
-func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToken, commentID, userID, content string) error {
+// ReplyToComment 回复指定评论，mentions 含义同 PostComment
+func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToken, commentID, userID, content string, mentions []string) ([]string, error) {
 	// 增加超时时间，因为需要滚动查找评论
 	// 注意：不使用 Context(ctx)，避免继承外部 context 的超时
 	page := f.page.Timeout(5 * time.Minute)
@@ -94,7 +99,7 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 
 	// 检测页面是否可访问
 	if err := checkPageAccessible(page); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 等待评论容器加载
@@ -103,7 +108,7 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 	// 使用 Go 实现的查找逻辑
 	commentEl, err := findCommentElement(page, commentID, userID)
 	if err != nil {
-		return fmt.Errorf("无法找到评论: %w", err)
+		return nil, fmt.Errorf("无法找到评论: %w", err)
 	}
 
 	// 滚动到评论位置
@@ -116,11 +121,11 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 	// 查找并点击回复按钮
 	replyBtn, err := commentEl.Element(".right .interactions .reply")
 	if err != nil {
-		return fmt.Errorf("无法找到回复按钮: %w", err)
+		return nil, fmt.Errorf("无法找到回复按钮: %w", err)
 	}
 
 	if err := replyBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return fmt.Errorf("点击回复按钮失败: %w", err)
+		return nil, fmt.Errorf("点击回复按钮失败: %w", err)
 	}
 
 	time.Sleep(1 * time.Second)
@@ -128,12 +133,17 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 	// 查找回复输入框
 	inputEl, err := page.Element("div.input-box div.content-edit p.content-input")
 	if err != nil {
-		return fmt.Errorf("无法找到回复输入框: %w", err)
+		return nil, fmt.Errorf("无法找到回复输入框: %w", err)
 	}
 
 	// 输入内容
 	if err := inputEl.Input(content); err != nil {
-		return fmt.Errorf("输入回复内容失败: %w", err)
+		return nil, fmt.Errorf("输入回复内容失败: %w", err)
+	}
+
+	warnings, err := inputMentions(inputEl, mentions)
+	if err != nil {
+		return nil, fmt.Errorf("输入@用户失败: %w", err)
 	}
 
 	time.Sleep(500 * time.Millisecond)
@@ -141,16 +151,16 @@ func (f *CommentFeedAction) ReplyToComment(ctx context.Context, feedID, xsecToke
 	// 查找并点击提交按钮
 	submitBtn, err := page.Element("div.bottom button.submit")
 	if err != nil {
-		return fmt.Errorf("无法找到提交按钮: %w", err)
+		return nil, fmt.Errorf("无法找到提交按钮: %w", err)
 	}
 
 	if err := submitBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return fmt.Errorf("点击提交按钮失败: %w", err)
+		return nil, fmt.Errorf("点击提交按钮失败: %w", err)
 	}
 
 	time.Sleep(2 * time.Second)
 	logrus.Infof("回复评论成功")
-	return nil
+	return warnings, nil
 }
 
 // findCommentElement 查找指定评论元素（参考 feed_detail.go 的滚动逻辑）
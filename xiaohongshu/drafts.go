@@ -0,0 +1,331 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Draft 创作者中心草稿箱中的草稿。
+// 草稿保存在浏览器本地（IndexedDB），只有持久化 profile 或远程浏览器才能跨次读取。
+type Draft struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Content   string `json:"content,omitempty"`
+	Type      string `json:"type"` // normal/video
+	UpdatedAt string `json:"updated_at,omitempty"`
+
+	updated int64
+}
+
+// draftRecord 页面读出的 IndexedDB 原始记录
+type draftRecord struct {
+	DB    string         `json:"db"`
+	Store string         `json:"store"`
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// 读取名称含 draft 的 IndexedDB 中的全部记录，过长的字符串（图片 base64 等）直接丢弃
+const jsReadDraftRecords = `async () => {
+	const req = (r) => new Promise((resolve, reject) => { r.onsuccess = () => resolve(r.result); r.onerror = () => reject(r.error); });
+	const dbs = indexedDB.databases ? await indexedDB.databases() : [];
+	const out = [];
+	for (const info of dbs) {
+		if (!info.name || !/draft/i.test(info.name)) continue;
+		const db = await req(indexedDB.open(info.name));
+		for (const store of Array.from(db.objectStoreNames)) {
+			const os = db.transaction(store, 'readonly').objectStore(store);
+			const [keys, values] = await Promise.all([req(os.getAllKeys()), req(os.getAll())]);
+			keys.forEach((k, i) => out.push({ db: info.name, store, key: String(k), value: values[i] }));
+		}
+		db.close();
+	}
+	return JSON.stringify(out, (k, v) => (typeof v === 'string' && v.length > 5000) ? undefined : v);
+}`
+
+// parseDrafts 把 IndexedDB 记录整理为草稿列表，按更新时间倒序
+func parseDrafts(raw []byte) ([]Draft, error) {
+	var records []draftRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, errors.Wrap(err, "解析草稿数据失败")
+	}
+
+	drafts := make([]Draft, 0, len(records))
+	for _, r := range records {
+		if r.Value == nil {
+			continue
+		}
+		d := Draft{
+			ID:      firstString(r.Value, "id", "draftId", "draft_id"),
+			Title:   firstString(r.Value, "title", "noteTitle"),
+			Content: firstString(r.Value, "desc", "content"),
+			Type:    draftType(r.Value, r.Store),
+		}
+		if d.ID == "" {
+			d.ID = r.Key
+		}
+		d.updated = firstMillis(r.Value, "updateTime", "update_time", "updatedAt", "time", "createTime")
+		if d.updated > 0 {
+			d.UpdatedAt = time.UnixMilli(d.updated).Format(time.RFC3339)
+		}
+		drafts = append(drafts, d)
+	}
+	sort.SliceStable(drafts, func(i, j int) bool { return drafts[i].updated > drafts[j].updated })
+	return drafts, nil
+}
+
+func draftType(v map[string]any, store string) string {
+	t := strings.ToLower(firstString(v, "type", "noteType", "note_type"))
+	if strings.Contains(t, "video") || strings.Contains(strings.ToLower(store), "video") {
+		return "video"
+	}
+	return "normal"
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// firstMillis 读取毫秒时间戳，兼容数字、数字字符串与 RFC3339
+func firstMillis(m map[string]any, keys ...string) int64 {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case float64:
+			if v > 0 {
+				return int64(v)
+			}
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+				return n
+			}
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.UnixMilli()
+			}
+		}
+	}
+	return 0
+}
+
+// readDrafts 在发布页读取草稿箱，调用前页面需位于创作者中心
+func readDrafts(page *rod.Page) ([]Draft, error) {
+	res, err := page.Eval(jsReadDraftRecords)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取草稿箱失败")
+	}
+	return parseDrafts([]byte(res.Value.Str()))
+}
+
+// saveDraft 点击“暂存离开”把当前编辑内容存入草稿箱，并返回新草稿的 ID。
+// 只认保存前快照中不存在的草稿 ID，不按标题匹配，避免取到之前的同名草稿。
+func saveDraft(page *rod.Page, title string) (*PublishResult, error) {
+	before, err := readDrafts(page)
+	if err != nil {
+		return nil, errors.Wrap(err, "保存前读取草稿箱失败，无法确认新草稿")
+	}
+
+	if err := clickButtonByText(page, "暂存离开", "存草稿", "保存草稿"); err != nil {
+		return nil, errors.Wrap(err, "保存草稿失败")
+	}
+	time.Sleep(2 * time.Second)
+
+	after, err := readDrafts(page)
+	if err != nil {
+		return nil, errors.Wrap(err, "保存后读取草稿箱失败")
+	}
+	d := newDraft(before, after)
+	if d == nil {
+		return nil, errors.New("草稿箱中未找到刚保存的草稿")
+	}
+	if strings.TrimSpace(d.Title) != strings.TrimSpace(title) {
+		logrus.Warnf("新草稿标题与填写的不一致: draft_id=%s title=%s", d.ID, d.Title)
+	}
+	logrus.Infof("已存入草稿箱: draft_id=%s title=%s", d.ID, d.Title)
+	return &PublishResult{DraftID: d.ID}, nil
+}
+
+// newDraft 返回 after 中不在 before 里的最新草稿
+func newDraft(before, after []Draft) *Draft {
+	known := make(map[string]bool, len(before))
+	for _, d := range before {
+		known[d.ID] = true
+	}
+	for i := range after {
+		if !known[after[i].ID] {
+			return &after[i]
+		}
+	}
+	return nil
+}
+
+// clickButtonByText 点击页面上文字完全匹配的第一个可见按钮
+func clickButtonByText(page *rod.Page, texts ...string) error {
+	res, err := page.Eval(`(texts) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		const btn = Array.from(document.querySelectorAll('button, .d-button, span, div'))
+			.filter(el => isVisible(el) && texts.includes((el.textContent || '').trim()))
+			.sort((a, b) => a.children.length - b.children.length)[0];
+		if (!btn) return 'not_found';
+		btn.scrollIntoView({ block: 'center' });
+		btn.click();
+		return 'ok';
+	}`, texts)
+	if err != nil {
+		return errors.Wrap(err, "点击按钮失败")
+	}
+	if res.Value.String() != "ok" {
+		return errors.Errorf("没有找到按钮: %s", strings.Join(texts, "/"))
+	}
+	return nil
+}
+
+type DraftAction struct {
+	page *rod.Page
+}
+
+func NewDraftAction(page *rod.Page) *DraftAction {
+	return &DraftAction{page: page}
+}
+
+// List 列出草稿箱中的草稿
+func (a *DraftAction) List(ctx context.Context) ([]Draft, error) {
+	page := a.page.Context(ctx).Timeout(60 * time.Second)
+	if err := openPublishPage(page); err != nil {
+		return nil, err
+	}
+	return readDrafts(page)
+}
+
+// Publish 从草稿箱打开草稿并立即发布
+func (a *DraftAction) Publish(ctx context.Context, draftID string) (*PublishResult, error) {
+	page := a.page.Context(ctx).Timeout(300 * time.Second)
+
+	draft, err := a.open(page, draftID, "编辑")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := page.Timeout(60 * time.Second).Element("div.d-input input"); err != nil {
+		return nil, errors.Wrap(err, "草稿编辑页未加载")
+	}
+
+	w := watchResponses(page, isPublishAPI)
+	defer w.Stop()
+	started := time.Now()
+
+	btn, err := waitForPublishButtonClickable(page)
+	if err != nil {
+		return nil, err
+	}
+	if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, errors.Wrap(err, "点击发布按钮失败")
+	}
+	if err := waitForPublishResult(page); err != nil {
+		return nil, errors.Wrap(err, "小红书发布草稿失败")
+	}
+	return collectPublishResult(page, w, draft.Title, started), nil
+}
+
+// Delete 从草稿箱删除草稿，删除后重新读取确认
+func (a *DraftAction) Delete(ctx context.Context, draftID string) error {
+	page := a.page.Context(ctx).Timeout(120 * time.Second)
+
+	_, target, err := a.locate(page, draftID)
+	if err != nil {
+		return err
+	}
+	// 草稿箱本身是弹窗，只在点击删除后新弹出的确认框中确认
+	err = confirmOpenedDialog(page, func() error {
+		return clickCardAction(page, "删除", target)
+	})
+	if err != nil {
+		return err
+	}
+	time.Sleep(1500 * time.Millisecond)
+
+	drafts, err := readDrafts(page)
+	if err != nil {
+		return errors.Wrap(err, "删除后确认草稿箱失败")
+	}
+	for _, d := range drafts {
+		if d.ID == draftID {
+			return errors.Errorf("删除后草稿仍在草稿箱中: %s", draftID)
+		}
+	}
+	logrus.Infof("草稿已删除: %s", draftID)
+	return nil
+}
+
+// open 打开草稿箱并点击草稿卡片上的 label 按钮
+func (a *DraftAction) open(page *rod.Page, draftID, label string) (*Draft, error) {
+	draft, target, err := a.locate(page, draftID)
+	if err != nil {
+		return nil, err
+	}
+	if err := clickCardAction(page, label, target); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// locate 打开草稿箱，返回草稿及其卡片定位信息
+func (a *DraftAction) locate(page *rod.Page, draftID string) (*Draft, cardTarget, error) {
+	if err := openPublishPage(page); err != nil {
+		return nil, cardTarget{}, err
+	}
+	drafts, err := readDrafts(page)
+	if err != nil {
+		return nil, cardTarget{}, err
+	}
+	idx := -1
+	for i := range drafts {
+		if drafts[i].ID == draftID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, cardTarget{}, errors.Errorf("未找到草稿: %s", draftID)
+	}
+
+	if err := clickButtonByText(page, fmt.Sprintf("草稿箱(%d)", len(drafts)), "草稿箱"); err != nil {
+		return nil, cardTarget{}, errors.Wrap(err, "打开草稿箱失败")
+	}
+	time.Sleep(1 * time.Second)
+	titles := make([]string, len(drafts))
+	for i, d := range drafts {
+		titles[i] = d.Title
+	}
+	return &drafts[idx], newCardTarget(draftID, idx, titles), nil
+}
+
+func openPublishPage(page *rod.Page) error {
+	if err := page.Navigate(urlOfPublic); err != nil {
+		return errors.Wrap(err, "导航到发布页面失败")
+	}
+	if err := page.WaitLoad(); err != nil {
+		logrus.Warnf("等待页面加载出现问题: %v，继续尝试", err)
+	}
+	if err := page.WaitDOMStable(time.Second, 0.1); err != nil {
+		logrus.Warnf("等待 DOM 稳定出现问题: %v，继续尝试", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDrafts(t *testing.T) {
+	raw := []byte(`[
+		{"db":"draft-database-v1","store":"image-draft","key":"101","value":{"title":"旧草稿","desc":"正文A","updateTime":1700000000000}},
+		{"db":"draft-database-v1","store":"video-draft","key":"202","value":{"id":"v-202","title":"视频草稿","updateTime":"1700000100000"}},
+		{"db":"draft-database-v1","store":"image-draft","key":"303","value":{"title":"无时间"}},
+		{"db":"draft-database-v1","store":"meta","key":"x","value":null}
+	]`)
+
+	drafts, err := parseDrafts(raw)
+	require.NoError(t, err)
+	require.Len(t, drafts, 3)
+
+	assert.Equal(t, "v-202", drafts[0].ID)
+	assert.Equal(t, "video", drafts[0].Type)
+	assert.NotEmpty(t, drafts[0].UpdatedAt)
+
+	assert.Equal(t, "101", drafts[1].ID)
+	assert.Equal(t, "旧草稿", drafts[1].Title)
+	assert.Equal(t, "正文A", drafts[1].Content)
+	assert.Equal(t, "normal", drafts[1].Type)
+
+	assert.Equal(t, "303", drafts[2].ID)
+	assert.Empty(t, drafts[2].UpdatedAt)
+
+	_, err = parseDrafts([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNewDraftIgnoresExistingSameTitle(t *testing.T) {
+	before := []Draft{{ID: "1", Title: "周末探店"}}
+	// 同名的旧草稿不算新草稿
+	assert.Nil(t, newDraft(before, []Draft{{ID: "1", Title: "周末探店"}}))
+
+	d := newDraft(before, []Draft{{ID: "2", Title: "周末探店"}, {ID: "1", Title: "周末探店"}})
+	require.NotNil(t, d)
+	assert.Equal(t, "2", d.ID)
+}
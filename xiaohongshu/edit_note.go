@@ -0,0 +1,375 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// 创作者中心编辑已发布笔记的页面
+const urlOfEditNote = `https://creator.xiaohongshu.com/publish/update?id=%s`
+
+// EditNoteContent 编辑笔记的内容，nil/空的字段保持不变
+type EditNoteContent struct {
+	NoteID   string
+	Title    *string
+	Content  *string
+	Tags     []string
+	Location *string
+	// ImageOrder 新的图片顺序，元素为原图片的下标（从 0 开始），如 [2,0,1]
+	ImageOrder []int
+	// ExactTags 只关联名称与标签完全一致的话题
+	ExactTags bool
+}
+
+// imageMove 一次拖拽：把 From 位置的图片移动到 To 位置
+type imageMove struct {
+	From int
+	To   int
+}
+
+type EditNoteAction struct {
+	page *rod.Page
+}
+
+func NewEditNoteAction(page *rod.Page) *EditNoteAction {
+	return &EditNoteAction{page: page}
+}
+
+// Edit 打开笔记编辑页修改内容并提交，返回笔记的 ID、链接与 xsec_token
+func (a *EditNoteAction) Edit(ctx context.Context, content EditNoteContent) (*PublishResult, error) {
+	if content.NoteID == "" {
+		return nil, errors.New("笔记 ID 不能为空")
+	}
+	if len(content.Tags) > 0 && content.Content == nil {
+		// 标签写在正文末尾，只改标签会与原有标签重复
+		return nil, errors.New("修改标签时需要同时提供正文")
+	}
+
+	page := a.page.Context(ctx).Timeout(300 * time.Second)
+
+	if err := openEditPage(page, content.NoteID); err != nil {
+		return nil, err
+	}
+
+	if len(content.ImageOrder) > 0 {
+		if err := reorderImages(page, content.ImageOrder); err != nil {
+			return nil, errors.Wrap(err, "调整图片顺序失败")
+		}
+	}
+
+	tags := content.Tags
+	if len(tags) >= 10 {
+		logrus.Warnf("标签数量超过10，截取前10个标签")
+		tags = tags[:10]
+	}
+
+	logrus.Infof("编辑笔记: id=%s, title=%v, content=%v, tags=%v, location=%v, image_order=%v",
+		content.NoteID, content.Title != nil, content.Content != nil, tags, content.Location != nil, content.ImageOrder)
+
+	filled, err := submitEdit(page, content.Title, content.Content, tags, content.ExactTags, content.Location)
+	if err != nil {
+		return nil, errors.Wrap(err, "小红书编辑笔记失败")
+	}
+
+	res := &PublishResult{NoteID: content.NoteID}
+	filled.applyTo(res)
+	if note, err := findPostedNote(page, content.NoteID, "", time.Time{}); err == nil {
+		res.XsecToken = note.XsecToken
+	} else {
+		logrus.Warnf("从笔记管理获取笔记信息失败: %v", err)
+	}
+	res.URL = NoteURL(res.NoteID, res.XsecToken)
+	return res, nil
+}
+
+func openEditPage(page *rod.Page, noteID string) error {
+	if err := page.Navigate(fmt.Sprintf(urlOfEditNote, url.QueryEscape(noteID))); err != nil {
+		return errors.Wrap(err, "打开笔记编辑页失败")
+	}
+	if err := page.WaitLoad(); err != nil {
+		logrus.Warnf("等待页面加载出现问题: %v，继续尝试", err)
+	}
+	if err := page.WaitDOMStable(time.Second, 0.1); err != nil {
+		logrus.Warnf("等待 DOM 稳定出现问题: %v，继续尝试", err)
+	}
+
+	// 编辑页与发布页共用编辑器，标题框出现即认为笔记已加载
+	if _, err := page.Timeout(30 * time.Second).Element("div.d-input input"); err != nil {
+		return errors.Wrapf(err, "笔记编辑页未加载，笔记可能不存在或不可编辑: %s", noteID)
+	}
+	time.Sleep(1 * time.Second)
+	return nil
+}
+
+func submitEdit(page *rod.Page, title, content *string, tags []string, exactTags bool, location *string) (*editorInput, error) {
+	filled := &editorInput{}
+	if title != nil {
+		titleElem, err := page.Element("div.d-input input")
+		if err != nil {
+			return nil, errors.Wrap(err, "查找标题输入框失败")
+		}
+		if err := titleElem.SelectAllText(); err != nil {
+			return nil, errors.Wrap(err, "选中原标题失败")
+		}
+		if err := titleElem.Input(*title); err != nil {
+			return nil, errors.Wrap(err, "输入标题失败")
+		}
+
+		time.Sleep(500 * time.Millisecond)
+		if err := checkTitleMaxLength(page); err != nil {
+			return nil, err
+		}
+	}
+
+	if content != nil {
+		contentElem, ok := getContentElement(page)
+		if !ok {
+			return nil, errors.New("没有找到内容输入框")
+		}
+		// 清空正文会一并删除原有话题，没有传入新标签时重新关联原有话题
+		var kept []string
+		if len(tags) == 0 {
+			attached, err := readAttachedTopics(contentElem)
+			if err != nil {
+				logrus.Warnf("读取原有话题失败: %v", err)
+				filled.Warnings = append(filled.Warnings, "无法读取笔记原有的话题，修改正文后原有话题可能丢失")
+			}
+			tags, exactTags, kept = attached, true, attached
+		}
+		if err := clearContent(contentElem); err != nil {
+			return nil, err
+		}
+		if err := contentElem.Input(*content); err != nil {
+			return nil, errors.Wrap(err, "输入正文失败")
+		}
+		topics, warnings, err := inputTags(contentElem, tags, exactTags)
+		if err != nil {
+			return nil, err
+		}
+		filled.Topics = topics
+		filled.Warnings = append(filled.Warnings, warnings...)
+		if len(kept) > 0 {
+			logrus.Infof("修改正文后重新关联原有话题: %v", kept)
+		}
+
+		time.Sleep(1 * time.Second)
+		if err := checkContentMaxLength(page); err != nil {
+			return nil, err
+		}
+	}
+
+	if location != nil {
+		if err := setPublishLocation(page, *location); err != nil {
+			return nil, errors.Wrap(err, "设置地点失败")
+		}
+	}
+
+	submitButton, err := waitForPublishButtonClickable(page)
+	if err != nil {
+		return nil, err
+	}
+	if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, errors.Wrap(err, "点击发布按钮失败")
+	}
+
+	return filled, waitForPublishResult(page)
+}
+
+// clearContent 清空正文编辑器中的原有内容（含标签）
+func clearContent(contentElem *rod.Element) error {
+	if err := contentElem.Focus(); err != nil {
+		return errors.Wrap(err, "聚焦正文输入框失败")
+	}
+	ka, err := contentElem.KeyActions()
+	if err != nil {
+		return errors.Wrap(err, "创建键盘操作失败")
+	}
+	if err := ka.Press(input.ControlLeft).Type(input.KeyA).Release(input.ControlLeft).Type(input.Backspace).Do(); err != nil {
+		return errors.Wrap(err, "清空正文失败")
+	}
+	time.Sleep(300 * time.Millisecond)
+	return nil
+}
+
+// readAttachedTopics 读取正文编辑器中已关联的话题名称
+func readAttachedTopics(contentElem *rod.Element) ([]string, error) {
+	res, err := contentElem.Eval(`function (sel) {
+		const nodes = Array.from(this.querySelectorAll(sel));
+		// 嵌套匹配的节点只保留最外层
+		return nodes.filter(n => !nodes.some(o => o !== n && o.contains(n))).map(n => n.textContent || '');
+	}`, topicNodeSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取话题节点失败")
+	}
+	var texts []string
+	for _, v := range res.Value.Arr() {
+		texts = append(texts, v.String())
+	}
+	return topicNames(texts), nil
+}
+
+// topicNames 把话题节点文本（如 "#旅行[话题]#"）转换为去重后的话题名称
+func topicNames(texts []string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, t := range texts {
+		name := strings.TrimSpace(t)
+		name = strings.Trim(name, "#")
+		name = strings.TrimSpace(strings.TrimSuffix(name, "[话题]"))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// validateImageOrder 校验新顺序是 0..n-1 的一个排列
+func validateImageOrder(order []int, n int) error {
+	if len(order) != n {
+		return errors.Errorf("图片顺序数量不匹配: 笔记有 %d 张图片，传入 %d 个", n, len(order))
+	}
+	seen := make([]bool, n)
+	for _, idx := range order {
+		if idx < 0 || idx >= n {
+			return errors.Errorf("图片下标越界: %d（有效范围 0-%d）", idx, n-1)
+		}
+		if seen[idx] {
+			return errors.Errorf("图片下标重复: %d", idx)
+		}
+		seen[idx] = true
+	}
+	return nil
+}
+
+// planImageMoves 计算把图片排成 order 所需的拖拽步骤，每步把一张图片拖到目标位置
+func planImageMoves(order []int) []imageMove {
+	cur := make([]int, len(order))
+	for i := range cur {
+		cur[i] = i
+	}
+	var moves []imageMove
+	for to, want := range order {
+		from := to
+		for cur[from] != want {
+			from++
+		}
+		if from == to {
+			continue
+		}
+		moves = append(moves, imageMove{From: from, To: to})
+		// 模拟拖拽后的顺序：被拖动的图片插入 to，其余后移
+		v := cur[from]
+		copy(cur[to+1:from+1], cur[to:from])
+		cur[to] = v
+	}
+	return moves
+}
+
+// imagePreviewSelector 编辑器中图片预览项
+const imagePreviewSelector = ".img-preview-area .pr, .img-preview-area .img-preview"
+
+func imagePreviewSources(page *rod.Page) ([]string, error) {
+	res, err := page.Eval(`(sel) => Array.from(document.querySelectorAll(sel))
+		.map(el => { const img = el.querySelector('img'); return img ? img.src : ''; })
+		.filter(Boolean)`, imagePreviewSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取图片列表失败")
+	}
+	var srcs []string
+	for _, v := range res.Value.Arr() {
+		srcs = append(srcs, v.String())
+	}
+	return srcs, nil
+}
+
+// reorderImages 拖拽预览图调整顺序，完成后按图片地址校验结果
+func reorderImages(page *rod.Page, order []int) error {
+	before, err := imagePreviewSources(page)
+	if err != nil {
+		return err
+	}
+	if err := validateImageOrder(order, len(before)); err != nil {
+		return err
+	}
+
+	for _, mv := range planImageMoves(order) {
+		if err := dragImage(page, mv.From, mv.To); err != nil {
+			return err
+		}
+		time.Sleep(800 * time.Millisecond)
+	}
+
+	after, err := imagePreviewSources(page)
+	if err != nil {
+		return err
+	}
+	for i, idx := range order {
+		if i >= len(after) || after[i] != before[idx] {
+			return errors.Errorf("图片顺序未生效: 期望第 %d 张为原第 %d 张", i+1, idx+1)
+		}
+	}
+	logrus.Infof("图片顺序已调整: %v", order)
+	return nil
+}
+
+func dragImage(page *rod.Page, from, to int) error {
+	items, err := page.Elements(imagePreviewSelector)
+	if err != nil {
+		return errors.Wrap(err, "查找图片预览失败")
+	}
+	if from >= len(items) || to >= len(items) {
+		return errors.Errorf("图片预览数量不足: %d", len(items))
+	}
+
+	src, err := elementCenter(items[from])
+	if err != nil {
+		return err
+	}
+	dst, err := elementCenter(items[to])
+	if err != nil {
+		return err
+	}
+
+	mouse := page.Mouse
+	if err := mouse.MoveTo(src); err != nil {
+		return errors.Wrap(err, "移动鼠标失败")
+	}
+	if err := mouse.Down(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "按下鼠标失败")
+	}
+	// 分段移动，触发页面的拖拽排序
+	if err := mouse.MoveLinear(dst, 15); err != nil {
+		return errors.Wrap(err, "拖动图片失败")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if err := mouse.Up(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "松开鼠标失败")
+	}
+	return nil
+}
+
+func elementCenter(el *rod.Element) (proto.Point, error) {
+	if err := el.ScrollIntoView(); err != nil {
+		return proto.Point{}, errors.Wrap(err, "滚动到图片失败")
+	}
+	shape, err := el.Shape()
+	if err != nil || shape == nil {
+		return proto.Point{}, errors.New("获取图片位置失败")
+	}
+	pt := shape.OnePointInside()
+	if pt == nil {
+		return proto.Point{}, errors.New("图片不可见")
+	}
+	return *pt, nil
+}
@@ -0,0 +1,48 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateImageOrder(t *testing.T) {
+	assert.NoError(t, validateImageOrder([]int{2, 0, 1}, 3))
+	assert.Error(t, validateImageOrder([]int{0, 1}, 3), "数量不匹配")
+	assert.Error(t, validateImageOrder([]int{0, 3, 1}, 3), "下标越界")
+	assert.Error(t, validateImageOrder([]int{0, 0, 1}, 3), "下标重复")
+}
+
+func TestPlanImageMoves(t *testing.T) {
+	apply := func(n int, moves []imageMove) []int {
+		cur := make([]int, n)
+		for i := range cur {
+			cur[i] = i
+		}
+		for _, mv := range moves {
+			v := cur[mv.From]
+			cur = append(cur[:mv.From], cur[mv.From+1:]...)
+			cur = append(cur[:mv.To], append([]int{v}, cur[mv.To:]...)...)
+		}
+		return cur
+	}
+
+	for _, order := range [][]int{
+		{0, 1, 2},
+		{2, 0, 1},
+		{1, 0, 2, 3},
+		{3, 2, 1, 0},
+		{1, 3, 0, 2},
+	} {
+		moves := planImageMoves(order)
+		assert.Equal(t, order, apply(len(order), moves), "order=%v moves=%v", order, moves)
+	}
+
+	assert.Empty(t, planImageMoves([]int{0, 1, 2}))
+	assert.Equal(t, []imageMove{{From: 2, To: 0}}, planImageMoves([]int{2, 0, 1}))
+}
+
+func TestTopicNames(t *testing.T) {
+	names := topicNames([]string{"#旅行[话题]#", " #美食 ", "旅行", "#[话题]#", ""})
+	assert.Equal(t, []string{"旅行", "美食"}, names)
+}
@@ -2,14 +2,206 @@ package xiaohongshu
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/errors"
 )
 
+const (
+	// maxFeedsCount 单次最多获取的 Feed 数量
+	maxFeedsCount = 500
+	// maxIdleScrolls 连续多少次滚动没有新内容时认为已到底
+	maxIdleScrolls = 3
+)
+
+// FeedChannel 发现页频道
+type FeedChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// DefaultFeedChannel 推荐频道
+const DefaultFeedChannel = "homefeed_recommend"
+
+// FeedChannels 发现页支持的频道
+var FeedChannels = []FeedChannel{
+	{ID: DefaultFeedChannel, Name: "推荐", Key: "recommend"},
+	{ID: "homefeed.fashion_v3", Name: "穿搭", Key: "fashion"},
+	{ID: "homefeed.food_v3", Name: "美食", Key: "food"},
+	{ID: "homefeed.cosmetics_v3", Name: "彩妆", Key: "cosmetics"},
+	{ID: "homefeed.movie_and_tv_v3", Name: "影视", Key: "movie"},
+	{ID: "homefeed.career_v3", Name: "职场", Key: "career"},
+	{ID: "homefeed.love_v3", Name: "情感", Key: "love"},
+	{ID: "homefeed.household_product_v3", Name: "家居", Key: "household"},
+	{ID: "homefeed.gaming_v3", Name: "游戏", Key: "gaming"},
+	{ID: "homefeed.travel_v3", Name: "旅行", Key: "travel"},
+	{ID: "homefeed.fitness_v3", Name: "健身", Key: "fitness"},
+}
+
+// ResolveFeedChannel 把频道名称（中文名、英文 key 或频道 ID）转换为频道 ID，为空时返回推荐频道
+func ResolveFeedChannel(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultFeedChannel, nil
+	}
+	for _, ch := range FeedChannels {
+		if name == ch.Name || strings.EqualFold(name, ch.Key) || strings.EqualFold(name, ch.ID) {
+			return ch.ID, nil
+		}
+	}
+	names := make([]string, len(FeedChannels))
+	for i, ch := range FeedChannels {
+		names[i] = ch.Name
+	}
+	return "", fmt.Errorf("不支持的频道: %s，可选: %s", name, strings.Join(names, "|"))
+}
+
+// FeedsListOptions 首页 Feed 列表的获取选项
+type FeedsListOptions struct {
+	// Channel 频道，支持中文名、英文 key 或频道 ID，默认推荐
+	Channel string `json:"channel,omitempty"`
+	// Count 需要获取的数量，0 表示只取首屏
+	Count int `json:"count,omitempty"`
+	// Cursor 上一次返回的 next_cursor，用于继续获取后面的内容
+	Cursor string `json:"cursor,omitempty"`
+	// ScrollSpeed 滚动速度: slow|normal|fast
+	ScrollSpeed string `json:"scroll_speed,omitempty"`
+	// Account 当前账号，游标只能由创建它的账号继续使用
+	Account string `json:"-"`
+}
+
+// FeedsPage 一页 Feed 列表
+type FeedsPage struct {
+	Channel    string `json:"channel"`
+	Feeds      []Feed `json:"feeds"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// feedsCursor 分页游标：分页会话与会话内已返回的数量
+type feedsCursor struct {
+	Channel string `json:"c"`
+	Session string `json:"s"`
+	Offset  int    `json:"o"`
+}
+
+const (
+	// feedsSessionTTL 分页会话超过该时长未使用即失效
+	feedsSessionTTL = 30 * time.Minute
+	// maxFeedsSessions 同时保留的分页会话数量
+	maxFeedsSessions = 64
+)
+
+// feedsSession 一次分页浏览的状态。发现页各频道都是推荐流，每次打开的顺序不同，
+// 按位置跳过前面的内容既慢又会重复或遗漏；会话按加载顺序保存已见过的全部 Feed，
+// 翻页时重新打开频道只追加没见过的内容，已返回的内容不会再出现
+type feedsSession struct {
+	mu        sync.Mutex
+	channel   string
+	account   string
+	feeds     []Feed
+	seen      map[string]bool
+	exhausted bool
+	usedAt    time.Time
+}
+
+var (
+	feedsSessionsMu sync.Mutex
+	feedsSessions   = map[string]*feedsSession{}
+)
+
+// newFeedsSession 创建分页会话，同时清理过期与超出数量上限的旧会话
+func newFeedsSession(channel, account string) (string, *feedsSession) {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	id := hex.EncodeToString(buf)
+	sess := &feedsSession{channel: channel, account: account, seen: map[string]bool{}, usedAt: time.Now()}
+
+	feedsSessionsMu.Lock()
+	defer feedsSessionsMu.Unlock()
+	var oldestID string
+	for k, v := range feedsSessions {
+		if time.Since(v.usedAt) > feedsSessionTTL {
+			delete(feedsSessions, k)
+			continue
+		}
+		if oldestID == "" || v.usedAt.Before(feedsSessions[oldestID].usedAt) {
+			oldestID = k
+		}
+	}
+	if len(feedsSessions) >= maxFeedsSessions && oldestID != "" {
+		delete(feedsSessions, oldestID)
+	}
+	feedsSessions[id] = sess
+	return id, sess
+}
+
+// lookupFeedsSession 按游标取回分页会话
+func lookupFeedsSession(cur feedsCursor, account string) (*feedsSession, error) {
+	feedsSessionsMu.Lock()
+	defer feedsSessionsMu.Unlock()
+	sess, ok := feedsSessions[cur.Session]
+	if !ok || time.Since(sess.usedAt) > feedsSessionTTL {
+		delete(feedsSessions, cur.Session)
+		return nil, errors.ErrFeedsCursorExpired
+	}
+	if sess.account != account {
+		return nil, fmt.Errorf("游标不属于当前账号")
+	}
+	sess.usedAt = time.Now()
+	return sess, nil
+}
+
+func encodeFeedsCursor(c feedsCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeFeedsCursor(s string) (feedsCursor, error) {
+	var c feedsCursor
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return c, fmt.Errorf("无效的游标: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 || c.Session == "" {
+		return c, fmt.Errorf("无效的游标: %s", s)
+	}
+	return c, nil
+}
+
+// mergeFeeds 按 Feed ID 去重追加，返回新增数量
+func mergeFeeds(dst []Feed, seen map[string]bool, src []Feed) ([]Feed, int) {
+	added := 0
+	for _, feed := range src {
+		if feed.ID == "" || seen[feed.ID] {
+			continue
+		}
+		seen[feed.ID] = true
+		dst = append(dst, feed)
+		added++
+	}
+	return dst, added
+}
+
+// feedsChannelURL 频道对应的发现页地址
+func feedsChannelURL(channel string) string {
+	if channel == DefaultFeedChannel {
+		return "https://www.xiaohongshu.com/explore"
+	}
+	return "https://www.xiaohongshu.com/explore?channel_id=" + url.QueryEscape(channel)
+}
+
 type FeedsListAction struct {
 	page *rod.Page
 }
@@ -29,6 +221,132 @@ func (f *FeedsListAction) GetFeedsList(ctx context.Context) ([]Feed, error) {
 
 	time.Sleep(1 * time.Second)
 
+	return readFeeds(page)
+}
+
+// ListFeeds 打开指定频道，通过模拟滚动加载更多内容，直到达到需要的数量或没有更多内容。
+// 带游标时在同一分页会话中继续，只返回之前没有返回过的内容
+func (f *FeedsListAction) ListFeeds(ctx context.Context, opts FeedsListOptions) (*FeedsPage, error) {
+	if opts.Count < 0 {
+		return nil, fmt.Errorf("count 不能为负数: %d", opts.Count)
+	}
+	channel, err := ResolveFeedChannel(opts.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sessionID string
+		sess      *feedsSession
+		offset    int
+	)
+	if opts.Cursor != "" {
+		cur, err := decodeFeedsCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Channel != "" && cur.Channel != channel {
+			return nil, fmt.Errorf("游标属于频道 %s，与请求的频道 %s 不一致", cur.Channel, channel)
+		}
+		if sess, err = lookupFeedsSession(cur, opts.Account); err != nil {
+			return nil, err
+		}
+		sessionID, channel, offset = cur.Session, sess.channel, cur.Offset
+	} else {
+		sessionID, sess = newFeedsSession(channel, opts.Account)
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if offset < 0 || offset > len(sess.feeds) {
+		return nil, fmt.Errorf("无效的游标偏移: %d", offset)
+	}
+
+	count := min(opts.Count, maxFeedsCount)
+	speed := opts.ScrollSpeed
+	if speed == "" {
+		speed = "normal"
+	}
+
+	// count 为 0 时只取首屏（续取时为重新打开后新出现的一屏）
+	target := offset + count
+	if count == 0 || target > len(sess.feeds) {
+		if err := sess.load(ctx, f.page.Context(ctx), speed, target, count == 0); err != nil {
+			return nil, err
+		}
+	}
+	if count == 0 {
+		target = len(sess.feeds)
+	}
+
+	if len(sess.feeds) == 0 {
+		return nil, errors.ErrNoFeeds
+	}
+
+	result := &FeedsPage{Channel: channel}
+	end := min(len(sess.feeds), target)
+	result.Feeds = append([]Feed(nil), sess.feeds[offset:end]...)
+	result.HasMore = !sess.exhausted || end < len(sess.feeds)
+	if result.HasMore {
+		result.NextCursor = encodeFeedsCursor(feedsCursor{Channel: channel, Session: sessionID, Offset: end})
+	}
+	logrus.Infof("频道 %s 获取 %d 条 Feed（偏移 %d）", channel, len(result.Feeds), offset)
+	return result, nil
+}
+
+// load 打开频道，把没见过的 Feed 追加到会话中，直到总数达到 target 或连续滚动没有新内容；
+// firstScreen 为 true 时只读取首屏
+func (s *feedsSession) load(ctx context.Context, page *rod.Page, speed string, target int, firstScreen bool) error {
+	if s.channel != DefaultFeedChannel {
+		page.MustNavigate(feedsChannelURL(s.channel))
+		page.MustWaitDOMStable()
+	}
+	time.Sleep(1 * time.Second)
+
+	screen, err := readFeeds(page)
+	if err != nil {
+		return err
+	}
+	var added int
+	s.feeds, added = mergeFeeds(s.feeds, s.seen, screen)
+	s.exhausted = false
+	if firstScreen {
+		// 续取时首屏全是已见过的内容，继续滚动到出现新内容为止
+		if added > 0 || len(s.feeds) == 0 {
+			return nil
+		}
+		target = len(s.feeds) + 1
+	}
+
+	idle := 0
+	for len(s.feeds) < target {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		scrolled, _, _ := humanScroll(page, speed, false, 1)
+		time.Sleep(getScrollInterval(speed))
+
+		screen, err := readFeeds(page)
+		if err != nil {
+			return err
+		}
+		s.feeds, added = mergeFeeds(s.feeds, s.seen, screen)
+		if added > 0 {
+			idle = 0
+			logrus.Debugf("频道 %s 已加载 %d/%d 条", s.channel, len(s.feeds), target)
+			continue
+		}
+		idle++
+		if !scrolled || idle >= maxIdleScrolls {
+			s.exhausted = true
+			break
+		}
+	}
+	return nil
+}
+
+// readFeeds 读取页面状态中当前已加载的全部 Feed
+func readFeeds(page *rod.Page) ([]Feed, error) {
 	result := page.MustEval(`() => {
 		if (window.__INITIAL_STATE__ &&
 		    window.__INITIAL_STATE__.feed &&
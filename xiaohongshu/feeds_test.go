@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/go-rod/rod"
 	"github.com/stretchr/testify/require"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/errors"
 )
 
 func TestGetFeedsList(t *testing.T) {
@@ -86,3 +88,84 @@ func TestGetFeedsList(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveFeedChannel(t *testing.T) {
+	for _, name := range []string{"", "推荐", "recommend"} {
+		id, err := ResolveFeedChannel(name)
+		require.NoError(t, err)
+		require.Equal(t, DefaultFeedChannel, id)
+	}
+	for _, name := range []string{"美食", "Food", "homefeed.food_v3"} {
+		id, err := ResolveFeedChannel(name)
+		require.NoError(t, err)
+		require.Equal(t, "homefeed.food_v3", id)
+	}
+	_, err := ResolveFeedChannel("不存在")
+	require.Error(t, err)
+
+	require.Equal(t, "https://www.xiaohongshu.com/explore", feedsChannelURL(DefaultFeedChannel))
+	require.Equal(t, "https://www.xiaohongshu.com/explore?channel_id=homefeed.fashion_v3", feedsChannelURL("homefeed.fashion_v3"))
+}
+
+func TestMergeFeedsDedupesByID(t *testing.T) {
+	seen := map[string]bool{}
+	all, added := mergeFeeds(nil, seen, []Feed{{ID: "a"}, {ID: "b"}, {ID: "a"}, {ID: ""}})
+	require.Equal(t, 2, added)
+
+	// 滚动后页面状态包含之前已加载的内容，只追加新的
+	all, added = mergeFeeds(all, seen, []Feed{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	require.Equal(t, 1, added)
+	require.Equal(t, []string{"a", "b", "c"}, []string{all[0].ID, all[1].ID, all[2].ID})
+}
+
+func TestFeedsCursorRoundTrip(t *testing.T) {
+	want := feedsCursor{Channel: "homefeed.food_v3", Session: "abc", Offset: 40}
+	c, err := decodeFeedsCursor(encodeFeedsCursor(want))
+	require.NoError(t, err)
+	require.Equal(t, want, c)
+
+	_, err = decodeFeedsCursor("not a cursor!")
+	require.Error(t, err)
+	_, err = decodeFeedsCursor(encodeFeedsCursor(feedsCursor{Channel: "homefeed.food_v3", Session: "abc", Offset: -1}))
+	require.Error(t, err)
+}
+
+func TestListFeedsPagesFromSession(t *testing.T) {
+	id, sess := newFeedsSession("homefeed.food_v3", "alice")
+	sess.feeds, _ = mergeFeeds(nil, sess.seen, []Feed{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}})
+
+	// 会话中已有足够内容时直接分页，不需要打开页面
+	f := &FeedsListAction{page: &rod.Page{}}
+	cursor := encodeFeedsCursor(feedsCursor{Channel: "homefeed.food_v3", Session: id, Offset: 1})
+	p, err := f.ListFeeds(context.Background(), FeedsListOptions{Count: 2, Cursor: cursor, Account: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, []string{p.Feeds[0].ID, p.Feeds[1].ID})
+	require.True(t, p.HasMore)
+
+	next, err := decodeFeedsCursor(p.NextCursor)
+	require.NoError(t, err)
+	require.Equal(t, feedsCursor{Channel: "homefeed.food_v3", Session: id, Offset: 3}, next)
+
+	// 同一游标重复请求返回同一页
+	again, err := f.ListFeeds(context.Background(), FeedsListOptions{Count: 2, Cursor: cursor, Account: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, p.Feeds, again.Feeds)
+
+	_, err = f.ListFeeds(context.Background(), FeedsListOptions{Count: 2, Cursor: cursor, Account: "bob"})
+	require.Error(t, err)
+}
+
+func TestListFeedsRejectsInvalidArgs(t *testing.T) {
+	f := &FeedsListAction{page: &rod.Page{}}
+	ctx := context.Background()
+
+	_, err := f.ListFeeds(ctx, FeedsListOptions{Count: -1})
+	require.Error(t, err)
+
+	id, _ := newFeedsSession(DefaultFeedChannel, "")
+	_, err = f.ListFeeds(ctx, FeedsListOptions{Count: 1, Cursor: encodeFeedsCursor(feedsCursor{Channel: DefaultFeedChannel, Session: id, Offset: 5})})
+	require.Error(t, err)
+
+	_, err = f.ListFeeds(ctx, FeedsListOptions{Count: 1, Cursor: encodeFeedsCursor(feedsCursor{Channel: DefaultFeedChannel, Session: "missing"})})
+	require.ErrorIs(t, err, errors.ErrFeedsCursorExpired)
+}
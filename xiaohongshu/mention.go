@@ -0,0 +1,162 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// @ 联想弹层：创作者中心编辑器与笔记详情页评论框使用不同的容器，只在 @ 专用的弹层中查找，
+// 避免选中话题联想等其他弹层里的内容
+var mentionPopupSelectors = []string{
+	"#creator-editor-mention-container",
+	".mention-container",
+	"[class*='mention'][class*='list']",
+}
+
+// mentionItemSelector 联想弹层中的用户选项
+const mentionItemSelector = ".item, [class*='mention-item'], [class*='user-item']"
+
+// mentionNodeSelector 选中用户后编辑器中插入的 @ 节点
+const mentionNodeSelector = "[class*='mention'], [data-type='mention'], a[href*='/user/profile']"
+
+// normalizeMentions 去掉空值、前导 @ 与重复
+func normalizeMentions(mentions []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		m = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(m), "@＠"))
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// inputMentions 在输入框当前光标处依次输入 @昵称 并从联想弹层选中对应用户；
+// 无法选中时保留为普通文本，返回提示信息
+func inputMentions(elem *rod.Element, mentions []string) ([]string, error) {
+	var warnings []string
+	for _, name := range normalizeMentions(mentions) {
+		ok, err := inputMention(elem, name)
+		if err != nil {
+			return warnings, errors.Wrapf(err, "输入@%s失败", name)
+		}
+		if !ok {
+			w := fmt.Sprintf("未能在联想列表中找到 @%s，已按普通文本输入", name)
+			logrus.Warn(w)
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings, nil
+}
+
+// inputMention 输入一个 @昵称，选中联想列表中昵称一致的用户则返回 true
+func inputMention(elem *rod.Element, name string) (bool, error) {
+	if err := elem.Input(" @"); err != nil {
+		return false, err
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	for _, char := range name {
+		if err := elem.Input(string(char)); err != nil {
+			return false, errors.Wrapf(err, "输入字符[%c]失败", char)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// 等待联想结果加载
+	time.Sleep(1500 * time.Millisecond)
+
+	item := findMentionItem(elem.Page(), name)
+	if item == nil {
+		return false, elem.Input(" ")
+	}
+	before := countInsertedNodes(elem, mentionNodeSelector, name)
+	if err := item.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return false, errors.Wrap(err, "点击@联想选项失败")
+	}
+	time.Sleep(500 * time.Millisecond)
+	// 以编辑器中实际插入的 @ 节点为准，点击没有生效时按普通文本处理
+	if countInsertedNodes(elem, mentionNodeSelector, name) <= before {
+		logrus.Warnf("点击@%s后编辑器中没有出现对应的@节点", name)
+		return false, nil
+	}
+	logrus.Infof("成功选择@用户: %s", name)
+	return true, nil
+}
+
+// countInsertedNodes 统计输入框中匹配 sel 且文本（去掉前导 @/# 与结尾的 [话题] 标记）为 name 的节点数量，
+// 用于确认联想选项点击后编辑器确实插入了对应的 @ 或话题节点
+func countInsertedNodes(elem *rod.Element, sel, name string) int {
+	res, err := elem.Eval(`(sel, name) => {
+		const norm = s => (s || '').trim().replace(/^[@＠#]+/, '').replace(/#?\[话题\]#?$/, '').replace(/#+$/, '').trim().toLowerCase();
+		let n = 0;
+		for (const node of this.querySelectorAll(sel)) {
+			if (norm(node.textContent) === norm(name)) n++;
+		}
+		return n;
+	}`, sel, name)
+	if err != nil {
+		return 0
+	}
+	return res.Value.Int()
+}
+
+// findMentionItem 在联想弹层中找到昵称或小红书号与 name 一致的选项，避免误选相似用户；
+// 有多个选项同时匹配时无法确定是谁，不做选择
+func findMentionItem(page *rod.Page, name string) *rod.Element {
+	for _, sel := range mentionPopupSelectors {
+		container, err := page.Timeout(500 * time.Millisecond).Element(sel)
+		if err != nil || container == nil || !isElementVisible(container) {
+			continue
+		}
+		items, err := container.Elements(mentionItemSelector)
+		if err != nil {
+			continue
+		}
+		var matched []*rod.Element
+		for _, it := range items {
+			text, err := it.Text()
+			if err != nil {
+				continue
+			}
+			if mentionMatches(text, name) {
+				matched = append(matched, it)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			continue
+		case 1:
+			return matched[0]
+		default:
+			logrus.Warnf("联想列表中有 %d 个用户与 @%s 一致，无法确定选哪一个", len(matched), name)
+			return nil
+		}
+	}
+	return nil
+}
+
+// mentionMatches 选项文本（可能包含昵称、小红书号、粉丝数等多行）中是否有一行昵称或小红书号与 name 一致
+func mentionMatches(itemText, name string) bool {
+	for _, line := range strings.Split(itemText, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "@"))
+		if line == name {
+			return true
+		}
+		for _, prefix := range []string{"小红书号：", "小红书号:"} {
+			if id, ok := strings.CutPrefix(line, prefix); ok && strings.TrimSpace(id) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
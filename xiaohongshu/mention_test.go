@@ -0,0 +1,24 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMentions(t *testing.T) {
+	got := normalizeMentions([]string{"@小红薯", " 小红薯 ", "＠阿花", "", "@", "Bob"})
+	assert.Equal(t, []string{"小红薯", "阿花", "Bob"}, got)
+	assert.Empty(t, normalizeMentions(nil))
+}
+
+func TestMentionMatches(t *testing.T) {
+	assert.True(t, mentionMatches("小红薯\n小红书号：12345\n粉丝 1.2万", "小红薯"))
+	assert.True(t, mentionMatches("@阿花", "阿花"))
+	// 只包含昵称的相似用户不应被选中
+	assert.False(t, mentionMatches("小红薯的小号\n粉丝 12", "小红薯"))
+	assert.False(t, mentionMatches("", "小红薯"))
+	// 也可以按小红书号匹配，但粉丝数等其他数字不算
+	assert.True(t, mentionMatches("小红薯\n小红书号：12345\n粉丝 12", "12345"))
+	assert.False(t, mentionMatches("小红薯\n粉丝 12", "12"))
+}
@@ -0,0 +1,90 @@
+package xiaohongshu
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// capturedResponse 拦截到的接口响应
+type capturedResponse struct {
+	URL    string
+	Method string
+	Status int
+	Body   []byte
+}
+
+// responseWatcher 监听页面发出的接口请求，读取匹配请求的响应体。
+// 用于从页面自身的 XHR 中获取数据，避免自行构造带签名的请求。
+type responseWatcher struct {
+	ch     chan capturedResponse
+	cancel context.CancelFunc
+}
+
+// watchResponses 开始监听，match 根据请求方法与 URL 判断是否需要该响应。调用方负责 Stop。
+func watchResponses(page *rod.Page, match func(method, url string) bool) *responseWatcher {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	w := &responseWatcher{ch: make(chan capturedResponse, 16), cancel: cancel}
+
+	type pending struct {
+		method, url string
+		status      int
+	}
+	requests := map[proto.NetworkRequestID]*pending{}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if match(e.Request.Method, e.Request.URL) {
+				requests[e.RequestID] = &pending{method: e.Request.Method, url: e.Request.URL}
+			}
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if p, ok := requests[e.RequestID]; ok {
+				p.status = e.Response.Status
+			}
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			p, ok := requests[e.RequestID]
+			if !ok {
+				return
+			}
+			delete(requests, e.RequestID)
+			body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(page)
+			if err != nil {
+				return
+			}
+			data := []byte(body.Body)
+			if body.Base64Encoded {
+				return
+			}
+			select {
+			case w.ch <- capturedResponse{URL: p.url, Method: p.method, Status: p.status, Body: data}:
+			default:
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			delete(requests, e.RequestID)
+		},
+	)
+	go wait()
+
+	return w
+}
+
+// Next 等待下一个匹配的响应。
+func (w *responseWatcher) Next(timeout time.Duration) (capturedResponse, error) {
+	select {
+	case r := <-w.ch:
+		return r, nil
+	case <-time.After(timeout):
+		return capturedResponse{}, errors.Errorf("等待接口响应超时(%s)", timeout)
+	}
+}
+
+// Stop 停止监听。
+func (w *responseWatcher) Stop() {
+	w.cancel()
+}
@@ -0,0 +1,517 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	urlOfNoteManager = `https://creator.xiaohongshu.com/new/note-manager`
+	// 笔记管理页加载列表时调用的接口
+	apiOfPostedNotes = "/note/user/posted"
+)
+
+// ErrNoteNotFound 笔记管理列表中没有该笔记
+var ErrNoteNotFound = errors.New("未找到笔记")
+
+// 笔记状态
+const (
+	NoteStatusPublished = "published"
+	NoteStatusScheduled = "scheduled"
+	NoteStatusReviewing = "reviewing"
+	NoteStatusRejected  = "rejected"
+	NoteStatusUnknown   = "unknown"
+)
+
+// 笔记可见范围
+const (
+	NoteVisibilityPublic  = "public"
+	NoteVisibilityPrivate = "private"
+	NoteVisibilityFriends = "friends"
+)
+
+// ManagedNote 创作者中心笔记管理中的笔记
+type ManagedNote struct {
+	ID           string `json:"id"`
+	DisplayTitle string `json:"display_title"`
+	XsecToken    string `json:"xsec_token,omitempty"`
+	Type         string `json:"type"`
+	Time         string `json:"time,omitempty"`
+	URL          string `json:"url,omitempty"`
+
+	Status     string `json:"status"`
+	Visibility string `json:"visibility"`
+	// PermissionMsg 页面展示的可见范围/审核说明原文
+	PermissionMsg string `json:"permission_msg,omitempty"`
+	ScheduleTime  string `json:"schedule_time,omitempty"`
+
+	ViewCount    int `json:"view_count"`
+	LikeCount    int `json:"like_count"`
+	CommentCount int `json:"comment_count"`
+	CollectCount int `json:"collect_count"`
+	ShareCount   int `json:"share_count"`
+}
+
+// postedNote 笔记列表接口中的原始字段
+type postedNote struct {
+	ID               string `json:"id"`
+	DisplayTitle     string `json:"display_title"`
+	XsecToken        string `json:"xsec_token"`
+	Type             string `json:"type"`
+	Time             string `json:"time"`
+	TabStatus        int    `json:"tab_status"`
+	PermissionCode   int    `json:"permission_code"`
+	PermissionMsg    string `json:"permission_msg"`
+	SchedulePostTime int64  `json:"schedule_post_time"`
+	ViewCount        int    `json:"view_count"`
+	Likes            int    `json:"likes"`
+	CommentsCount    int    `json:"comments_count"`
+	CollectedCount   int    `json:"collected_count"`
+	SharedCount      int    `json:"shared_count"`
+}
+
+type postedNotesResponse struct {
+	Code    int  `json:"code"`
+	Success bool `json:"success"`
+	Data    struct {
+		Notes []postedNote `json:"notes"`
+		// Page 下一页页码，-1 表示没有更多
+		Page int `json:"page"`
+	} `json:"data"`
+}
+
+// NoteURL 笔记的访问链接，xsecToken 为空时返回不带 token 的链接
+func NoteURL(noteID, xsecToken string) string {
+	if noteID == "" {
+		return ""
+	}
+	u := "https://www.xiaohongshu.com/explore/" + url.PathEscape(noteID)
+	if xsecToken != "" {
+		u += "?xsec_token=" + url.QueryEscape(xsecToken) + "&xsec_source=pc_creatormng"
+	}
+	return u
+}
+
+func (n postedNote) toManaged(now time.Time) ManagedNote {
+	m := ManagedNote{
+		ID:            n.ID,
+		DisplayTitle:  n.DisplayTitle,
+		XsecToken:     n.XsecToken,
+		Type:          n.Type,
+		Time:          n.Time,
+		URL:           NoteURL(n.ID, n.XsecToken),
+		Status:        noteStatus(n, now),
+		Visibility:    noteVisibility(n.PermissionCode),
+		PermissionMsg: n.PermissionMsg,
+		ViewCount:     n.ViewCount,
+		LikeCount:     n.Likes,
+		CommentCount:  n.CommentsCount,
+		CollectCount:  n.CollectedCount,
+		ShareCount:    n.SharedCount,
+	}
+	if n.SchedulePostTime > 0 {
+		m.ScheduleTime = time.UnixMilli(n.SchedulePostTime).Format("2006-01-02 15:04")
+	}
+	return m
+}
+
+// noteStatus 未到发布时间的定时笔记为 scheduled，其余按 tab_status 判断：
+// 1 已发布，2 审核中，3 未通过（与笔记管理页的 Tab 对应）
+func noteStatus(n postedNote, now time.Time) string {
+	if n.SchedulePostTime > 0 && time.UnixMilli(n.SchedulePostTime).After(now) {
+		return NoteStatusScheduled
+	}
+	switch n.TabStatus {
+	case 1:
+		return NoteStatusPublished
+	case 2:
+		return NoteStatusReviewing
+	case 3:
+		return NoteStatusRejected
+	}
+	if strings.Contains(n.PermissionMsg, "审核中") {
+		return NoteStatusReviewing
+	}
+	if strings.Contains(n.PermissionMsg, "未通过") || strings.Contains(n.PermissionMsg, "违规") {
+		return NoteStatusRejected
+	}
+	return NoteStatusUnknown
+}
+
+// noteVisibility permission_code: 0 公开，1 仅自己可见，4 仅互关好友可见
+func noteVisibility(code int) string {
+	switch code {
+	case 1:
+		return NoteVisibilityPrivate
+	case 4:
+		return NoteVisibilityFriends
+	default:
+		return NoteVisibilityPublic
+	}
+}
+
+func parsePostedNotes(body []byte) ([]ManagedNote, int, error) {
+	var r postedNotesResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, -1, errors.Wrap(err, "解析笔记列表失败")
+	}
+	if !r.Success && r.Code != 0 {
+		return nil, -1, errors.Errorf("笔记列表接口返回错误: code=%d", r.Code)
+	}
+	now := time.Now()
+	notes := make([]ManagedNote, 0, len(r.Data.Notes))
+	for _, n := range r.Data.Notes {
+		notes = append(notes, n.toManaged(now))
+	}
+	return notes, r.Data.Page, nil
+}
+
+// NoteManagerAction 创作者中心笔记管理
+type NoteManagerAction struct {
+	page *rod.Page
+}
+
+func NewNoteManagerAction(page *rod.Page) *NoteManagerAction {
+	return &NoteManagerAction{page: page}
+}
+
+// List 列出账号的笔记，status 为空时返回全部；limit<=0 时只读取第一页
+func (a *NoteManagerAction) List(ctx context.Context, status string, limit int) ([]ManagedNote, error) {
+	page := a.page.Context(ctx).Timeout(120 * time.Second)
+	notes, err := listPostedNotes(page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		return notes, nil
+	}
+	out := make([]ManagedNote, 0, len(notes))
+	for _, n := range notes {
+		if n.Status == status {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// Get 按 ID 查找笔记，逐页加载直到找到或没有更多
+func (a *NoteManagerAction) Get(ctx context.Context, noteID string) (*ManagedNote, error) {
+	page := a.page.Context(ctx).Timeout(120 * time.Second)
+	notes, idx, err := findNoteInList(page, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if idx < 0 {
+		return nil, errors.Wrapf(ErrNoteNotFound, "%s", noteID)
+	}
+	return &notes[idx], nil
+}
+
+// Delete 在笔记管理页删除笔记，删除后重新读取列表确认
+func (a *NoteManagerAction) Delete(ctx context.Context, noteID string) error {
+	page := a.page.Context(ctx).Timeout(120 * time.Second)
+
+	notes, idx, err := findNoteInList(page, noteID)
+	if err != nil {
+		return err
+	}
+	if idx < 0 {
+		return errors.Wrapf(ErrNoteNotFound, "%s", noteID)
+	}
+
+	titles := make([]string, len(notes))
+	for i, n := range notes {
+		titles[i] = n.DisplayTitle
+	}
+	err = confirmOpenedDialog(page, func() error {
+		return clickCardAction(page, "删除", newCardTarget(noteID, idx, titles))
+	})
+	if err != nil {
+		return err
+	}
+	time.Sleep(2 * time.Second)
+
+	_, idx, err = findNoteInList(page, noteID)
+	if err != nil {
+		return errors.Wrap(err, "删除后确认笔记列表失败")
+	}
+	if idx >= 0 {
+		return errors.Errorf("删除后笔记仍在列表中: %s", noteID)
+	}
+	logrus.Infof("笔记已删除: %s", noteID)
+	return nil
+}
+
+// findNoteInList 逐页加载笔记列表直到出现 noteID 或没有更多，返回已加载的列表与笔记位置，未找到时位置为 -1
+func findNoteInList(page *rod.Page, noteID string) ([]ManagedNote, int, error) {
+	idx := -1
+	notes, err := loadPostedNotes(page, func(all []ManagedNote) bool {
+		for i := range all {
+			if all[i].ID == noteID {
+				idx = i
+				return true
+			}
+		}
+		return false
+	})
+	return notes, idx, err
+}
+
+// fetchPostedNotes 读取笔记管理页第一页
+func fetchPostedNotes(page *rod.Page) ([]ManagedNote, error) {
+	return listPostedNotes(page, 0)
+}
+
+// listPostedNotes 打开笔记管理页读取列表，滚动加载直到 limit 或没有更多
+func listPostedNotes(page *rod.Page, limit int) ([]ManagedNote, error) {
+	all, err := loadPostedNotes(page, func(all []ManagedNote) bool { return len(all) >= limit })
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// loadPostedNotes 打开笔记管理页逐页读取列表，done 返回 true 或没有更多时停止
+func loadPostedNotes(page *rod.Page, done func([]ManagedNote) bool) ([]ManagedNote, error) {
+	w := watchResponses(page, func(method, u string) bool {
+		return method == "GET" && strings.Contains(u, apiOfPostedNotes)
+	})
+	defer w.Stop()
+
+	if err := page.Navigate(urlOfNoteManager); err != nil {
+		return nil, errors.Wrap(err, "打开笔记管理页失败")
+	}
+
+	var all []ManagedNote
+	seen := map[string]bool{}
+	for {
+		resp, err := w.Next(20 * time.Second)
+		if err != nil {
+			if len(all) > 0 {
+				break
+			}
+			return nil, errors.Wrap(err, "读取笔记列表失败")
+		}
+		notes, next, err := parsePostedNotes(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if !seen[n.ID] {
+				seen[n.ID] = true
+				all = append(all, n)
+			}
+		}
+		if next < 0 || len(notes) == 0 || done(all) {
+			break
+		}
+		// 滚动到底部触发下一页
+		if _, err := page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			break
+		}
+	}
+	return all, nil
+}
+
+// cardTarget 要操作的卡片：ID 为笔记/草稿 ID，Index 为卡片在列表中的位置，
+// TitleUnique 表示标题在列表中唯一，只有此时才允许在页面上找不到 ID 时按标题定位
+type cardTarget struct {
+	ID          string `json:"id"`
+	Index       int    `json:"index"`
+	Title       string `json:"title"`
+	TitleUnique bool   `json:"titleUnique"`
+}
+
+// newCardTarget 根据列表中的标题计算 TitleUnique
+func newCardTarget(id string, idx int, titles []string) cardTarget {
+	t := cardTarget{ID: id, Index: idx, Title: strings.TrimSpace(titles[idx])}
+	if t.Title == "" {
+		return t
+	}
+	n := 0
+	for _, title := range titles {
+		if strings.TrimSpace(title) == t.Title {
+			n++
+		}
+	}
+	t.TitleUnique = n == 1
+	return t
+}
+
+// clickCardAction 点击目标卡片上文字为 label 的按钮（如“删除”“编辑”）。
+// 优先按卡片中链接/属性里的 ID 定位；页面不含 ID 时，仅当标题唯一、整行完全匹配且与列表位置一致时按标题定位。
+// 匹配到多张卡片或无法确认时返回错误，不会退而操作其他卡片。
+func clickCardAction(page *rod.Page, label string, target cardTarget) error {
+	res, err := page.Eval(`(label, t) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		const buttons = Array.from(document.querySelectorAll('span, div, button'))
+			.filter(el => el.children.length === 0 && (el.textContent || '').trim() === label && isVisible(el));
+		const countButtons = (el) => buttons.filter(b => el.contains(b)).length;
+		// 卡片为只包含这一个按钮的最大祖先节点
+		const cards = buttons.map(btn => {
+			let card = btn;
+			while (card.parentElement && card.parentElement !== document.body && countButtons(card.parentElement) === 1) {
+				card = card.parentElement;
+			}
+			return { btn, card };
+		});
+		const attrsOf = (el) => [el, ...el.querySelectorAll('*')]
+			.flatMap(n => Array.from(n.attributes || []).map(a => a.value)).join(' ');
+		const hasTitleLine = (el) => (el.innerText || '').split('\n').some(l => l.trim() === t.title);
+
+		let matched = t.id ? cards.filter(c => attrsOf(c.card).includes(t.id)) : [];
+		let by = 'id';
+		if (matched.length === 0 && t.titleUnique && t.title) {
+			matched = cards.filter(c => hasTitleLine(c.card));
+			by = 'title';
+			if (matched.length === 1 && cards[t.index] !== matched[0]) return 'mismatch';
+		}
+		if (matched.length > 1) return 'ambiguous:' + matched.length;
+		if (matched.length === 0) return 'not_found';
+		matched[0].btn.scrollIntoView({ block: 'center' });
+		matched[0].btn.click();
+		return 'ok:' + by;
+	}`, label, target)
+	if err != nil {
+		return errors.Wrapf(err, "点击%s按钮失败", label)
+	}
+	switch r := res.Value.String(); {
+	case strings.HasPrefix(r, "ok:"):
+		logrus.Debugf("按 %s 定位卡片并点击%s: id=%s", strings.TrimPrefix(r, "ok:"), label, target.ID)
+		return nil
+	case strings.HasPrefix(r, "ambiguous:"):
+		return errors.Errorf("有 %s 张卡片与 %s 匹配，为避免误操作已中止", strings.TrimPrefix(r, "ambiguous:"), target.ID)
+	case r == "mismatch":
+		return errors.Errorf("按标题找到的卡片与列表位置不一致，为避免误操作已中止: %s", target.ID)
+	default:
+		if !target.TitleUnique {
+			return errors.Errorf("页面上没有找到 %s 的卡片，且标题不唯一，无法安全定位", target.ID)
+		}
+		return errors.Errorf("没有找到 %s 卡片上的%s按钮", target.ID, label)
+	}
+}
+
+// dialogSelector 确认弹窗（模态框与气泡确认框）
+const dialogSelector = `.d-modal, [role="dialog"], .el-dialog, .d-popconfirm, .d-popover`
+
+// confirmOpenedDialog 执行 open（如点击删除按钮）后，只在由它新弹出的弹窗中点击确认按钮。
+// 没有新弹窗、同时弹出多个或弹窗中没有确认按钮时返回错误，不会点击页面上的其他按钮
+func confirmOpenedDialog(page *rod.Page, open func() error) error {
+	// 标记操作前已经可见的弹窗
+	if _, err := page.Eval(`(sel) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		document.querySelectorAll(sel).forEach(el => {
+			if (isVisible(el)) el.setAttribute('data-xhs-mcp-seen', '1');
+			else el.removeAttribute('data-xhs-mcp-seen');
+		});
+	}`, dialogSelector); err != nil {
+		return errors.Wrap(err, "读取弹窗状态失败")
+	}
+	if err := open(); err != nil {
+		return err
+	}
+	time.Sleep(800 * time.Millisecond)
+
+	res, err := page.Eval(`(sel) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		// 嵌套的弹窗节点只保留最外层
+		const opened = Array.from(document.querySelectorAll(sel))
+			.filter(el => isVisible(el) && !el.hasAttribute('data-xhs-mcp-seen'))
+			.filter((el, _, all) => !all.some(o => o !== el && o.contains(el)));
+		if (opened.length === 0) return 'no_dialog';
+		if (opened.length > 1) return 'ambiguous:' + opened.length;
+		const btns = Array.from(opened[0].querySelectorAll('button, .d-button, span'))
+			.filter(el => isVisible(el) && ['确定', '确认', '删除', '确认删除'].includes((el.textContent || '').trim()));
+		if (btns.length === 0) return 'not_found';
+		btns[btns.length - 1].click();
+		return 'ok';
+	}`, dialogSelector)
+	if err != nil {
+		return errors.Wrap(err, "确认删除失败")
+	}
+	switch r := res.Value.String(); {
+	case r == "ok":
+		return nil
+	case r == "no_dialog":
+		return errors.New("点击删除后没有弹出确认框，已中止")
+	case strings.HasPrefix(r, "ambiguous:"):
+		return errors.Errorf("同时出现 %s 个弹窗，无法确定删除确认框，已中止", strings.TrimPrefix(r, "ambiguous:"))
+	default:
+		return errors.New("删除确认框中没有找到确认按钮")
+	}
+}
+
+// findPostedNote 在笔记管理列表中查找刚发布的笔记：优先按 ID；
+// 没有 ID 时按标题匹配 since 之后发布的笔记，匹配到多条时返回错误而不是猜测
+func findPostedNote(page *rod.Page, noteID, title string, since time.Time) (*ManagedNote, error) {
+	notes, err := fetchPostedNotes(page)
+	if err != nil {
+		return nil, err
+	}
+	return matchPostedNote(notes, noteID, title, since)
+}
+
+// 笔记管理列表中的时间为北京时间，精确到分钟
+var (
+	noteTimeZone    = time.FixedZone("CST", 8*3600)
+	noteTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006年01月02日 15:04"}
+)
+
+func parseNoteTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range noteTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, noteTimeZone); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func matchPostedNote(notes []ManagedNote, noteID, title string, since time.Time) (*ManagedNote, error) {
+	if noteID != "" {
+		for i := range notes {
+			if notes[i].ID == noteID {
+				return &notes[i], nil
+			}
+		}
+		logrus.Debugf("笔记管理列表中未找到笔记: id=%s (共 %d 条)", noteID, len(notes))
+		return nil, errors.New("笔记管理列表中未找到刚发布的笔记")
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("缺少笔记 ID 与标题，无法定位刚发布的笔记")
+	}
+	// 列表时间只精确到分钟，起点按分钟取整
+	since = since.Truncate(time.Minute)
+	var matched []*ManagedNote
+	for i := range notes {
+		if strings.TrimSpace(notes[i].DisplayTitle) != title {
+			continue
+		}
+		// 无法确认发布时间的笔记可能是之前发布的同名笔记，不参与匹配
+		if t, ok := parseNoteTime(notes[i].Time); !ok || t.Before(since) {
+			continue
+		}
+		matched = append(matched, &notes[i])
+	}
+	switch len(matched) {
+	case 1:
+		return matched[0], nil
+	case 0:
+		logrus.Debugf("笔记管理列表中未找到笔记: title=%s since=%s (共 %d 条)", title, since.Format(time.DateTime), len(notes))
+		return nil, errors.New("笔记管理列表中未找到刚发布的笔记")
+	default:
+		return nil, errors.Errorf("发布后有 %d 条同名笔记，无法确定刚发布的是哪一条", len(matched))
+	}
+}
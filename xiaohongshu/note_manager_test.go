@@ -0,0 +1,54 @@
+package xiaohongshu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteStatusAndVisibility(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	future := now.Add(time.Hour).UnixMilli()
+	past := now.Add(-time.Hour).UnixMilli()
+
+	cases := []struct {
+		note postedNote
+		want string
+	}{
+		{postedNote{TabStatus: 1}, NoteStatusPublished},
+		{postedNote{TabStatus: 2}, NoteStatusReviewing},
+		{postedNote{TabStatus: 3}, NoteStatusRejected},
+		{postedNote{TabStatus: 1, SchedulePostTime: future}, NoteStatusScheduled},
+		{postedNote{TabStatus: 1, SchedulePostTime: past}, NoteStatusPublished},
+		{postedNote{PermissionMsg: "审核中"}, NoteStatusReviewing},
+		{postedNote{}, NoteStatusUnknown},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, noteStatus(c.note, now))
+	}
+
+	assert.Equal(t, NoteVisibilityPublic, noteVisibility(0))
+	assert.Equal(t, NoteVisibilityPrivate, noteVisibility(1))
+	assert.Equal(t, NoteVisibilityFriends, noteVisibility(4))
+}
+
+func TestPostedNoteToManaged(t *testing.T) {
+	n := postedNote{ID: "n1", XsecToken: "tok", TabStatus: 1, Likes: 3, CommentsCount: 2, ViewCount: 10}
+	m := n.toManaged(time.Now())
+	assert.Equal(t, NoteURL("n1", "tok"), m.URL)
+	assert.Equal(t, 3, m.LikeCount)
+	assert.Equal(t, 2, m.CommentCount)
+	assert.Equal(t, 10, m.ViewCount)
+}
+
+func TestNewCardTarget(t *testing.T) {
+	titles := []string{"周末探店", "新品测评", " 周末探店 ", ""}
+
+	tg := newCardTarget("n2", 1, titles)
+	assert.Equal(t, cardTarget{ID: "n2", Index: 1, Title: "新品测评", TitleUnique: true}, tg)
+
+	// 同名卡片只能按 ID 定位
+	assert.False(t, newCardTarget("n1", 0, titles).TitleUnique)
+	assert.False(t, newCardTarget("n4", 3, titles).TitleUnique)
+}
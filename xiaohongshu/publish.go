@@ -21,9 +21,13 @@ type PublishImageContent struct {
 	Title        string
 	Content      string
 	Tags         []string
+	Mentions     []string // 正文末尾 @ 的用户昵称
+	ExactTags    bool     // 只关联名称与标签完全一致的话题
 	ImagePaths   []string
 	Location     string
 	ScheduleTime *time.Time // 定时发布时间，nil 表示立即发布
+	Draft        bool       // 填写完成后存入草稿箱，不发布
+	Options      PublishOptions
 }
 
 type PublishAction struct {
@@ -67,19 +71,20 @@ func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 	}, nil
 }
 
-func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) error {
+// Publish 上传图片并提交，成功后返回新笔记的 ID、链接与 xsec_token
+func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) (*PublishResult, error) {
 	if len(content.ImagePaths) == 0 {
-		return errors.New("图片不能为空")
+		return nil, errors.New("图片不能为空")
 	}
 
 	page := p.page.Context(ctx)
 
 	uploadedCount, err := uploadImages(page, content.ImagePaths)
 	if err != nil {
-		return errors.Wrap(err, "小红书上传图片失败")
+		return nil, errors.Wrap(err, "小红书上传图片失败")
 	}
 	if err := waitForUploadComplete(page, uploadedCount); err != nil {
-		return errors.Wrap(err, "小红书上传图片未完成")
+		return nil, errors.Wrap(err, "小红书上传图片未完成")
 	}
 
 	tags := content.Tags
@@ -90,11 +95,31 @@ func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent
 
 	logrus.Infof("发布内容: title=%s, images=%v, tags=%v, location=%s, schedule=%v", content.Title, len(content.ImagePaths), tags, content.Location, content.ScheduleTime)
 
-	if err := submitPublish(page, content.Title, content.Content, tags, content.Location, content.ScheduleTime); err != nil {
-		return errors.Wrap(err, "小红书发布失败")
+	if content.Draft {
+		filled, err := fillPublishForm(page, content.Title, content.Content, tags, content.Mentions, content.ExactTags, content.Location, nil, content.Options)
+		if err != nil {
+			return nil, errors.Wrap(err, "小红书填写笔记失败")
+		}
+		result, err := saveDraft(page, content.Title)
+		if err != nil {
+			return nil, err
+		}
+		filled.applyTo(result)
+		return result, nil
 	}
 
-	return nil
+	w := watchResponses(page, isPublishAPI)
+	defer w.Stop()
+	started := time.Now()
+
+	filled, err := submitPublish(page, content.Title, content.Content, tags, content.Mentions, content.ExactTags, content.Location, content.ScheduleTime, content.Options)
+	if err != nil {
+		return nil, errors.Wrap(err, "小红书发布失败")
+	}
+
+	result := collectPublishResult(page, w, content.Title, started)
+	filled.applyTo(result)
+	return result, nil
 }
 
 func removePopCover(page *rod.Page) {
@@ -503,23 +528,43 @@ func probePublishState(page *rod.Page) (publishCheckState, error) {
 	return st, nil
 }
 
-func submitPublish(page *rod.Page, title, content string, tags []string, location string, scheduleTime *time.Time) error {
+// submitPublish 填写表单并点击发布，返回实际关联的话题与提示
+func submitPublish(page *rod.Page, title, content string, tags, mentions []string, exactTags bool, location string, scheduleTime *time.Time, opts PublishOptions) (*editorInput, error) {
+	filled, err := fillPublishForm(page, title, content, tags, mentions, exactTags, location, scheduleTime, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	submitButton, err := waitForPublishButtonClickable(page)
+	if err != nil {
+		return nil, err
+	}
+	err = submitButton.Click(proto.InputMouseButtonLeft, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "点击发布按钮失败")
+	}
+
+	return filled, waitForPublishResult(page)
+}
+
+// fillPublishForm 填写标题、正文、@用户、标签、定时发布与发布设置，不点击发布
+func fillPublishForm(page *rod.Page, title, content string, tags, mentions []string, exactTags bool, location string, scheduleTime *time.Time, opts PublishOptions) (*editorInput, error) {
 	_ = location
 
 	titleElem, err := page.Element("div.d-input input")
 	if err != nil {
-		return errors.Wrap(err, "查找标题输入框失败")
+		return nil, errors.Wrap(err, "查找标题输入框失败")
 	}
 	err = titleElem.Input(title)
 	if err != nil {
-		return errors.Wrap(err, "输入标题失败")
+		return nil, errors.Wrap(err, "输入标题失败")
 	}
 
 	// 检查标题长度
 	time.Sleep(500 * time.Millisecond)
 	err = checkTitleMaxLength(page)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	slog.Info("检查标题长度：通过")
 
@@ -527,23 +572,30 @@ func submitPublish(page *rod.Page, title, content string, tags []string, locatio
 
 	contentElem, ok := getContentElement(page)
 	if !ok {
-		return errors.New("没有找到内容输入框")
+		return nil, errors.New("没有找到内容输入框")
 	}
 	err = contentElem.Input(content)
 	if err != nil {
-		return errors.Wrap(err, "输入正文失败")
+		return nil, errors.Wrap(err, "输入正文失败")
 	}
-	err = inputTags(contentElem, tags)
+	filled := &editorInput{}
+	filled.Warnings, err = inputMentions(contentElem, mentions)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	topics, tagWarnings, err := inputTags(contentElem, tags, exactTags)
+	if err != nil {
+		return nil, err
 	}
+	filled.Topics = topics
+	filled.Warnings = append(filled.Warnings, tagWarnings...)
 
 	time.Sleep(1 * time.Second)
 
 	// 检查正文长度
 	err = checkContentMaxLength(page)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	slog.Info("检查正文长度：通过")
 
@@ -555,21 +607,12 @@ func submitPublish(page *rod.Page, title, content string, tags []string, locatio
 	if scheduleTime != nil {
 		err = setSchedulePublish(page, *scheduleTime)
 		if err != nil {
-			return errors.Wrap(err, "设置定时发布失败")
+			return nil, errors.Wrap(err, "设置定时发布失败")
 		}
 		slog.Info("定时发布设置完成", "schedule_time", scheduleTime.Format("2006-01-02 15:04"))
 	}
 
-	submitButton, err := waitForPublishButtonClickable(page)
-	if err != nil {
-		return err
-	}
-	err = submitButton.Click(proto.InputMouseButtonLeft, 1)
-	if err != nil {
-		return errors.Wrap(err, "点击发布按钮失败")
-	}
-
-	return waitForPublishResult(page)
+	return filled, applyPublishOptions(page, opts)
 }
 
 func findPublishButton(page *rod.Page) (*rod.Element, error) {
@@ -613,8 +656,6 @@ func setPublishLocation(page *rod.Page, location string) error {
 	return nil
 }
 
-var _ = setPublishLocation
-
 func openLocationPanel(page *rod.Page) error {
 	inputs, _ := page.Elements("input[placeholder*='地点']")
 	for _, in := range inputs {
@@ -841,9 +882,21 @@ func getContentElement(page *rod.Page) (*rod.Element, bool) {
 	return nil, false
 }
 
-func inputTags(contentElem *rod.Element, tags []string) error {
+// editorInput 填写正文的结果：实际关联的话题，以及 @ 用户、标签未按预期处理的提示
+type editorInput struct {
+	Topics   []string
+	Warnings []string
+}
+
+func (e *editorInput) applyTo(res *PublishResult) {
+	res.Topics = e.Topics
+	res.Warnings = e.Warnings
+}
+
+// inputTags 在正文末尾逐个输入标签，返回实际关联的话题与提示
+func inputTags(contentElem *rod.Element, tags []string, exact bool) (topics, warnings []string, err error) {
 	if len(tags) == 0 {
-		return nil
+		return nil, nil, nil
 	}
 
 	time.Sleep(1 * time.Second)
@@ -851,69 +904,38 @@ func inputTags(contentElem *rod.Element, tags []string) error {
 	for range 20 {
 		ka, err := contentElem.KeyActions()
 		if err != nil {
-			return errors.Wrap(err, "创建键盘操作失败")
+			return nil, nil, errors.Wrap(err, "创建键盘操作失败")
 		}
 		if err := ka.Type(input.ArrowDown).Do(); err != nil {
-			return errors.Wrap(err, "按下方向键失败")
+			return nil, nil, errors.Wrap(err, "按下方向键失败")
 		}
 		time.Sleep(10 * time.Millisecond)
 	}
 
 	ka, err := contentElem.KeyActions()
 	if err != nil {
-		return errors.Wrap(err, "创建键盘操作失败")
+		return nil, nil, errors.Wrap(err, "创建键盘操作失败")
 	}
 	if err := ka.Press(input.Enter).Press(input.Enter).Do(); err != nil {
-		return errors.Wrap(err, "按下回车键失败")
+		return nil, nil, errors.Wrap(err, "按下回车键失败")
 	}
 
 	time.Sleep(1 * time.Second)
 
 	for _, tag := range tags {
 		tag = strings.TrimLeft(tag, "#")
-		if err := inputTag(contentElem, tag); err != nil {
-			return errors.Wrapf(err, "输入标签[%s]失败", tag)
+		topic, err := inputTag(contentElem, tag, exact)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "输入标签[%s]失败", tag)
 		}
-	}
-	return nil
-}
-
-func inputTag(contentElem *rod.Element, tag string) error {
-	if err := contentElem.Input("#"); err != nil {
-		return errors.Wrap(err, "输入#失败")
-	}
-	time.Sleep(200 * time.Millisecond)
-
-	for _, char := range tag {
-		if err := contentElem.Input(string(char)); err != nil {
-			return errors.Wrapf(err, "输入字符[%c]失败", char)
+		if topic != "" {
+			topics = append(topics, topic)
+		}
+		if w := tagWarning(tag, topic); w != "" {
+			warnings = append(warnings, w)
 		}
-		time.Sleep(50 * time.Millisecond)
-	}
-
-	time.Sleep(1 * time.Second)
-
-	page := contentElem.Page()
-	topicContainer, err := page.Element("#creator-editor-topic-container")
-	if err != nil || topicContainer == nil {
-		slog.Warn("未找到标签联想下拉框，直接输入空格", "tag", tag)
-		return contentElem.Input(" ")
-	}
-
-	firstItem, err := topicContainer.Element(".item")
-	if err != nil || firstItem == nil {
-		slog.Warn("未找到标签联想选项，直接输入空格", "tag", tag)
-		return contentElem.Input(" ")
-	}
-
-	if err := firstItem.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return errors.Wrap(err, "点击标签联想选项失败")
 	}
-	slog.Info("成功点击标签联想选项", "tag", tag)
-	time.Sleep(200 * time.Millisecond)
-
-	time.Sleep(500 * time.Millisecond) // 等待标签处理完成
-	return nil
+	return topics, warnings, nil
 }
 
 func findTextboxByPlaceholder(page *rod.Page) (*rod.Element, error) {
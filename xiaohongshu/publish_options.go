@@ -0,0 +1,278 @@
+package xiaohongshu
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// 可见范围
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+	VisibilityFriends = "friends"
+)
+
+// 内容类型声明
+const (
+	DisclosureAIGenerated = "ai_generated"
+	DisclosureFiction     = "fiction"
+	DisclosureSelfShot    = "self_shot"
+	DisclosureReposted    = "reposted"
+)
+
+// 编辑器中可见范围选项的文案
+var visibilityLabels = map[string][]string{
+	VisibilityPublic:  {"公开可见", "公开"},
+	VisibilityPrivate: {"仅自己可见", "私密"},
+	VisibilityFriends: {"仅互关好友可见", "好友可见"},
+}
+
+// 编辑器中内容类型声明选项的文案
+var disclosureLabels = map[string][]string{
+	DisclosureAIGenerated: {"笔记含AI合成内容", "AI合成内容", "含AI生成内容"},
+	DisclosureFiction:     {"虚构演绎，仅供娱乐", "虚构演绎"},
+	DisclosureSelfShot:    {"自主拍摄"},
+	DisclosureReposted:    {"来源转载", "转载"},
+}
+
+// PublishOptions 发布设置，零值表示保持编辑器默认（公开、不声明）
+type PublishOptions struct {
+	// Visibility 可见范围：public/private/friends，为空时公开
+	Visibility string `json:"visibility,omitempty"`
+	// Original 声明原创
+	Original bool `json:"original,omitempty"`
+	// Disclosure 内容类型声明：ai_generated/fiction/self_shot/reposted
+	Disclosure string `json:"disclosure,omitempty"`
+	// AllowCoCreate 允许其他用户共创（合拍），为空时保持账号默认
+	AllowCoCreate *bool `json:"allow_co_create,omitempty"`
+	// Collection 加入的合集名称，合集需已在创作者中心创建
+	Collection string `json:"collection,omitempty"`
+}
+
+// Validate 校验发布设置的取值
+func (o PublishOptions) Validate() error {
+	if o.Visibility != "" {
+		if _, ok := visibilityLabels[o.Visibility]; !ok {
+			return errors.Errorf("不支持的可见范围: %s（可选 public/private/friends）", o.Visibility)
+		}
+	}
+	if o.Disclosure != "" {
+		if _, ok := disclosureLabels[o.Disclosure]; !ok {
+			return errors.Errorf("不支持的内容类型声明: %s（可选 ai_generated/fiction/self_shot/reposted）", o.Disclosure)
+		}
+	}
+	if o.Original && o.Disclosure == DisclosureReposted {
+		return errors.New("转载内容不能声明原创")
+	}
+	if n := len([]rune(strings.TrimSpace(o.Collection))); n > 30 {
+		return errors.Errorf("合集名称过长: %d/30", n)
+	}
+	return nil
+}
+
+// isZero 是否全部保持默认
+func (o PublishOptions) isZero() bool {
+	return o == PublishOptions{}
+}
+
+// applyPublishOptions 在编辑器中设置发布选项，需在点击发布前调用
+func applyPublishOptions(page *rod.Page, o PublishOptions) error {
+	if o.isZero() {
+		return nil
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	if o.Original {
+		if err := setOriginalDeclaration(page); err != nil {
+			return errors.Wrap(err, "声明原创失败")
+		}
+	}
+	if o.Disclosure != "" {
+		// 内容类型声明（尤其是 AI 合成内容标识）是合规要求，选择后必须确认页面上已显示该声明
+		want, others := disclosureLabels[o.Disclosure], otherLabels(disclosureLabels, o.Disclosure)
+		if err := verifySelected(page, want, others); err != nil {
+			triggers := append([]string{"添加内容类型声明", "内容类型声明"}, firstLabels(disclosureLabels)...)
+			if err := selectOption(page, triggers, want); err != nil {
+				return errors.Wrap(err, "设置内容类型声明失败")
+			}
+			if err := verifySelected(page, want, others); err != nil {
+				return errors.Wrap(err, "内容类型声明未生效")
+			}
+		}
+	}
+	if o.AllowCoCreate != nil {
+		if err := setSwitch(page, []string{"允许共创", "允许合拍"}, *o.AllowCoCreate); err != nil {
+			return errors.Wrap(err, "设置共创失败")
+		}
+	}
+	if c := strings.TrimSpace(o.Collection); c != "" {
+		if err := selectOption(page, []string{"添加到合集", "选择合集"}, []string{c}); err != nil {
+			return errors.Wrap(err, "加入合集失败")
+		}
+		if err := verifySelected(page, []string{c}, nil); err != nil {
+			return errors.Wrap(err, "加入合集未生效")
+		}
+	}
+	if o.Visibility != "" {
+		// 入口显示的是当前的可见范围，账号默认不是公开时同样需要切换
+		want, others := visibilityLabels[o.Visibility], otherLabels(visibilityLabels, o.Visibility)
+		if o.Visibility == VisibilityPublic {
+			// 编辑器默认公开，没有显示其他可见范围即可
+			want = nil
+		}
+		if err := verifySelected(page, want, others); err != nil {
+			want = visibilityLabels[o.Visibility]
+			triggers := append([]string{"权限设置", "谁可以看"}, firstLabels(visibilityLabels)...)
+			if err := selectOption(page, triggers, want); err != nil {
+				return errors.Wrap(err, "设置可见范围失败")
+			}
+			if err := verifySelected(page, want, others); err != nil {
+				return errors.Wrap(err, "可见范围未生效")
+			}
+		}
+	}
+
+	logrus.Infof("发布设置完成: %+v", o)
+	return nil
+}
+
+// selectOption 点击入口（文案与 triggers 之一完全一致）展开下拉/弹层后选择文案完全一致的选项
+func selectOption(page *rod.Page, triggers, options []string) error {
+	if err := clickByText(page, triggers, true); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	if err := clickByText(page, options, true); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
+
+// clickByText 点击文案匹配的最内层可见元素；exactOnly 为 false 时允许包含匹配
+func clickByText(page *rod.Page, texts []string, exactOnly bool) error {
+	res, err := page.Eval(`(texts, exactOnly) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		const nodes = Array.from(document.querySelectorAll('span, div, button, label, li, p'))
+			.filter(el => isVisible(el) && el.children.length <= 1);
+		const text = (el) => (el.textContent || '').trim();
+		let target = null;
+		for (const t of texts) {
+			target = nodes.find(el => text(el) === t);
+			if (!target && !exactOnly) target = nodes.find(el => text(el).includes(t) && text(el).length <= t.length + 8);
+			if (target) break;
+		}
+		if (!target) return 'not_found';
+		target.scrollIntoView({ block: 'center' });
+		target.click();
+		return 'ok';
+	}`, texts, exactOnly)
+	if err != nil {
+		return errors.Wrap(err, "点击元素失败")
+	}
+	if res.Value.String() != "ok" {
+		return errors.Errorf("没有找到: %s", strings.Join(texts, "/"))
+	}
+	return nil
+}
+
+// popupSelector 下拉框、弹层与弹窗，其中的文案是待选项而不是当前设置
+const popupSelector = `.d-popover, .d-dropdown, .d-select-dropdown, [role="listbox"], [role="menu"], .d-modal, [role="dialog"]`
+
+// verifySelected 确认设置已生效：弹层之外的页面上显示了 want 之一的文案（want 为空时不检查），且没有显示同组其他选项 others
+func verifySelected(page *rod.Page, want, others []string) error {
+	res, err := page.Eval(`(want, others, popup) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		const shown = new Set(Array.from(document.querySelectorAll('span, div, p, label'))
+			.filter(el => isVisible(el) && el.children.length === 0 && !el.closest(popup))
+			.map(el => (el.textContent || '').trim()));
+		return { found: want.some(t => shown.has(t)), others: others.filter(t => shown.has(t)) };
+	}`, append([]string{}, want...), append([]string{}, others...), popupSelector)
+	if err != nil {
+		return errors.Wrap(err, "读取设置状态失败")
+	}
+	if conflict := res.Value.Get("others").Arr(); len(conflict) > 0 {
+		return errors.Errorf("页面显示的是 %s", conflict[0].String())
+	}
+	if len(want) > 0 && !res.Value.Get("found").Bool() {
+		return errors.Errorf("页面上没有显示 %s", want[0])
+	}
+	return nil
+}
+
+// firstLabels 每个选项的完整文案
+func firstLabels(labels map[string][]string) []string {
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		out = append(out, l[0])
+	}
+	return out
+}
+
+// otherLabels 同组中除 key 以外各选项的完整文案
+func otherLabels(labels map[string][]string, key string) []string {
+	var out []string
+	for k, l := range labels {
+		if k != key {
+			out = append(out, l[0])
+		}
+	}
+	return out
+}
+
+// setSwitch 找到文案匹配 labels 的设置项，把同一行的开关切换到 on
+func setSwitch(page *rod.Page, labels []string, on bool) error {
+	res, err := page.Eval(`(labels, on) => {
+		const isVisible = (el) => { const r = el.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+		const label = Array.from(document.querySelectorAll('span, div, label, p'))
+			.filter(el => isVisible(el) && el.children.length === 0)
+			.find(el => labels.includes((el.textContent || '').trim()));
+		if (!label) return 'not_found';
+		let row = label.parentElement, sw = null;
+		for (let i = 0; i < 5 && row && !sw; i++, row = row.parentElement) {
+			sw = row.querySelector('.d-switch, [role="switch"], input[type="checkbox"]');
+		}
+		if (!sw) return 'no_switch';
+		const checked = sw.getAttribute('aria-checked') === 'true' || sw.checked === true ||
+			/checked|active|\bon\b/.test(sw.className || '');
+		if (checked !== on) sw.click();
+		return 'ok';
+	}`, labels, on)
+	if err != nil {
+		return errors.Wrap(err, "切换开关失败")
+	}
+	switch res.Value.String() {
+	case "ok":
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	case "no_switch":
+		return errors.Errorf("设置项没有开关: %s", strings.Join(labels, "/"))
+	default:
+		return errors.Errorf("没有找到设置项: %s", strings.Join(labels, "/"))
+	}
+}
+
+// setOriginalDeclaration 打开原创声明并在确认弹窗中勾选协议后确认
+func setOriginalDeclaration(page *rod.Page) error {
+	if err := setSwitch(page, []string{"原创声明", "声明原创"}, true); err != nil {
+		return err
+	}
+	time.Sleep(800 * time.Millisecond)
+
+	// 首次声明会弹出须知，需要勾选同意后再确认；未弹出时直接返回
+	if err := clickByText(page, []string{"我已阅读并同意", "我已阅读并同意《原创声明须知》"}, false); err != nil {
+		return nil
+	}
+	time.Sleep(300 * time.Millisecond)
+	if err := clickButtonByText(page, "声明原创", "确认", "确定"); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
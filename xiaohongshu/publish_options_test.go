@@ -0,0 +1,31 @@
+package xiaohongshu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishOptionsValidate(t *testing.T) {
+	allow := true
+	assert.NoError(t, PublishOptions{}.Validate())
+	assert.NoError(t, PublishOptions{
+		Visibility:    VisibilityFriends,
+		Original:      true,
+		Disclosure:    DisclosureAIGenerated,
+		AllowCoCreate: &allow,
+		Collection:    "周末探店",
+	}.Validate())
+
+	assert.Error(t, PublishOptions{Visibility: "everyone"}.Validate())
+	assert.Error(t, PublishOptions{Disclosure: "ai"}.Validate())
+	assert.Error(t, PublishOptions{Original: true, Disclosure: DisclosureReposted}.Validate())
+	assert.Error(t, PublishOptions{Collection: strings.Repeat("长", 31)}.Validate())
+}
+
+func TestOtherLabels(t *testing.T) {
+	others := otherLabels(visibilityLabels, VisibilityPrivate)
+	assert.ElementsMatch(t, []string{"公开可见", "仅互关好友可见"}, others)
+	assert.Len(t, firstLabels(disclosureLabels), len(disclosureLabels))
+}
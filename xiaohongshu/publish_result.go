@@ -0,0 +1,87 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// PublishResult 发布成功后的笔记信息，获取失败的字段为空
+type PublishResult struct {
+	NoteID    string `json:"note_id,omitempty"`
+	URL       string `json:"url,omitempty"`
+	XsecToken string `json:"xsec_token,omitempty"`
+	// DraftID 存入草稿箱时的草稿 ID，此时笔记尚未发布
+	DraftID string `json:"draft_id,omitempty"`
+	// Topics 实际关联的话题
+	Topics []string `json:"topics,omitempty"`
+	// Warnings 未影响发布的问题，例如 @ 用户未能解析而按普通文本输入
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// 发布笔记时创作者中心调用的接口
+var publishAPIPaths = []string{
+	"/web_api/sns/v2/note",
+	"/api/sns/v2/note",
+	"/api/galaxy/creator/note/publish",
+}
+
+func isPublishAPI(method, u string) bool {
+	if method != "POST" {
+		return false
+	}
+	for _, p := range publishAPIPaths {
+		if strings.Contains(u, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePublishNoteID 从发布接口响应中取出笔记 ID
+func parsePublishNoteID(body []byte) string {
+	var r map[string]any
+	if err := json.Unmarshal(body, &r); err != nil {
+		return ""
+	}
+	if data, ok := r["data"].(map[string]any); ok {
+		if id := pickNoteID(data); id != "" {
+			return id
+		}
+	}
+	return pickNoteID(r)
+}
+
+func pickNoteID(m map[string]any) string {
+	for _, k := range []string{"note_id", "noteId", "id"} {
+		if v, ok := m[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// collectPublishResult 点击发布后获取笔记 ID 与 xsec_token：
+// 先读取发布接口的响应，再到笔记管理列表中补全 token（接口响应缺失时按标题匹配 started 之后发布的笔记）。
+func collectPublishResult(page *rod.Page, w *responseWatcher, title string, started time.Time) *PublishResult {
+	res := &PublishResult{}
+	if resp, err := w.Next(5 * time.Second); err == nil {
+		res.NoteID = parsePublishNoteID(resp.Body)
+	} else {
+		logrus.Warnf("未捕获到发布接口响应: %v", err)
+	}
+
+	if note, err := findPostedNote(page, res.NoteID, title, started); err == nil {
+		res.NoteID = note.ID
+		res.XsecToken = note.XsecToken
+	} else {
+		logrus.Warnf("从笔记管理获取笔记信息失败: %v", err)
+	}
+
+	res.URL = NoteURL(res.NoteID, res.XsecToken)
+	logrus.Infof("发布结果: note_id=%s url=%s", res.NoteID, res.URL)
+	return res
+}
@@ -0,0 +1,70 @@
+package xiaohongshu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublishNoteID(t *testing.T) {
+	assert.Equal(t, "65f0c1a2000000001203abcd", parsePublishNoteID([]byte(`{"success":true,"data":{"id":"65f0c1a2000000001203abcd"}}`)))
+	assert.Equal(t, "abc", parsePublishNoteID([]byte(`{"success":true,"note_id":"abc"}`)))
+	assert.Empty(t, parsePublishNoteID([]byte(`{"success":false,"msg":"error"}`)))
+	assert.Empty(t, parsePublishNoteID([]byte(`not json`)))
+}
+
+func TestIsPublishAPI(t *testing.T) {
+	assert.True(t, isPublishAPI("POST", "https://edith.xiaohongshu.com/web_api/sns/v2/note"))
+	assert.False(t, isPublishAPI("GET", "https://edith.xiaohongshu.com/web_api/sns/v2/note"))
+	assert.False(t, isPublishAPI("POST", "https://edith.xiaohongshu.com/api/sns/web/v1/feed"))
+}
+
+func TestParsePostedNotes(t *testing.T) {
+	body := `{"code":0,"success":true,"data":{"notes":[{"id":"n1","display_title":"标题","xsec_token":"tok","type":"normal"}],"page":-1}}`
+	notes, next, err := parsePostedNotes([]byte(body))
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, -1, next)
+	assert.Equal(t, "tok", notes[0].XsecToken)
+
+	_, _, err = parsePostedNotes([]byte(`{"code":-100,"success":false}`))
+	require.Error(t, err)
+}
+
+func TestNoteURL(t *testing.T) {
+	assert.Empty(t, NoteURL("", "tok"))
+	assert.Equal(t, "https://www.xiaohongshu.com/explore/n1", NoteURL("n1", ""))
+	assert.Equal(t, "https://www.xiaohongshu.com/explore/n1?xsec_token=a%3Db&xsec_source=pc_creatormng", NoteURL("n1", "a=b"))
+}
+
+func TestMatchPostedNote(t *testing.T) {
+	started := time.Date(2025, 3, 1, 10, 30, 20, 0, noteTimeZone)
+	notes := []ManagedNote{
+		{ID: "new", DisplayTitle: "周末探店", Time: "2025-03-01 10:30"},
+		{ID: "old", DisplayTitle: "周末探店", Time: "2025-02-20 09:00"},
+		{ID: "other", DisplayTitle: "别的", Time: "2025-03-01 10:31"},
+	}
+
+	// 有 ID 时只按 ID 匹配
+	n, err := matchPostedNote(notes, "old", "周末探店", started)
+	require.NoError(t, err)
+	assert.Equal(t, "old", n.ID)
+	_, err = matchPostedNote(notes, "missing", "周末探店", started)
+	require.Error(t, err)
+
+	// 按标题只匹配发布开始之后的笔记，不会取到之前的同名笔记
+	n, err = matchPostedNote(notes, "", "周末探店", started)
+	require.NoError(t, err)
+	assert.Equal(t, "new", n.ID)
+	_, err = matchPostedNote(notes[1:], "", "周末探店", started)
+	require.Error(t, err)
+
+	// 无法确定时报错
+	dup := append(notes, ManagedNote{ID: "new2", DisplayTitle: "周末探店", Time: "2025-03-01 10:31"})
+	_, err = matchPostedNote(dup, "", "周末探店", started)
+	require.ErrorContains(t, err, "同名笔记")
+	_, err = matchPostedNote([]ManagedNote{{ID: "x", DisplayTitle: "周末探店"}}, "", "周末探店", started)
+	require.Error(t, err)
+}
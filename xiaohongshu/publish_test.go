@@ -26,12 +26,13 @@ func TestPublish(t *testing.T) {
 	action, err := NewPublishImageAction(page)
 	require.NoError(t, err)
 
-	err = action.Publish(context.Background(), PublishImageContent{
+	res, err := action.Publish(context.Background(), PublishImageContent{
 		Title:      "Hello World",
 		Content:    "Hello World",
 		ImagePaths: []string{"/tmp/1.jpg"},
 	})
 	assert.NoError(t, err)
+	assert.NotEmpty(t, res.NoteID)
 }
 
 func TestClassifyPublishMessage(t *testing.T) {
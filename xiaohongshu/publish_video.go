@@ -18,9 +18,16 @@ type PublishVideoContent struct {
 	Title        string
 	Content      string
 	Tags         []string
+	Mentions     []string // 正文末尾 @ 的用户昵称
+	ExactTags    bool     // 只关联名称与标签完全一致的话题
 	VideoPath    string
 	Location     string
 	ScheduleTime *time.Time // 定时发布时间，nil 表示立即发布
+	Draft        bool       // 填写完成后存入草稿箱，不发布
+	Options      PublishOptions
+	// CoverPath 自定义封面图片的本地路径，CoverOffset 从视频该时间点截帧作为封面；都为空时使用平台自动封面
+	CoverPath   string
+	CoverOffset *time.Duration
 }
 
 // NewPublishVideoAction 进入发布页并切换到"上传视频"
@@ -51,22 +58,52 @@ func NewPublishVideoAction(page *rod.Page) (*PublishAction, error) {
 	return &PublishAction{page: pp}, nil
 }
 
-// PublishVideo 上传视频并提交
-func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoContent) error {
+// PublishVideo 上传视频并提交，成功后返回新笔记的 ID、链接与 xsec_token
+func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoContent) (*PublishResult, error) {
 	if content.VideoPath == "" {
-		return errors.New("视频不能为空")
+		return nil, errors.New("视频不能为空")
 	}
 
 	page := p.page.Context(ctx)
 
+	cover := content.CoverPath
+	if cover == "" && content.CoverOffset != nil {
+		framePath, err := captureVideoFrame(page, content.VideoPath, *content.CoverOffset)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(framePath)
+		cover = framePath
+	}
+
 	if err := uploadVideo(page, content.VideoPath); err != nil {
-		return errors.Wrap(err, "小红书上传视频失败")
+		return nil, errors.Wrap(err, "小红书上传视频失败")
 	}
 
-	if err := submitPublishVideo(page, content.Title, content.Content, content.Tags, content.Location, content.ScheduleTime); err != nil {
-		return errors.Wrap(err, "小红书发布失败")
+	if content.Draft {
+		filled, err := fillPublishVideoForm(page, content.Title, content.Content, content.Tags, content.Mentions, content.ExactTags, content.Location, nil, content.Options, cover)
+		if err != nil {
+			return nil, errors.Wrap(err, "小红书填写笔记失败")
+		}
+		result, err := saveDraft(page, content.Title)
+		if err != nil {
+			return nil, err
+		}
+		filled.applyTo(result)
+		return result, nil
 	}
-	return nil
+
+	w := watchResponses(page, isPublishAPI)
+	defer w.Stop()
+	started := time.Now()
+
+	filled, err := submitPublishVideo(page, content.Title, content.Content, content.Tags, content.Mentions, content.ExactTags, content.Location, content.ScheduleTime, content.Options, cover)
+	if err != nil {
+		return nil, errors.Wrap(err, "小红书发布失败")
+	}
+	result := collectPublishResult(page, w, content.Title, started)
+	filled.applyTo(result)
+	return result, nil
 }
 
 // uploadVideo 上传单个本地视频
@@ -137,34 +174,70 @@ func waitForPublishButtonClickable(page *rod.Page) (*rod.Element, error) {
 	return nil, errors.New("等待发布按钮可点击超时")
 }
 
-// submitPublishVideo 填写标题、正文、标签并点击发布（等待按钮可点击后再提交）
-func submitPublishVideo(page *rod.Page, title, content string, tags []string, location string, scheduleTime *time.Time) error {
+// submitPublishVideo 设置封面，填写标题、正文、标签并点击发布（等待按钮可点击后再提交）
+func submitPublishVideo(page *rod.Page, title, content string, tags, mentions []string, exactTags bool, location string, scheduleTime *time.Time, opts PublishOptions, cover string) (*editorInput, error) {
+	filled, err := fillPublishVideoForm(page, title, content, tags, mentions, exactTags, location, scheduleTime, opts, cover)
+	if err != nil {
+		return nil, err
+	}
+
+	// 等待发布按钮可点击
+	btn, err := waitForPublishButtonClickable(page)
+	if err != nil {
+		return nil, err
+	}
+
+	// 点击发布
+	err = btn.Click(proto.InputMouseButtonLeft, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "点击发布按钮失败")
+	}
+
+	time.Sleep(3 * time.Second)
+	return filled, nil
+}
+
+// fillPublishVideoForm 设置封面，填写视频笔记的标题、正文、@用户、标签、定时发布与发布设置，不点击发布
+func fillPublishVideoForm(page *rod.Page, title, content string, tags, mentions []string, exactTags bool, location string, scheduleTime *time.Time, opts PublishOptions, cover string) (*editorInput, error) {
 	_ = location
 
+	if cover != "" {
+		if err := setVideoCover(page, cover); err != nil {
+			return nil, errors.Wrap(err, "设置封面失败")
+		}
+	}
+
 	// 标题
 	titleElem, err := page.Element("div.d-input input")
 	if err != nil {
-		return errors.Wrap(err, "查找标题输入框失败")
+		return nil, errors.Wrap(err, "查找标题输入框失败")
 	}
 	err = titleElem.Input(title)
 	if err != nil {
-		return errors.Wrap(err, "输入标题失败")
+		return nil, errors.Wrap(err, "输入标题失败")
 	}
 	time.Sleep(1 * time.Second)
 
-	// 正文 + 标签
+	// 正文 + @用户 + 标签
 	contentElem, ok := getContentElement(page)
 	if !ok {
-		return errors.New("没有找到内容输入框")
+		return nil, errors.New("没有找到内容输入框")
 	}
 	err = contentElem.Input(content)
 	if err != nil {
-		return errors.Wrap(err, "输入正文失败")
+		return nil, errors.Wrap(err, "输入正文失败")
 	}
-	err = inputTags(contentElem, tags)
+	filled := &editorInput{}
+	filled.Warnings, err = inputMentions(contentElem, mentions)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	topics, tagWarnings, err := inputTags(contentElem, tags, exactTags)
+	if err != nil {
+		return nil, err
+	}
+	filled.Topics = topics
+	filled.Warnings = append(filled.Warnings, tagWarnings...)
 
 	time.Sleep(1 * time.Second)
 
@@ -176,23 +249,10 @@ func submitPublishVideo(page *rod.Page, title, content string, tags []string, lo
 	if scheduleTime != nil {
 		err = setSchedulePublish(page, *scheduleTime)
 		if err != nil {
-			return errors.Wrap(err, "设置定时发布失败")
+			return nil, errors.Wrap(err, "设置定时发布失败")
 		}
 		slog.Info("定时发布设置完成", "schedule_time", scheduleTime.Format("2006-01-02 15:04"))
 	}
 
-	// 等待发布按钮可点击
-	btn, err := waitForPublishButtonClickable(page)
-	if err != nil {
-		return err
-	}
-
-	// 点击发布
-	err = btn.Click(proto.InputMouseButtonLeft, 1)
-	if err != nil {
-		return errors.Wrap(err, "点击发布按钮失败")
-	}
-
-	time.Sleep(3 * time.Second)
-	return nil
+	return filled, applyPublishOptions(page, opts)
 }
@@ -0,0 +1,308 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Topic 话题联想结果
+type Topic struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	ViewCount int64  `json:"view_count"`
+	// ViewText 页面上展示的浏览量，如 "1.2亿次浏览"
+	ViewText string `json:"view_text,omitempty"`
+}
+
+// 输入 # 时编辑器调用的话题联想接口
+func isTopicSearchAPI(method, u string) bool {
+	return method == "POST" && strings.Contains(u, "/search/topic")
+}
+
+// parseTopicSearchResponse 解析话题联想接口响应
+func parseTopicSearchResponse(body []byte) []Topic {
+	var r struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil
+	}
+
+	var items []map[string]any
+	for _, key := range []string{"topic_info_dtos", "topics", "items"} {
+		if raw, ok := r.Data[key]; ok && json.Unmarshal(raw, &items) == nil {
+			break
+		}
+	}
+
+	topics := make([]Topic, 0, len(items))
+	for _, it := range items {
+		name, _ := it["name"].(string)
+		name = normalizeTopicName(name)
+		if name == "" {
+			continue
+		}
+		t := Topic{Name: name}
+		if id, ok := it["id"].(string); ok {
+			t.ID = id
+		}
+		for _, k := range []string{"view_num", "viewNum", "view_count"} {
+			if v, ok := it[k].(float64); ok {
+				t.ViewCount = int64(v)
+				break
+			}
+		}
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+var topicViewRe = regexp.MustCompile(`([\d.,]+)\s*([万亿wW]?)\s*次?浏览`)
+
+// parseTopicItemText 解析联想下拉框中一项的文本，如 "#美食\n12.3亿次浏览"
+func parseTopicItemText(text string) Topic {
+	text = strings.TrimSpace(text)
+	var t Topic
+	if loc := topicViewRe.FindStringSubmatchIndex(text); loc != nil {
+		t.ViewText = strings.TrimSpace(text[loc[0]:loc[1]])
+		t.ViewCount = parseCount(text[loc[2]:loc[3]] + text[loc[4]:loc[5]])
+		text = text[:loc[0]]
+	}
+	if line, _, ok := strings.Cut(text, "\n"); ok {
+		text = line
+	}
+	t.Name = normalizeTopicName(text)
+	return t
+}
+
+// parseCount 解析 "1.2亿"、"3.4万"、"1,234" 这类计数
+func parseCount(s string) int64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	mul := 1.0
+	switch {
+	case strings.HasSuffix(s, "亿"):
+		mul, s = 1e8, strings.TrimSuffix(s, "亿")
+	case strings.HasSuffix(s, "万"):
+		mul, s = 1e4, strings.TrimSuffix(s, "万")
+	case strings.HasSuffix(s, "w"), strings.HasSuffix(s, "W"):
+		mul, s = 1e4, s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(v*mul + 0.5)
+}
+
+// normalizeTopicName 去掉话题名称两端的 # 与空白，便于比较
+func normalizeTopicName(name string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(name), "#"))
+}
+
+// topicNameEqual 精确匹配：忽略 # 与英文大小写
+func topicNameEqual(a, b string) bool {
+	return strings.EqualFold(normalizeTopicName(a), normalizeTopicName(b))
+}
+
+// topicNodeSelector 选中话题后编辑器中插入的话题节点
+const topicNodeSelector = "[class*='topic'], [data-topic], [data-type='topic']"
+
+// readTopicSuggestions 读取当前联想下拉框中的话题及其元素
+func readTopicSuggestions(page *rod.Page) ([]Topic, []*rod.Element) {
+	container, err := page.Timeout(2 * time.Second).Element("#creator-editor-topic-container")
+	if err != nil || container == nil {
+		return nil, nil
+	}
+	items, err := container.Elements(".item")
+	if err != nil {
+		return nil, nil
+	}
+	topics := make([]Topic, 0, len(items))
+	elems := make([]*rod.Element, 0, len(items))
+	for _, it := range items {
+		text, err := it.Text()
+		if err != nil {
+			continue
+		}
+		t := parseTopicItemText(text)
+		if t.Name == "" {
+			continue
+		}
+		topics = append(topics, t)
+		elems = append(elems, it)
+	}
+	return topics, elems
+}
+
+// typeTopicQuery 在正文中输入 #关键词 触发话题联想
+func typeTopicQuery(contentElem *rod.Element, keyword string) error {
+	if err := contentElem.Input("#"); err != nil {
+		return errors.Wrap(err, "输入#失败")
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	for _, char := range keyword {
+		if err := contentElem.Input(string(char)); err != nil {
+			return errors.Wrapf(err, "输入字符[%c]失败", char)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	time.Sleep(1 * time.Second)
+	return nil
+}
+
+// TopicSearchAction 话题搜索
+type TopicSearchAction struct {
+	page *rod.Page
+}
+
+// NewTopicSearchAction 进入发布页并上传一张占位图，使正文编辑器可用；不会发布或保存
+func NewTopicSearchAction(page *rod.Page) (*TopicSearchAction, error) {
+	action, err := NewPublishImageAction(page)
+	if err != nil {
+		return nil, err
+	}
+	return &TopicSearchAction{page: action.page}, nil
+}
+
+// Search 在正文中输入 #关键词，返回编辑器给出的话题联想列表（含浏览量）
+func (a *TopicSearchAction) Search(ctx context.Context, keyword string) ([]Topic, error) {
+	keyword = normalizeTopicName(keyword)
+	if keyword == "" {
+		return nil, errors.New("关键词不能为空")
+	}
+	page := a.page.Context(ctx)
+
+	placeholder, err := writePlaceholderImage()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(placeholder)
+
+	n, err := uploadImages(page, []string{placeholder})
+	if err != nil {
+		return nil, errors.Wrap(err, "上传占位图失败")
+	}
+	if err := waitForUploadComplete(page, n); err != nil {
+		return nil, errors.Wrap(err, "上传占位图未完成")
+	}
+
+	contentElem, ok := getContentElement(page)
+	if !ok {
+		return nil, errors.New("没有找到内容输入框")
+	}
+
+	w := watchResponses(page, isTopicSearchAPI)
+	defer w.Stop()
+
+	if err := typeTopicQuery(contentElem, keyword); err != nil {
+		return nil, err
+	}
+
+	// 优先使用接口响应（带话题 ID 与精确浏览量），取不到时读取下拉框
+	var topics []Topic
+	if resp, err := w.Next(3 * time.Second); err == nil {
+		topics = parseTopicSearchResponse(resp.Body)
+	}
+	domTopics, _ := readTopicSuggestions(page)
+	if len(topics) == 0 {
+		topics = domTopics
+	} else {
+		mergeTopicViewText(topics, domTopics)
+	}
+
+	logrus.Infof("话题搜索: keyword=%s results=%d", keyword, len(topics))
+	return topics, nil
+}
+
+// mergeTopicViewText 把下拉框中展示的浏览量文本补充到接口结果中
+func mergeTopicViewText(topics, domTopics []Topic) {
+	texts := make(map[string]string, len(domTopics))
+	for _, t := range domTopics {
+		texts[strings.ToLower(t.Name)] = t.ViewText
+	}
+	for i := range topics {
+		if topics[i].ViewText == "" {
+			topics[i].ViewText = texts[strings.ToLower(topics[i].Name)]
+		}
+	}
+}
+
+// writePlaceholderImage 生成一张纯白占位图，仅用于打开正文编辑器
+func writePlaceholderImage() (string, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 600, 800))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+
+	f, err := os.CreateTemp("", "xhs-topic-*.png")
+	if err != nil {
+		return "", errors.Wrap(err, "创建占位图失败")
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "写入占位图失败")
+	}
+	return f.Name(), nil
+}
+
+// inputTag 输入一个标签并从联想列表选择话题，返回实际关联的话题名（未关联时为空）。
+// exact 为 true 时只选择名称完全一致的话题，找不到则保留为普通文本，避免关联到错误的话题。
+func inputTag(contentElem *rod.Element, tag string, exact bool) (string, error) {
+	if err := typeTopicQuery(contentElem, tag); err != nil {
+		return "", err
+	}
+
+	topics, elems := readTopicSuggestions(contentElem.Page())
+	idx := -1
+	for i, t := range topics {
+		if !exact || topicNameEqual(t.Name, tag) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		logrus.Warnf("未找到匹配的话题，按普通文本输入: tag=%s exact=%v candidates=%d", tag, exact, len(topics))
+		return "", contentElem.Input(" ")
+	}
+
+	before := countInsertedNodes(contentElem, topicNodeSelector, topics[idx].Name)
+	if err := elems[idx].Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return "", errors.Wrap(err, "点击标签联想选项失败")
+	}
+	time.Sleep(700 * time.Millisecond) // 等待标签处理完成
+
+	// 以编辑器中实际插入的话题节点为准，点击没有生效时视为未关联
+	if countInsertedNodes(contentElem, topicNodeSelector, topics[idx].Name) <= before {
+		logrus.Warnf("点击话题后编辑器中没有出现对应的话题节点: tag=%s topic=%s", tag, topics[idx].Name)
+		return "", nil
+	}
+	logrus.Infof("成功选择话题: tag=%s topic=%s", tag, topics[idx].Name)
+	return topics[idx].Name, nil
+}
+
+// tagWarning 标签未按预期关联时给调用方的提示
+func tagWarning(tag, topic string) string {
+	if topic == "" {
+		return fmt.Sprintf("标签 #%s 未关联到话题，已按普通文本输入", tag)
+	}
+	if !topicNameEqual(tag, topic) {
+		return fmt.Sprintf("标签 #%s 关联到了话题 #%s", tag, topic)
+	}
+	return ""
+}
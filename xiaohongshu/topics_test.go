@@ -0,0 +1,51 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCount(t *testing.T) {
+	assert.Equal(t, int64(120000000), parseCount("1.2亿"))
+	assert.Equal(t, int64(34000), parseCount("3.4万"))
+	assert.Equal(t, int64(25000), parseCount("2.5w"))
+	assert.Equal(t, int64(1234), parseCount("1,234"))
+	assert.Equal(t, int64(0), parseCount("很多"))
+}
+
+func TestParseTopicItemText(t *testing.T) {
+	tp := parseTopicItemText("#美食\n12.3亿次浏览")
+	assert.Equal(t, "美食", tp.Name)
+	assert.Equal(t, int64(1230000000), tp.ViewCount)
+	assert.Equal(t, "12.3亿次浏览", tp.ViewText)
+
+	tp = parseTopicItemText("#美食探店 3.4万浏览")
+	assert.Equal(t, "美食探店", tp.Name)
+	assert.Equal(t, int64(34000), tp.ViewCount)
+
+	tp = parseTopicItemText("#新话题")
+	assert.Equal(t, "新话题", tp.Name)
+	assert.Zero(t, tp.ViewCount)
+}
+
+func TestParseTopicSearchResponse(t *testing.T) {
+	body := []byte(`{"code":0,"success":true,"data":{"topic_info_dtos":[
+		{"id":"5be00b","name":"美食","view_num":1230000000},
+		{"id":"5be00c","name":"#美食分享","view_num":45600},
+		{"id":"x","name":""}
+	]}}`)
+	topics := parseTopicSearchResponse(body)
+	require.Len(t, topics, 2)
+	assert.Equal(t, Topic{ID: "5be00b", Name: "美食", ViewCount: 1230000000}, topics[0])
+	assert.Equal(t, "美食分享", topics[1].Name)
+
+	assert.Empty(t, parseTopicSearchResponse([]byte(`not json`)))
+}
+
+func TestTagWarning(t *testing.T) {
+	assert.Empty(t, tagWarning("Vlog", "vlog"))
+	assert.Contains(t, tagWarning("美食", "美食分享"), "#美食分享")
+	assert.Contains(t, tagWarning("冷门", ""), "普通文本")
+}
@@ -0,0 +1,168 @@
+package xiaohongshu
+
+import (
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ParseCoverOffset 解析封面截帧时间，支持秒数（"3.5"）、Go 时长（"1m2s"）与 "mm:ss" / "hh:mm:ss"
+func ParseCoverOffset(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("封面时间为空")
+	}
+
+	var d time.Duration
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		d = time.Duration(sec * float64(time.Second))
+	} else if v, err := time.ParseDuration(s); err == nil {
+		d = v
+	} else if parts := strings.Split(s, ":"); len(parts) == 2 || len(parts) == 3 {
+		total := 0.0
+		for i, p := range parts {
+			n, err := strconv.ParseFloat(p, 64)
+			last := i == len(parts)-1
+			if err != nil || n < 0 || (i > 0 && n >= 60) || (!last && n != float64(int(n))) {
+				return 0, errors.Errorf("封面时间格式错误: %s", s)
+			}
+			total = total*60 + n
+		}
+		d = time.Duration(total * float64(time.Second))
+	} else {
+		return 0, errors.Errorf("封面时间格式错误: %s（示例: 3.5 / 1m2s / 01:02）", s)
+	}
+
+	if d < 0 {
+		return 0, errors.Errorf("封面时间不能为负: %s", s)
+	}
+	return d, nil
+}
+
+// CheckCoverOffset 校验封面时间在视频时长之内，duration<=0（时长未知）时不校验
+func CheckCoverOffset(d, duration time.Duration) error {
+	if duration > 0 && d >= duration {
+		return errors.Errorf("封面时间 %s 超出视频时长 %s", d, duration.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// 在页面中用 <video> 加载本地视频，跳到指定时间后画到 canvas 导出 JPEG
+const jsCaptureVideoFrame = `async (inputID, sec) => {
+	const input = document.getElementById(inputID);
+	const file = input && input.files && input.files[0];
+	if (!file) throw new Error('video file not set');
+	const url = URL.createObjectURL(file);
+	const v = document.createElement('video');
+	v.muted = true;
+	v.preload = 'auto';
+	const wait = (ev) => new Promise((ok, fail) => {
+		const timer = setTimeout(() => fail(new Error(ev + ' timeout')), 20000);
+		v.addEventListener(ev, () => { clearTimeout(timer); ok(); }, { once: true });
+		v.addEventListener('error', () => { clearTimeout(timer); fail(new Error('decode error')); }, { once: true });
+	});
+	try {
+		v.src = url;
+		await wait('loadeddata');
+		if (isFinite(v.duration) && sec >= v.duration) throw new Error('cover_at ' + sec + 's exceeds duration ' + v.duration + 's');
+		v.currentTime = Math.max(0, Math.min(sec, v.duration - 0.05));
+		await wait('seeked');
+		const c = document.createElement('canvas');
+		c.width = v.videoWidth;
+		c.height = v.videoHeight;
+		if (!c.width || !c.height) throw new Error('empty frame');
+		c.getContext('2d').drawImage(v, 0, 0, c.width, c.height);
+		return c.toDataURL('image/jpeg', 0.92);
+	} finally {
+		URL.revokeObjectURL(url);
+		input.remove();
+	}
+}`
+
+const coverSourceInputID = "__xhs_mcp_cover_src"
+
+// captureVideoFrame 截取视频 offset 处的画面保存为临时 JPEG，调用方负责删除文件。
+// 截帧依赖浏览器的解码能力，不含 H.264 解码的 Chromium 会失败，此时请改用封面图片。
+func captureVideoFrame(page *rod.Page, videoPath string, offset time.Duration) (string, error) {
+	if _, err := page.Eval(`(id) => {
+		const i = document.createElement('input');
+		i.type = 'file';
+		i.id = id;
+		i.style.display = 'none';
+		document.body.appendChild(i);
+	}`, coverSourceInputID); err != nil {
+		return "", errors.Wrap(err, "创建截帧输入框失败")
+	}
+	input, err := page.Element("#" + coverSourceInputID)
+	if err != nil {
+		return "", errors.Wrap(err, "查找截帧输入框失败")
+	}
+	if err := input.SetFiles([]string{videoPath}); err != nil {
+		return "", errors.Wrap(err, "加载视频失败")
+	}
+
+	res, err := page.Eval(jsCaptureVideoFrame, coverSourceInputID, offset.Seconds())
+	if err != nil {
+		return "", errors.Wrap(err, "视频截帧失败，浏览器可能无法解码该视频，请改用封面图片")
+	}
+
+	dataURL := res.Value.Str()
+	_, b64, ok := strings.Cut(dataURL, "base64,")
+	if !ok {
+		return "", errors.New("视频截帧结果无效")
+	}
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return "", errors.Wrap(err, "解码截帧图片失败")
+	}
+
+	f, err := os.CreateTemp("", "xhs-cover-*.jpg")
+	if err != nil {
+		return "", errors.Wrap(err, "创建封面临时文件失败")
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "写入封面临时文件失败")
+	}
+	logrus.Infof("已截取视频封面: offset=%s size=%d", offset, len(data))
+	return f.Name(), nil
+}
+
+// setVideoCover 打开封面设置弹窗上传自定义封面并确认
+func setVideoCover(page *rod.Page, coverPath string) error {
+	if _, err := os.Stat(coverPath); err != nil {
+		return errors.Wrapf(err, "封面文件不存在: %s", coverPath)
+	}
+
+	if err := clickByText(page, []string{"设置封面", "修改封面", "编辑封面", "选择封面"}, false); err != nil {
+		return err
+	}
+	time.Sleep(1 * time.Second)
+	// 弹窗默认停在截取封面，切到上传；没有该标签时直接找上传框
+	_ = clickByText(page, []string{"上传封面", "本地上传"}, true)
+	time.Sleep(500 * time.Millisecond)
+
+	inputs, err := page.Elements("input[type='file'][accept*='image']")
+	if err != nil || len(inputs) == 0 {
+		return errors.New("未找到封面上传输入框")
+	}
+	// 发布页本身也有图片上传框，弹窗中的输入框在最后
+	if err := inputs[len(inputs)-1].SetFiles([]string{coverPath}); err != nil {
+		return errors.Wrap(err, "上传封面失败")
+	}
+	time.Sleep(3 * time.Second)
+
+	if err := clickButtonByText(page, "确定", "完成", "确认"); err != nil {
+		return errors.Wrap(err, "确认封面失败")
+	}
+	time.Sleep(1 * time.Second)
+	logrus.Infof("封面设置完成: %s", coverPath)
+	return nil
+}
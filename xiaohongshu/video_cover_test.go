@@ -0,0 +1,38 @@
+package xiaohongshu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCoverOffset(t *testing.T) {
+	cases := map[string]time.Duration{
+		"3":        3 * time.Second,
+		"3.5":      3500 * time.Millisecond,
+		"1m2s":     62 * time.Second,
+		"01:02":    62 * time.Second,
+		"00:00:07": 7 * time.Second,
+		"1:02:03":  time.Hour + 2*time.Minute + 3*time.Second,
+		"0:01.5":   1500 * time.Millisecond,
+	}
+	for in, want := range cases {
+		got, err := ParseCoverOffset(in)
+		if assert.NoError(t, err, in) {
+			assert.Equal(t, want, got, in)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "-1", "01:75", "1:2:3:4", "1.5:00"} {
+		_, err := ParseCoverOffset(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestCheckCoverOffset(t *testing.T) {
+	assert.NoError(t, CheckCoverOffset(3*time.Second, 10*time.Second))
+	assert.NoError(t, CheckCoverOffset(time.Hour, 0))
+	assert.Error(t, CheckCoverOffset(10*time.Second, 10*time.Second))
+	assert.Error(t, CheckCoverOffset(12*time.Second, 10*time.Second))
+}